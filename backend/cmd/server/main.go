@@ -9,21 +9,29 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 
 	"courtopia-reserve/backend/internal/config"
 	"courtopia-reserve/backend/internal/database"
 	"courtopia-reserve/backend/internal/handlers"
+	"courtopia-reserve/backend/internal/models"
 	"courtopia-reserve/backend/internal/repository"
 )
 
-func startScheduler(bookingRepo *repository.BookingRepository, userRepo *repository.UserRepository) {
+func startScheduler(bookingRepo *repository.BookingRepository, userRepo *repository.UserRepository, notificationRepo *repository.NotificationRepository, paymentRepo *repository.PaymentRepository, promoRepo *repository.PromoCodeRepository, membershipReminderDays int, keyRotator *handlers.KeyRotator) {
 	ticker := time.NewTicker(1 * time.Minute)
 	go func() {
 		for range ticker.C {
 			log.Println("Running email notification scheduler...")
-			handlers.SendMail(bookingRepo, userRepo)
+			handlers.SendMail(bookingRepo, userRepo, notificationRepo)
+			handlers.ExpirePaymentHolds(bookingRepo, paymentRepo, promoRepo)
+			handlers.SendMembershipReminders(userRepo, notificationRepo, membershipReminderDays)
+
+			if err := keyRotator.Rotate(context.Background()); err != nil {
+				log.Printf("Error rotating signing keys: %v", err)
+			}
 		}
 	}()
 }
@@ -47,8 +55,58 @@ func main() {
 	userRepo := repository.NewUserRepository(db)
 	courtRepo := repository.NewCourtRepository(db)
 	bookingRepo := repository.NewBookingRepository(db)
+	tokenRepo := repository.NewAPITokenRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	signingKeyRepo := repository.NewSigningKeyRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	auditRepo := repository.NewAuditLogRepository(db)
+	announcementRepo := repository.NewAnnouncementRepository(db)
+	facilityRepo := repository.NewFacilityRepository(db)
+	pricingRepo := repository.NewPricingRuleRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	walletRepo := repository.NewWalletRepository(db)
+	promoRepo := repository.NewPromoCodeRepository(db)
+	membershipRepo := repository.NewMembershipPlanRepository(db)
+	invoiceRepo := repository.NewInvoiceRepository(db)
+	equipmentRepo := repository.NewEquipmentRepository(db)
+
+	defaultFacility, err := facilityRepo.EnsureDefault(context.Background(), &models.Facility{
+		Name:        cfg.DefaultFacilityName,
+		Timezone:    cfg.FacilityTimezone,
+		OpeningTime: cfg.OpeningTime,
+		ClosingTime: cfg.ClosingTime,
+	})
+	if err != nil {
+		log.Fatalf("Error creating default facility: %v", err)
+	}
+	if err := courtRepo.EnsureIndexes(context.Background(), defaultFacility.ID); err != nil {
+		log.Fatalf("Error creating court indexes: %v", err)
+	}
+	if err := bookingRepo.AssignDefaultFacility(context.Background(), defaultFacility.ID); err != nil {
+		log.Fatalf("Error migrating bookings to the default facility: %v", err)
+	}
+	if err := auditRepo.EnsureIndexes(context.Background(), cfg.AuditRetention); err != nil {
+		log.Fatalf("Error creating audit log indexes: %v", err)
+	}
+	if err := paymentRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating payment indexes: %v", err)
+	}
+	if err := walletRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating wallet indexes: %v", err)
+	}
+	if err := promoRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating promo code indexes: %v", err)
+	}
+	if err := invoiceRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Error creating invoice indexes: %v", err)
+	}
+
+	keyRotator := handlers.NewKeyRotator(signingKeyRepo, cfg)
+	if err := keyRotator.Rotate(context.Background()); err != nil {
+		log.Fatalf("Error loading signing keys: %v", err)
+	}
 
-	startScheduler(bookingRepo, userRepo)
+	startScheduler(bookingRepo, userRepo, notificationRepo, paymentRepo, promoRepo, cfg.MembershipReminderDays, keyRotator)
 
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -61,7 +119,8 @@ func main() {
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, Content-Disposition")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == "OPTIONS" {
@@ -76,10 +135,10 @@ func main() {
 		c.String(http.StatusOK, "OK")
 	})
 
-	h := handlers.NewHandler(db, userRepo, courtRepo, bookingRepo, cfg.JWTSecret)
+	h := handlers.NewHandler(db, userRepo, courtRepo, bookingRepo, tokenRepo, notificationRepo, analyticsRepo, auditRepo, announcementRepo, facilityRepo, pricingRepo, paymentRepo, walletRepo, promoRepo, membershipRepo, invoiceRepo, equipmentRepo, keyRotator.Keys(), cfg)
 	h.RegisterRoutes(r)
 
-	h = handlers.NewHandler(db, userRepo, courtRepo, bookingRepo, cfg.JWTSecret)
+	h = handlers.NewHandler(db, userRepo, courtRepo, bookingRepo, tokenRepo, notificationRepo, analyticsRepo, auditRepo, announcementRepo, facilityRepo, pricingRepo, paymentRepo, walletRepo, promoRepo, membershipRepo, invoiceRepo, equipmentRepo, keyRotator.Keys(), cfg)
 	r.POST("/trigger-email-notifications", h.TriggerEmailNotifications)
 
 	srv := &http.Server{
@@ -0,0 +1,81 @@
+// Package cancellation decides the refund and penalty for cancelling a
+// booking, based on how long before the start it is cancelled.
+package cancellation
+
+import (
+	"time"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+// Policy splits the time before a booking into three windows. Cancelling
+// at least FreeWindow ahead is refunded in full, cancelling at least
+// LateWindow ahead is refunded PartialRefundPct percent, and anything later
+// is refunded nothing and earns a strike. StrikeLimit strikes within
+// StrikeWindow ban the user from booking for BanDuration; a limit of zero
+// disables bans.
+type Policy struct {
+	FreeWindow       time.Duration
+	LateWindow       time.Duration
+	PartialRefundPct int
+	StrikeLimit      int
+	StrikeWindow     time.Duration
+	BanDuration      time.Duration
+}
+
+// Evaluate returns the outcome of cancelling the booking at now. startsAt is
+// the real instant the booking starts and strikes are the user's earlier
+// strike times. Only paid bookings are refunded, and only confirmed
+// bookings earn strikes.
+func (p Policy) Evaluate(booking *models.Booking, startsAt time.Time, strikes []time.Time, now time.Time) *models.CancellationOutcome {
+	outcome := &models.CancellationOutcome{}
+
+	notice := startsAt.Sub(now)
+	switch {
+	case notice >= p.FreeWindow:
+		outcome.Window = models.CancelWindowFree
+		outcome.RefundPct = 100
+	case notice >= p.LateWindow:
+		outcome.Window = models.CancelWindowPartial
+		outcome.RefundPct = p.PartialRefundPct
+	default:
+		outcome.Window = models.CancelWindowLate
+		outcome.Strike = booking.Status == "active"
+	}
+
+	if booking.PaidAt != nil {
+		outcome.RefundAmount = booking.Price * int64(outcome.RefundPct) / 100
+		outcome.Fee = booking.Price - outcome.RefundAmount
+	}
+
+	if outcome.Strike {
+		outcome.Strikes = p.RecentStrikes(strikes, now) + 1
+		if p.StrikeLimit > 0 && outcome.Strikes >= p.StrikeLimit {
+			banUntil := now.Add(p.BanDuration)
+			outcome.BanUntil = &banUntil
+		}
+	}
+
+	return outcome
+}
+
+// Exempt is the outcome of a cancellation made by staff: a full refund of
+// a paid booking and no strike.
+func Exempt(booking *models.Booking) *models.CancellationOutcome {
+	outcome := &models.CancellationOutcome{Window: models.CancelExempt, RefundPct: 100}
+	if booking.PaidAt != nil {
+		outcome.RefundAmount = booking.Price
+	}
+	return outcome
+}
+
+// RecentStrikes counts the strikes inside the strike window.
+func (p Policy) RecentStrikes(strikes []time.Time, now time.Time) int {
+	count := 0
+	for _, at := range strikes {
+		if now.Sub(at) < p.StrikeWindow {
+			count++
+		}
+	}
+	return count
+}
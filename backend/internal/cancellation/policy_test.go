@@ -0,0 +1,130 @@
+package cancellation
+
+import (
+	"testing"
+	"time"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+var testPolicy = Policy{
+	FreeWindow:       24 * time.Hour,
+	LateWindow:       2 * time.Hour,
+	PartialRefundPct: 50,
+	StrikeLimit:      3,
+	StrikeWindow:     30 * 24 * time.Hour,
+	BanDuration:      7 * 24 * time.Hour,
+}
+
+func TestEvaluateWindows(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	paidAt := now.Add(-48 * time.Hour)
+
+	tests := []struct {
+		name       string
+		notice     time.Duration
+		status     string
+		price      int64
+		paid       bool
+		wantWindow string
+		wantRefund int64
+		wantFee    int64
+		wantStrike bool
+	}{
+		{name: "well ahead", notice: 72 * time.Hour, status: "active", price: 30000, paid: true, wantWindow: models.CancelWindowFree, wantRefund: 30000},
+		{name: "exactly at the free window", notice: 24 * time.Hour, status: "active", price: 30000, paid: true, wantWindow: models.CancelWindowFree, wantRefund: 30000},
+		{name: "just inside the free window", notice: 24*time.Hour - time.Minute, status: "active", price: 30000, paid: true, wantWindow: models.CancelWindowPartial, wantRefund: 15000, wantFee: 15000},
+		{name: "exactly at the late window", notice: 2 * time.Hour, status: "active", price: 30000, paid: true, wantWindow: models.CancelWindowPartial, wantRefund: 15000, wantFee: 15000},
+		{name: "partial refund rounds down", notice: 12 * time.Hour, status: "active", price: 12345, paid: true, wantWindow: models.CancelWindowPartial, wantRefund: 6172, wantFee: 6173},
+		{name: "late", notice: time.Hour, status: "active", price: 30000, paid: true, wantWindow: models.CancelWindowLate, wantFee: 30000, wantStrike: true},
+		{name: "after the start", notice: -time.Hour, status: "active", price: 30000, paid: true, wantWindow: models.CancelWindowLate, wantFee: 30000, wantStrike: true},
+		{name: "late but unpaid", notice: time.Hour, status: "active", price: 30000, wantWindow: models.CancelWindowLate, wantStrike: true},
+		{name: "late pending payment", notice: time.Hour, status: "pending_payment", price: 30000, wantWindow: models.CancelWindowLate},
+		{name: "free booking", notice: time.Hour, status: "active", paid: true, wantWindow: models.CancelWindowLate, wantStrike: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := &models.Booking{Status: tt.status, Price: tt.price}
+			if tt.paid {
+				booking.PaidAt = &paidAt
+			}
+
+			outcome := testPolicy.Evaluate(booking, now.Add(tt.notice), nil, now)
+			if outcome.Window != tt.wantWindow {
+				t.Errorf("Window = %q, want %q", outcome.Window, tt.wantWindow)
+			}
+			if outcome.RefundAmount != tt.wantRefund || outcome.Fee != tt.wantFee {
+				t.Errorf("refund %d and fee %d, want %d and %d", outcome.RefundAmount, outcome.Fee, tt.wantRefund, tt.wantFee)
+			}
+			if outcome.RefundAmount+outcome.Fee != booking.Price && tt.paid {
+				t.Errorf("refund and fee add up to %d, want the price %d", outcome.RefundAmount+outcome.Fee, booking.Price)
+			}
+			if outcome.Strike != tt.wantStrike {
+				t.Errorf("Strike = %v, want %v", outcome.Strike, tt.wantStrike)
+			}
+		})
+	}
+}
+
+func TestEvaluateStrikes(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	booking := &models.Booking{Status: "active", Price: 30000}
+	startsAt := now.Add(30 * time.Minute)
+	noBans := testPolicy
+	noBans.StrikeLimit = 0
+
+	tests := []struct {
+		name        string
+		policy      Policy
+		strikes     []time.Time
+		wantStrikes int
+		wantBan     bool
+	}{
+		{name: "first strike", policy: testPolicy, wantStrikes: 1},
+		{name: "below the limit", policy: testPolicy, strikes: []time.Time{now.Add(-24 * time.Hour)}, wantStrikes: 2},
+		{name: "reaches the limit", policy: testPolicy, strikes: []time.Time{now.Add(-24 * time.Hour), now.Add(-48 * time.Hour)}, wantStrikes: 3, wantBan: true},
+		{name: "old strikes do not count", policy: testPolicy, strikes: []time.Time{now.Add(-31 * 24 * time.Hour), now.Add(-24 * time.Hour)}, wantStrikes: 2},
+		{name: "bans disabled", policy: noBans, strikes: []time.Time{now.Add(-time.Hour), now.Add(-2 * time.Hour)}, wantStrikes: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := tt.policy.Evaluate(booking, startsAt, tt.strikes, now)
+			if outcome.Strikes != tt.wantStrikes {
+				t.Errorf("Strikes = %d, want %d", outcome.Strikes, tt.wantStrikes)
+			}
+			if (outcome.BanUntil != nil) != tt.wantBan {
+				t.Fatalf("BanUntil = %v, want a ban: %v", outcome.BanUntil, tt.wantBan)
+			}
+			if tt.wantBan && !outcome.BanUntil.Equal(now.Add(tt.policy.BanDuration)) {
+				t.Errorf("BanUntil = %v, want %v", outcome.BanUntil, now.Add(tt.policy.BanDuration))
+			}
+		})
+	}
+}
+
+func TestExempt(t *testing.T) {
+	paidAt := time.Now()
+
+	tests := []struct {
+		name       string
+		booking    *models.Booking
+		wantRefund int64
+	}{
+		{name: "paid", booking: &models.Booking{Status: "active", Price: 30000, PaidAt: &paidAt}, wantRefund: 30000},
+		{name: "unpaid", booking: &models.Booking{Status: "pending_payment", Price: 30000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := Exempt(tt.booking)
+			if outcome.Window != models.CancelExempt || outcome.Strike {
+				t.Errorf("outcome = %+v, want an exempt cancellation without a strike", outcome)
+			}
+			if outcome.RefundAmount != tt.wantRefund || outcome.Fee != 0 {
+				t.Errorf("refund %d and fee %d, want %d and 0", outcome.RefundAmount, outcome.Fee, tt.wantRefund)
+			}
+		})
+	}
+}
@@ -1,18 +1,77 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"courtopia-reserve/backend/pkg/utils"
 )
 
+const DefaultJWTSecret = "your-secret-key"
+
 type Config struct {
 	MongoURI    string
 	Port        int
 	JWTSecret   string
 	Environment string
+
+	JWTAlgorithm        string
+	JWTRotationInterval time.Duration
+	JWTKeyEncryptionKey []byte
+
+	OIDCIssuer            string
+	OIDCClientID          string
+	OIDCClientSecret      string
+	OIDCRedirectURL       string
+	OIDCScopes            []string
+	OIDCStudentIDClaim    string
+	OIDCNameClaim         string
+	OIDCEmailClaim        string
+	OIDCPostLoginRedirect string
+
+	TOTPIssuer      string
+	AdminRequire2FA bool
+
+	AppBaseURL string
+
+	OpeningTime string
+	ClosingTime string
+
+	DefaultFacilityName string
+	FacilityTimezone    string
+
+	AuditRetention time.Duration
+
+	PaymentProvider      string
+	PromptPayID          string
+	PaymentWebhookSecret string
+	PaymentHold          time.Duration
+
+	CancelFreeWindow       time.Duration
+	CancelLateWindow       time.Duration
+	CancelPartialRefundPct int
+	LateCancelStrikeLimit  int
+	LateCancelStrikeWindow time.Duration
+	BookingBanDuration     time.Duration
+
+	BookingQuota           int
+	MembershipReminderDays int
+	CourtCapacity          int
+	BookingTransferTTL     time.Duration
+
+	InvoiceIssuerName   string
+	InvoiceIssuerNameTH string
+	InvoiceTaxID        string
+	InvoiceAddress      string
+	InvoiceFontPath     string
 }
 
 func LoadConfig() (*Config, error) {
@@ -26,8 +85,36 @@ func LoadConfig() (*Config, error) {
 	cfg := &Config{
 		MongoURI:    "mongodb://localhost:27017",
 		Port:        8000,
-		JWTSecret:   "your-secret-key",
+		JWTSecret:   DefaultJWTSecret,
 		Environment: "development",
+		TOTPIssuer:  "Courtopia",
+		AppBaseURL:  "http://localhost:8080",
+		OpeningTime: "08:00",
+		ClosingTime: "22:00",
+
+		DefaultFacilityName: "Main Sports Hall",
+		FacilityTimezone:    "Asia/Bangkok",
+
+		JWTAlgorithm:        "HS256",
+		JWTRotationInterval: 30 * 24 * time.Hour,
+		AuditRetention:      365 * 24 * time.Hour,
+
+		PaymentHold: 15 * time.Minute,
+
+		CancelFreeWindow:       24 * time.Hour,
+		CancelLateWindow:       2 * time.Hour,
+		CancelPartialRefundPct: 50,
+		LateCancelStrikeLimit:  3,
+		LateCancelStrikeWindow: 30 * 24 * time.Hour,
+		BookingBanDuration:     7 * 24 * time.Hour,
+
+		MembershipReminderDays: 7,
+		CourtCapacity:          4,
+		BookingTransferTTL:     24 * time.Hour,
+
+		InvoiceIssuerName:   "Courtopia Sports Centre",
+		InvoiceIssuerNameTH: "ศูนย์กีฬาคอร์ทโทเปีย",
+		InvoiceTaxID:        "0000000000000",
 	}
 
 	if mongoURI := os.Getenv("MONGO_URI"); mongoURI != "" {
@@ -51,6 +138,239 @@ func LoadConfig() (*Config, error) {
 	if env := os.Getenv("ENVIRONMENT"); env != "" {
 		cfg.Environment = env
 	}
+
+	if alg := os.Getenv("JWT_ALGORITHM"); alg != "" {
+		cfg.JWTAlgorithm = alg
+	}
+	switch cfg.JWTAlgorithm {
+	case "HS256", "RS256", "EdDSA":
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q", cfg.JWTAlgorithm)
+	}
+
+	if days := os.Getenv("JWT_ROTATION_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid JWT_ROTATION_DAYS %q", days)
+		}
+		cfg.JWTRotationInterval = time.Duration(n) * 24 * time.Hour
+	}
+
+	if cfg.Environment == "production" && cfg.JWTAlgorithm == "HS256" && cfg.JWTSecret == DefaultJWTSecret {
+		return nil, errors.New("JWT_SECRET must be set in production")
+	}
+
+	// Asymmetric private keys are stored encrypted with this key. Outside
+	// production it falls back to one derived from JWT_SECRET.
+	if kek := os.Getenv("JWT_KEY_ENCRYPTION_KEY"); kek != "" {
+		key, err := base64.StdEncoding.DecodeString(kek)
+		if err != nil || len(key) != 32 {
+			return nil, errors.New("JWT_KEY_ENCRYPTION_KEY must be 32 bytes, base64 encoded")
+		}
+		cfg.JWTKeyEncryptionKey = key
+	} else if cfg.JWTAlgorithm != "HS256" {
+		if cfg.Environment == "production" {
+			return nil, errors.New("JWT_KEY_ENCRYPTION_KEY must be set in production")
+		}
+		sum := sha256.Sum256([]byte(cfg.JWTSecret))
+		cfg.JWTKeyEncryptionKey = sum[:]
+	}
+
+	cfg.OIDCIssuer = os.Getenv("OIDC_ISSUER")
+	cfg.OIDCClientID = os.Getenv("OIDC_CLIENT_ID")
+	cfg.OIDCClientSecret = os.Getenv("OIDC_CLIENT_SECRET")
+	cfg.OIDCRedirectURL = os.Getenv("OIDC_REDIRECT_URL")
+	cfg.OIDCStudentIDClaim = os.Getenv("OIDC_STUDENT_ID_CLAIM")
+	cfg.OIDCNameClaim = os.Getenv("OIDC_NAME_CLAIM")
+	cfg.OIDCEmailClaim = os.Getenv("OIDC_EMAIL_CLAIM")
+	cfg.OIDCPostLoginRedirect = os.Getenv("OIDC_POST_LOGIN_REDIRECT")
+	if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+		cfg.OIDCScopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+	}
+	if baseURL := os.Getenv("APP_BASE_URL"); baseURL != "" {
+		cfg.AppBaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+
+	if hours := os.Getenv("OPERATING_HOURS"); hours != "" {
+		opening, closing, ok := strings.Cut(hours, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid OPERATING_HOURS %q, use HH:MM-HH:MM", hours)
+		}
+		cfg.OpeningTime, cfg.ClosingTime = strings.TrimSpace(opening), strings.TrimSpace(closing)
+	}
+	openAt, errOpen := time.Parse("15:04", cfg.OpeningTime)
+	closeAt, errClose := time.Parse("15:04", cfg.ClosingTime)
+	if errOpen != nil || errClose != nil || !closeAt.After(openAt) {
+		return nil, fmt.Errorf("invalid operating hours %s-%s", cfg.OpeningTime, cfg.ClosingTime)
+	}
+
+	if name := os.Getenv("DEFAULT_FACILITY_NAME"); name != "" {
+		cfg.DefaultFacilityName = name
+	}
+	if tz := os.Getenv("FACILITY_TIMEZONE"); tz != "" {
+		cfg.FacilityTimezone = tz
+	}
+	if _, err := time.LoadLocation(cfg.FacilityTimezone); err != nil {
+		return nil, fmt.Errorf("invalid FACILITY_TIMEZONE %q", cfg.FacilityTimezone)
+	}
+
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		cfg.TOTPIssuer = issuer
+	}
+
+	if require2FA := os.Getenv("ADMIN_REQUIRE_2FA"); require2FA != "" {
+		cfg.AdminRequire2FA, _ = strconv.ParseBool(require2FA)
+	}
+	// A retention of zero keeps audit entries forever.
+	if days := os.Getenv("AUDIT_RETENTION_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid AUDIT_RETENTION_DAYS %q", days)
+		}
+		cfg.AuditRetention = time.Duration(n) * 24 * time.Hour
+	}
+
+	// Online payments stay off until a provider is chosen. The only
+	// provider so far is the fake one, which confirms any correctly signed
+	// event and so is only for development and tests. Until a real
+	// provider is added, production runs without PromptPay bookings and
+	// wallet top-ups.
+	cfg.PaymentProvider = os.Getenv("PAYMENT_PROVIDER")
+	switch cfg.PaymentProvider {
+	case "":
+	case "fake":
+		if cfg.Environment == "production" {
+			return nil, errors.New("PAYMENT_PROVIDER=fake cannot be used in production")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PAYMENT_PROVIDER %q", cfg.PaymentProvider)
+	}
+	cfg.PromptPayID = os.Getenv("PROMPTPAY_ID")
+	if cfg.PaymentProvider != "" {
+		if cfg.PromptPayID == "" || strings.Trim(cfg.PromptPayID, "0") == "" {
+			return nil, errors.New("PROMPTPAY_ID must be set to a real PromptPay ID when PAYMENT_PROVIDER is")
+		}
+		if _, err := utils.PromptPayPayload(cfg.PromptPayID, 1); err != nil {
+			return nil, fmt.Errorf("invalid PROMPTPAY_ID: %v", err)
+		}
+	}
+	cfg.PaymentWebhookSecret = os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if cfg.PaymentProvider != "" && cfg.PaymentWebhookSecret == "" {
+		return nil, errors.New("PAYMENT_WEBHOOK_SECRET must be set when PAYMENT_PROVIDER is")
+	}
+	if minutes := os.Getenv("PAYMENT_HOLD_MINUTES"); minutes != "" {
+		n, err := strconv.Atoi(minutes)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid PAYMENT_HOLD_MINUTES %q", minutes)
+		}
+		cfg.PaymentHold = time.Duration(n) * time.Minute
+	}
+
+	if hours := os.Getenv("CANCEL_FREE_HOURS"); hours != "" {
+		n, err := strconv.Atoi(hours)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid CANCEL_FREE_HOURS %q", hours)
+		}
+		cfg.CancelFreeWindow = time.Duration(n) * time.Hour
+	}
+	if hours := os.Getenv("CANCEL_LATE_HOURS"); hours != "" {
+		n, err := strconv.Atoi(hours)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid CANCEL_LATE_HOURS %q", hours)
+		}
+		cfg.CancelLateWindow = time.Duration(n) * time.Hour
+	}
+	if cfg.CancelLateWindow > cfg.CancelFreeWindow {
+		return nil, errors.New("CANCEL_LATE_HOURS cannot be longer than CANCEL_FREE_HOURS")
+	}
+	if pct := os.Getenv("CANCEL_PARTIAL_REFUND_PCT"); pct != "" {
+		n, err := strconv.Atoi(pct)
+		if err != nil || n < 0 || n > 100 {
+			return nil, fmt.Errorf("invalid CANCEL_PARTIAL_REFUND_PCT %q", pct)
+		}
+		cfg.CancelPartialRefundPct = n
+	}
+	// A strike limit of zero records strikes but never bans.
+	if limit := os.Getenv("LATE_CANCEL_STRIKE_LIMIT"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid LATE_CANCEL_STRIKE_LIMIT %q", limit)
+		}
+		cfg.LateCancelStrikeLimit = n
+	}
+	if days := os.Getenv("LATE_CANCEL_STRIKE_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid LATE_CANCEL_STRIKE_DAYS %q", days)
+		}
+		cfg.LateCancelStrikeWindow = time.Duration(n) * 24 * time.Hour
+	}
+	if days := os.Getenv("BOOKING_BAN_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid BOOKING_BAN_DAYS %q", days)
+		}
+		cfg.BookingBanDuration = time.Duration(n) * 24 * time.Hour
+	}
+
+	// A quota of zero allows any number of upcoming bookings. Membership
+	// plans can set their own quota.
+	if quota := os.Getenv("BOOKING_QUOTA"); quota != "" {
+		n, err := strconv.Atoi(quota)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid BOOKING_QUOTA %q", quota)
+		}
+		cfg.BookingQuota = n
+	}
+	if days := os.Getenv("MEMBERSHIP_REMINDER_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid MEMBERSHIP_REMINDER_DAYS %q", days)
+		}
+		cfg.MembershipReminderDays = n
+	}
+	// Courts without their own capacity allow this many players, the
+	// booker included.
+	if capacity := os.Getenv("COURT_CAPACITY"); capacity != "" {
+		n, err := strconv.Atoi(capacity)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid COURT_CAPACITY %q", capacity)
+		}
+		cfg.CourtCapacity = n
+	}
+	// Transfer offers also lapse when the booking starts.
+	if hours := os.Getenv("BOOKING_TRANSFER_HOURS"); hours != "" {
+		n, err := strconv.Atoi(hours)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid BOOKING_TRANSFER_HOURS %q", hours)
+		}
+		cfg.BookingTransferTTL = time.Duration(n) * time.Hour
+	}
+
+	if name := os.Getenv("INVOICE_ISSUER_NAME"); name != "" {
+		cfg.InvoiceIssuerName = name
+	}
+	if name := os.Getenv("INVOICE_ISSUER_NAME_TH"); name != "" {
+		cfg.InvoiceIssuerNameTH = name
+	}
+	if taxID := os.Getenv("INVOICE_TAX_ID"); taxID != "" {
+		if len(taxID) != 13 || strings.Trim(taxID, "0123456789") != "" {
+			return nil, fmt.Errorf("invalid INVOICE_TAX_ID %q, expected 13 digits", taxID)
+		}
+		cfg.InvoiceTaxID = taxID
+	}
+	cfg.InvoiceAddress = os.Getenv("INVOICE_ADDRESS")
+	// Thai text on invoices needs a TrueType font with Thai glyphs, such as
+	// Sarabun. Without one the documents are rendered in English only.
+	cfg.InvoiceFontPath = os.Getenv("INVOICE_FONT_PATH")
 	fmt.Printf("db: %s\n", cfg.MongoURI)
 	return cfg, nil
 }
+
+// OperatingMinutes is the number of bookable minutes per court per day
+// between two HH:MM times.
+func OperatingMinutes(opening, closing string) int {
+	openAt, _ := time.Parse("15:04", opening)
+	closeAt, _ := time.Parse("15:04", closing)
+	return int(closeAt.Sub(openAt).Minutes())
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaymentProviderConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		provider    string
+		secret      string
+		promptPayID string
+		wantErr     string
+	}{
+		{name: "payments off by default", environment: "production"},
+		{name: "fake provider in development", environment: "development", provider: "fake", secret: "whsec", promptPayID: "0812345678"},
+		{name: "fake provider in production", environment: "production", provider: "fake", secret: "whsec", promptPayID: "0812345678", wantErr: "cannot be used in production"},
+		{name: "fake provider without secret", environment: "development", provider: "fake", promptPayID: "0812345678", wantErr: "PAYMENT_WEBHOOK_SECRET"},
+		{name: "fake provider without PromptPay ID", environment: "development", provider: "fake", secret: "whsec", wantErr: "PROMPTPAY_ID"},
+		{name: "placeholder PromptPay ID", environment: "development", provider: "fake", secret: "whsec", promptPayID: "0000000000", wantErr: "PROMPTPAY_ID"},
+		{name: "invalid PromptPay ID", environment: "development", provider: "fake", secret: "whsec", promptPayID: "12345", wantErr: "invalid PROMPTPAY_ID"},
+		{name: "unknown provider", environment: "development", provider: "omise", secret: "whsec", promptPayID: "0812345678", wantErr: "unsupported PAYMENT_PROVIDER"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ENVIRONMENT", tt.environment)
+			t.Setenv("JWT_SECRET", "test-secret")
+			t.Setenv("PAYMENT_PROVIDER", tt.provider)
+			t.Setenv("PAYMENT_WEBHOOK_SECRET", tt.secret)
+			t.Setenv("PROMPTPAY_ID", tt.promptPayID)
+
+			cfg, err := LoadConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if cfg.PaymentProvider != tt.provider {
+				t.Errorf("PaymentProvider = %q, want %q", cfg.PaymentProvider, tt.provider)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/ledger"
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+const (
+	profilePictureDir = "uploads/profile_pictures"
+
+	// deletedStudentPrefix starts the student ID left on the bookings of a
+	// deleted account. Registration refuses it, so no new account can claim
+	// the bookings through the student ID.
+	deletedStudentPrefix = "deleted:"
+)
+
+func (h *Handler) ExportAccountData(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.collectAccountData(c.Request.Context(), user)
+	if err != nil {
+		log.Printf("Error collecting account data for %s: %v", user.StudentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account data"})
+		return
+	}
+
+	filename := fmt.Sprintf("courtopia-%s-%s", user.StudentID, export.ExportedAt.Format("20060102"))
+
+	if c.DefaultQuery("format", "json") != "zip" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	c.Status(http.StatusOK)
+
+	if err := writeAccountZip(c.Writer, user, export); err != nil {
+		log.Printf("Error writing account export for %s: %v", user.StudentID, err)
+	}
+}
+
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	audit(c, "profile.delete", "user", user.ID.Hex())
+
+	var req models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.StudentID != user.StudentID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Student ID confirmation does not match"})
+		return
+	}
+
+	if user.Password != "" && !utils.CheckPasswordHash(req.Password, user.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "รหัสผ่านไม่ถูกต้อง"})
+		return
+	}
+
+	cancelled, payout, err := h.deleteAccount(c.Request.Context(), user, user.StudentID)
+	if err != nil {
+		log.Printf("Error deleting account %s: %v", user.StudentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	if payout > 0 {
+		auditMeta(c, "walletPayout", strconv.FormatInt(payout, 10))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Account deleted successfully",
+		"cancelledBookings": cancelled,
+		"walletPayout":      payout,
+	})
+}
+
+func (h *Handler) collectAccountData(ctx context.Context, user *models.User) (*models.AccountExport, error) {
+	bookings, err := h.bookingRepo.FindByStudentID(ctx, user.StudentID)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := h.notificationRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := h.tokenRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AccountExport{
+		ExportedAt:    time.Now(),
+		Profile:       user,
+		Bookings:      bookings,
+		Notifications: notifications,
+		APITokens:     tokens,
+	}, nil
+}
+
+// deleteAccount removes a user and their personal data. Future bookings are
+// cancelled through the normal path with a full refund, the wallet is then
+// emptied into a payout for staff to settle, and past bookings are kept for
+// court statistics with the denormalised student ID and email stripped. It
+// returns the number of cancelled bookings and the amount paid out.
+func (h *Handler) deleteAccount(ctx context.Context, user *models.User, actor string) (int64, int64, error) {
+	cancelled, err := h.cancelFutureBookings(ctx, user, actor)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	payout, err := h.settleWallet(ctx, user, actor)
+	if err != nil {
+		return cancelled, 0, err
+	}
+
+	if err := h.bookingRepo.AnonymizeByStudentID(ctx, user.StudentID, deletedStudentPrefix+user.ID.Hex()); err != nil {
+		return cancelled, payout, err
+	}
+
+	if err := h.tokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return cancelled, payout, err
+	}
+
+	if err := h.notificationRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return cancelled, payout, err
+	}
+
+	if err := h.facilityRepo.RemoveAdmin(ctx, user.ID); err != nil {
+		return cancelled, payout, err
+	}
+
+	if err := h.userRepo.Delete(ctx, user.ID); err != nil {
+		return cancelled, payout, err
+	}
+
+	removeProfilePictures(user)
+
+	return cancelled, payout, nil
+}
+
+// cancelFutureBookings cancels every booking of the user that still holds a
+// slot that has not started, refunding it in full.
+func (h *Handler) cancelFutureBookings(ctx context.Context, user *models.User, actor string) (int64, error) {
+	bookings, err := h.bookingRepo.FindByStudentID(ctx, user.StudentID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	facilities := map[primitive.ObjectID]*models.Facility{}
+	var cancelled int64
+	for _, booking := range bookings {
+		if booking.Status != "active" && booking.Status != "pending_payment" {
+			continue
+		}
+		facility, ok := facilities[booking.FacilityID]
+		if !ok {
+			facility, err = h.facilityRepo.FindByID(ctx, booking.FacilityID)
+			if err != nil {
+				return cancelled, err
+			}
+			facilities[booking.FacilityID] = facility
+		}
+		if !facilityInstant(facility, booking.StartTime).After(now) {
+			continue
+		}
+
+		err := h.cancelBooking(ctx, booking, exemptCancellation(booking, actor))
+		if errors.Is(err, errBookingNotCancellable) {
+			continue
+		}
+		if err != nil {
+			return cancelled, err
+		}
+		cancelled++
+	}
+
+	return cancelled, nil
+}
+
+// settleWallet empties the user's wallet into a payout and returns the
+// amount owed back to them.
+func (h *Handler) settleWallet(ctx context.Context, user *models.User, actor string) (int64, error) {
+	wallet, err := h.walletRepo.FindByUser(ctx, user, pricing.Currency)
+	if err != nil || wallet.Balance <= 0 {
+		return 0, err
+	}
+
+	txn := ledger.Payout(user, wallet.Balance)
+	txn.CreatedBy = actor
+	txn.Memo = "Account deleted"
+	err = repository.RunInTransaction(ctx, h.db, func(ctx context.Context) error {
+		return h.walletRepo.Post(ctx, txn)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return wallet.Balance, nil
+}
+
+// removeProfilePictures deletes every picture recorded as uploaded by the
+// user, not only the current one, since re-uploads never cleaned up older
+// files. Pictures uploaded before uploads were recorded are only known by
+// the current one. File names are never matched by prefix: one student ID
+// can be the prefix of another.
+func removeProfilePictures(user *models.User) {
+	names := user.ProfilePictures
+	if idx := strings.Index(user.ProfilePicture, profilePictureDir+"/"); idx >= 0 {
+		names = append(names, user.ProfilePicture[idx+len(profilePictureDir)+1:])
+	}
+
+	for _, name := range names {
+		err := os.Remove(filepath.Join(profilePictureDir, filepath.Base(name)))
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("Error removing profile picture %s: %v", name, err)
+		}
+	}
+}
+
+func writeAccountZip(w io.Writer, user *models.User, export *models.AccountExport) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"bookings.json", export.Bookings},
+		{"notifications.json", export.Notifications},
+		{"api_tokens.json", export.APITokens},
+	}
+	for _, file := range files {
+		f, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	if idx := strings.Index(user.ProfilePicture, profilePictureDir+"/"); idx >= 0 {
+		name := filepath.Base(user.ProfilePicture[idx:])
+		if err := copyFileToZip(zw, filepath.Join(profilePictureDir, name), "profile_picture/"+name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func copyFileToZip(zw *zip.Writer, path, name string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	return err
+}
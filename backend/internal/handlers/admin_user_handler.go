@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+const (
+	passwordResetLifetime = 24 * time.Hour
+
+	defaultImpersonationMinutes = 15
+	maxImpersonationMinutes     = 60
+)
+
+var userRoles = map[string]bool{
+	"user":            true,
+	"staff":           true,
+	roleFacilityAdmin: true,
+	"admin":           true,
+}
+
+func (h *Handler) ListUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	search := repository.UserSearch{
+		Query: c.Query("q"),
+		Role:  c.Query("role"),
+		Page:  page,
+		Limit: limit,
+	}
+	if suspended := c.Query("suspended"); suspended != "" {
+		value, err := strconv.ParseBool(suspended)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid suspended filter"})
+			return
+		}
+		search.Suspended = &value
+	}
+
+	users, total, err := h.userRepo.Search(c.Request.Context(), search)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.UserSearchResponse{
+		Users: users,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	})
+}
+
+func (h *Handler) GetUser(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.bookingRepo.StatsByStudentID(c.Request.Context(), user.StudentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AdminUserResponse{User: user, Stats: stats})
+}
+
+func (h *Handler) UpdateUserRole(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+
+	var req models.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if !userRoles[req.Role] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+	audit(c, "user.role", "user", user.ID.Hex())
+
+	// Existing sessions carry the old role in their claims, so they are
+	// revoked and the user has to log in again.
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"role":               req.Role,
+		"tokens_valid_after": now,
+		"updated_at":         now,
+	}}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	if user.Role == roleFacilityAdmin && req.Role != roleFacilityAdmin {
+		if err := h.facilityRepo.RemoveAdmin(c.Request.Context(), user.ID); err != nil {
+			log.Printf("Error removing %s from facility admins: %v", user.StudentID, err)
+		}
+	}
+
+	updated := *user
+	updated.Role = req.Role
+	auditDiff(c, user, &updated)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role updated successfully"})
+}
+
+func (h *Handler) SuspendUser(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+
+	var req models.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"suspended":      true,
+		"suspended_at":   now,
+		"suspend_reason": req.Reason,
+		"updated_at":     now,
+	}}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suspend user"})
+		return
+	}
+
+	audit(c, "user.suspend", "user", user.ID.Hex())
+	auditMeta(c, "reason", req.Reason)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User suspended successfully"})
+}
+
+func (h *Handler) UnsuspendUser(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"suspended":  false,
+			"updated_at": time.Now(),
+		},
+		"$unset": bson.M{
+			"suspended_at":   "",
+			"suspend_reason": "",
+		},
+	}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsuspend user"})
+		return
+	}
+
+	audit(c, "user.unsuspend", "user", user.ID.Hex())
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unsuspended successfully"})
+}
+
+// ForcePasswordReset revokes the user's sessions, blocks password login and
+// emails a single-use reset link.
+func (h *Handler) ForcePasswordReset(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+
+	if user.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User has no email address to send the reset link to"})
+		return
+	}
+
+	token, err := utils.RandomString(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server error"})
+		return
+	}
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"must_reset_password":    true,
+		"password_reset_hash":    utils.HashToken(token),
+		"password_reset_expires": now.Add(passwordResetLifetime),
+		"tokens_valid_after":     now,
+		"updated_at":             now,
+	}}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	audit(c, "user.password_reset", "user", user.ID.Hex())
+
+	body := fmt.Sprintf(
+		"Dear %s,\n\nAn administrator has required you to choose a new password. Use the link below within 24 hours:\n\n%s/reset-password?token=%s\n\nThank you for using Courtminton!",
+		user.Name,
+		h.cfg.AppBaseURL,
+		token,
+	)
+	if err := deliverEmail(c.Request.Context(), h.notificationRepo, user, "password_reset", "Password reset required", body, nil); err != nil {
+		log.Printf("Error sending password reset email to %s: %v", user.Email, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Password reset was forced but the email could not be sent"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset email sent"})
+}
+
+func (h *Handler) DeleteUser(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+
+	audit(c, "user.delete", "user", user.ID.Hex())
+	auditMeta(c, "studentId", user.StudentID)
+
+	actor := c.MustGet("user").(*utils.Claims).StudentID
+	cancelled, payout, err := h.deleteAccount(c.Request.Context(), user, actor)
+	if err != nil {
+		log.Printf("Error deleting account %s: %v", user.StudentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+	auditMeta(c, "cancelledBookings", strconv.FormatInt(cancelled, 10))
+	if payout > 0 {
+		auditMeta(c, "walletPayout", strconv.FormatInt(payout, 10))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "User deleted successfully",
+		"cancelledBookings": cancelled,
+		"walletPayout":      payout,
+	})
+}
+
+// ImpersonateUser issues a short-lived token that lets an admin see the app
+// as the user does. The token carries an act claim naming the admin, every
+// request made with it is audited, and anything but read-only requests is
+// refused unless allowDestructive is set. Only plain users can be
+// impersonated, so the token never carries staff or admin rights.
+func (h *Handler) ImpersonateUser(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+	audit(c, "user.impersonate", "user", user.ID.Hex())
+
+	var req models.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required to impersonate a user"})
+		return
+	}
+
+	if req.Minutes == 0 {
+		req.Minutes = defaultImpersonationMinutes
+	}
+	if req.Minutes < 1 || req.Minutes > maxImpersonationMinutes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Minutes must be between 1 and %d", maxImpersonationMinutes)})
+		return
+	}
+
+	if user.Role != "user" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only user accounts can be impersonated"})
+		return
+	}
+	if user.Suspended {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Suspended accounts cannot be impersonated"})
+		return
+	}
+
+	actor, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	lifetime := time.Duration(req.Minutes) * time.Minute
+	token, err := utils.GenerateToken(user, h.keys, lifetime, utils.WithActor(actor, req.AllowDestructive))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	auditMeta(c, "reason", req.Reason)
+	auditMeta(c, "minutes", strconv.Itoa(req.Minutes))
+	auditMeta(c, "allowDestructive", strconv.FormatBool(req.AllowDestructive))
+
+	c.JSON(http.StatusOK, models.ImpersonateResponse{
+		Token:            token,
+		ExpiresAt:        time.Now().Add(lifetime),
+		StudentID:        user.StudentID,
+		Name:             user.Name,
+		Role:             user.Role,
+		ImpersonatedBy:   actor.StudentID,
+		AllowDestructive: req.AllowDestructive,
+	})
+}
+
+func (h *Handler) findUserParam(c *gin.Context) (*models.User, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil, false
+	}
+
+	user, err := h.userRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// notSelf stops admins from demoting, suspending or deleting themselves and
+// locking everyone out of the console.
+func (h *Handler) notSelf(c *gin.Context, user *models.User) bool {
+	claims := c.MustGet("user").(*utils.Claims)
+	if claims.Subject == user.ID.Hex() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot perform this action on your own account"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"courtopia-reserve/backend/internal/config"
+	"courtopia-reserve/backend/internal/models"
+)
+
+const maxAnalyticsRange = 366 * 24 * time.Hour
+
+func (h *Handler) GetUtilization(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", "day")
+	if granularity != "day" && granularity != "week" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Granularity must be day or week"})
+		return
+	}
+
+	entries, err := h.utilization(c, from, to, granularity == "week")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute utilization"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":        from.Format("2006-01-02"),
+		"to":          to.Format("2006-01-02"),
+		"granularity": granularity,
+		"entries":     entries,
+	})
+}
+
+func (h *Handler) GetPeakHeatmap(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cells, err := h.analyticsRepo.Heatmap(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute heatmap"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":  from.Format("2006-01-02"),
+		"to":    to.Format("2006-01-02"),
+		"cells": cells,
+	})
+}
+
+func (h *Handler) GetAnalyticsSummary(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	summary, err := h.analyticsRepo.Summary(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute summary"})
+		return
+	}
+	summary.From = from.Format("2006-01-02")
+	summary.To = to.Format("2006-01-02")
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *Handler) GetTopBookers(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be between 1 and 100"})
+		return
+	}
+
+	bookers, err := h.analyticsRepo.TopBookers(c.Request.Context(), from, to, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute top bookers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from.Format("2006-01-02"),
+		"to":      to.Format("2006-01-02"),
+		"bookers": bookers,
+	})
+}
+
+// utilization reports every court for every period in the range, including
+// periods without bookings, against the operating hours of the court's
+// facility.
+func (h *Handler) utilization(c *gin.Context, from, to time.Time, weekly bool) ([]*models.UtilizationEntry, error) {
+	ctx := c.Request.Context()
+
+	courts, err := h.courtRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	facilities, err := h.facilityRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dailyMinutes := map[string]int{}
+	for _, facility := range facilities {
+		dailyMinutes[facility.ID.Hex()] = config.OperatingMinutes(facility.OpeningTime, facility.ClosingTime)
+	}
+
+	usage, err := h.analyticsRepo.CourtUsage(ctx, from, to, weekly)
+	if err != nil {
+		return nil, err
+	}
+
+	booked := map[string]float64{}
+	for _, u := range usage {
+		booked[u.CourtID.Hex()+"|"+u.Period] += u.BookedMinutes
+	}
+
+	var periods []string
+	openDays := map[string]int{}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		key := periodKey(d, weekly)
+		if openDays[key] == 0 {
+			periods = append(periods, key)
+		}
+		openDays[key]++
+	}
+
+	entries := []*models.UtilizationEntry{}
+	for _, court := range courts {
+		for _, period := range periods {
+			openMinutes := openDays[period] * dailyMinutes[court.FacilityID.Hex()]
+			minutes := booked[court.ID.Hex()+"|"+period]
+
+			entry := &models.UtilizationEntry{
+				FacilityID:    court.FacilityID,
+				CourtID:       court.ID,
+				CourtNumber:   court.CourtNumber,
+				Period:        period,
+				BookedMinutes: minutes,
+				OpenMinutes:   openMinutes,
+			}
+			if openMinutes > 0 {
+				entry.UtilizationPct = math.Round(minutes/float64(openMinutes)*10000) / 100
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func periodKey(d time.Time, weekly bool) string {
+	if !weekly {
+		return d.Format("2006-01-02")
+	}
+	year, week := d.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// parseDateRange reads the inclusive from/to query dates, defaulting to
+// the last 30 days.
+func parseDateRange(c *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -29)
+
+	if s := c.Query("from"); s != "" {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid from date, use YYYY-MM-DD")
+		}
+		from = d
+	}
+	if s := c.Query("to"); s != "" {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid to date, use YYYY-MM-DD")
+		}
+		to = d
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("The to date must not be before the from date")
+	}
+	if to.Sub(from) > maxAnalyticsRange {
+		return time.Time{}, time.Time{}, errors.New("Date range cannot exceed one year")
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+var announcementSeverities = map[string]bool{
+	"info":     true,
+	"warning":  true,
+	"critical": true,
+}
+
+// GetAnnouncements lists the notices visible right now, optionally limited
+// to facility-wide notices and those for one court.
+func (h *Handler) GetAnnouncements(c *gin.Context) {
+	var courtID *primitive.ObjectID
+	if s := c.Query("courtId"); s != "" {
+		id, err := primitive.ObjectIDFromHex(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court ID"})
+			return
+		}
+		courtID = &id
+	}
+
+	announcements, err := h.announcementRepo.FindActive(c.Request.Context(), time.Now(), courtID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, announcements)
+}
+
+func (h *Handler) GetCourtAnnouncements(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court ID"})
+		return
+	}
+
+	announcements, err := h.announcementRepo.FindActive(c.Request.Context(), time.Now(), &id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, announcements)
+}
+
+func (h *Handler) ListAllAnnouncements(c *gin.Context) {
+	announcements, err := h.announcementRepo.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, announcements)
+}
+
+func (h *Handler) CreateAnnouncement(c *gin.Context) {
+	claims := c.MustGet("user").(*utils.Claims)
+
+	var req models.CreateAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	announcement := &models.Announcement{
+		ID:          primitive.NewObjectID(),
+		Title:       strings.TrimSpace(req.Title),
+		Body:        strings.TrimSpace(req.Body),
+		Severity:    req.Severity,
+		VisibleFrom: time.Now(),
+		CreatedBy:   claims.StudentID,
+	}
+	if announcement.Severity == "" {
+		announcement.Severity = "info"
+	}
+	if req.VisibleFrom != nil {
+		announcement.VisibleFrom = *req.VisibleFrom
+	}
+	announcement.VisibleUntil = req.VisibleUntil
+
+	if !h.applyAnnouncementScope(c, announcement, req.CourtID) || !validAnnouncement(c, announcement) {
+		return
+	}
+
+	if err := h.announcementRepo.Create(c.Request.Context(), announcement); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create announcement"})
+		return
+	}
+
+	audit(c, "announcement.create", "announcement", announcement.ID.Hex())
+	auditDiff(c, nil, announcement)
+
+	c.JSON(http.StatusCreated, announcement)
+}
+
+func (h *Handler) UpdateAnnouncement(c *gin.Context) {
+	announcement, ok := h.findAnnouncementParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "announcement.update", "announcement", announcement.ID.Hex())
+
+	var req models.UpdateAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	before := *announcement
+	if req.Title != nil {
+		announcement.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Body != nil {
+		announcement.Body = strings.TrimSpace(*req.Body)
+	}
+	if req.Severity != nil {
+		announcement.Severity = *req.Severity
+	}
+	if req.VisibleFrom != nil {
+		announcement.VisibleFrom = *req.VisibleFrom
+	}
+	if req.VisibleUntil != nil {
+		announcement.VisibleUntil = req.VisibleUntil
+	}
+	if req.CourtID != nil && !h.applyAnnouncementScope(c, announcement, *req.CourtID) {
+		return
+	}
+	if !validAnnouncement(c, announcement) {
+		return
+	}
+
+	if err := h.announcementRepo.Update(c.Request.Context(), announcement); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update announcement"})
+		return
+	}
+	auditDiff(c, &before, announcement)
+
+	c.JSON(http.StatusOK, announcement)
+}
+
+// ExpireAnnouncement ends the visibility window now. Expired announcements
+// are kept so admins can see what was posted.
+func (h *Handler) ExpireAnnouncement(c *gin.Context) {
+	announcement, ok := h.findAnnouncementParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "announcement.expire", "announcement", announcement.ID.Hex())
+
+	now := time.Now()
+	if announcement.VisibleUntil != nil && !announcement.VisibleUntil.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Announcement has already expired"})
+		return
+	}
+
+	before := *announcement
+	announcement.VisibleUntil = &now
+	if announcement.VisibleFrom.After(now) {
+		announcement.VisibleFrom = now
+	}
+
+	if err := h.announcementRepo.Update(c.Request.Context(), announcement); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to expire announcement"})
+		return
+	}
+	auditDiff(c, &before, announcement)
+
+	c.JSON(http.StatusOK, announcement)
+}
+
+func (h *Handler) findAnnouncementParam(c *gin.Context) (*models.Announcement, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return nil, false
+	}
+
+	announcement, err := h.announcementRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+		return nil, false
+	}
+
+	return announcement, true
+}
+
+// applyAnnouncementScope ties the announcement to a court, or makes it
+// facility-wide when courtID is empty.
+func (h *Handler) applyAnnouncementScope(c *gin.Context, announcement *models.Announcement, courtID string) bool {
+	if courtID == "" {
+		announcement.CourtID = nil
+		return true
+	}
+
+	id, err := primitive.ObjectIDFromHex(courtID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court ID"})
+		return false
+	}
+
+	court, err := h.courtRepo.FindByID(c.Request.Context(), id)
+	if err != nil || court.IsRetired {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Court not found"})
+		return false
+	}
+
+	announcement.CourtID = &court.ID
+	return true
+}
+
+func validAnnouncement(c *gin.Context, announcement *models.Announcement) bool {
+	if announcement.Title == "" || announcement.Body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title and body are required"})
+		return false
+	}
+	if !announcementSeverities[announcement.Severity] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Severity must be info, warning or critical"})
+		return false
+	}
+	if announcement.VisibleUntil != nil && !announcement.VisibleUntil.After(announcement.VisibleFrom) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "visibleUntil must be after visibleFrom"})
+		return false
+	}
+	return true
+}
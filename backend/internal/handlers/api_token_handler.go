@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+const (
+	scopeBookingsRead  = "bookings:read"
+	scopeBookingsWrite = "bookings:write"
+	scopeProfileRead   = "profile:read"
+	scopeProfileWrite  = "profile:write"
+	scopeWalletRead    = "wallet:read"
+	scopeWalletWrite   = "wallet:write"
+
+	defaultAPITokenDays = 90
+	maxAPITokenDays     = 365
+)
+
+var apiTokenScopes = map[string]bool{
+	scopeBookingsRead:  true,
+	scopeBookingsWrite: true,
+	scopeProfileRead:   true,
+	scopeProfileWrite:  true,
+	scopeWalletRead:    true,
+	scopeWalletWrite:   true,
+}
+
+var errInvalidAPIToken = errors.New("api token is revoked or expired")
+
+func (h *Handler) ListAPITokens(c *gin.Context) {
+	claims := c.MustGet("user").(*utils.Claims)
+
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	tokens, err := h.tokenRepo.FindByUserID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) CreateAPIToken(c *gin.Context) {
+	claims := c.MustGet("user").(*utils.Claims)
+
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req models.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Scopes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name and at least one scope are required"})
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !apiTokenScopes[scope] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope})
+			return
+		}
+	}
+
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = defaultAPITokenDays
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxAPITokenDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token lifetime must be between 1 and 365 days"})
+		return
+	}
+
+	rawToken, hash, err := utils.GenerateAPIToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server error"})
+		return
+	}
+
+	token := &models.APIToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		StudentID: claims.StudentID,
+		Name:      req.Name,
+		Prefix:    rawToken[:len(utils.APITokenPrefix)+6],
+		TokenHash: hash,
+		Scopes:    req.Scopes,
+		ExpiresAt: time.Now().AddDate(0, 0, req.ExpiresInDays),
+	}
+
+	if err := h.tokenRepo.Create(c.Request.Context(), token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API token"})
+		return
+	}
+
+	audit(c, "profile.token_create", "api_token", token.ID.Hex())
+	auditDiff(c, nil, token)
+
+	c.JSON(http.StatusCreated, models.CreateAPITokenResponse{
+		Token:    rawToken,
+		APIToken: token,
+	})
+}
+
+func (h *Handler) RevokeAPIToken(c *gin.Context) {
+	claims := c.MustGet("user").(*utils.Claims)
+
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	audit(c, "profile.token_revoke", "api_token", id.Hex())
+
+	revoked, err := h.tokenRepo.Revoke(c.Request.Context(), id, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API token"})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API token not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked"})
+}
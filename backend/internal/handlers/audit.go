@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	auditContextKey = "audit"
+)
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// auditRedacted lists fields whose values never reach the audit log. A
+// change is still recorded so it is visible that, say, a password changed.
+var auditRedacted = map[string]bool{
+	"password":            true,
+	"password_reset_hash": true,
+	"totp_secret":         true,
+	"totp_pending_secret": true,
+	"recovery_codes":      true,
+	"token_hash":          true,
+	"private_key":         true,
+}
+
+// auditIgnored lists bookkeeping fields that change on every write.
+var auditIgnored = map[string]bool{
+	"updated_at":     true,
+	"totp_last_step": true,
+}
+
+type auditDetails struct {
+	action     string
+	targetType string
+	targetID   string
+	changes    []*models.AuditChange
+	metadata   map[string]string
+	actor      *models.User
+}
+
+// RequestID tags every request with an ID, reusing a well-formed ID from an
+// upstream proxy, and echoes it in the response.
+func (h *Handler) RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id, _ = utils.RandomString(12)
+		}
+
+		c.Set("requestID", id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+// AuditMiddleware appends an entry for every mutating request, and for
+// every request made while impersonating, once the handler has run.
+// Handlers enrich the entry with audit, auditDiff and auditMeta; without
+// them the route and :id parameter are recorded.
+func (h *Handler) AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.FullPath() == "" || c.Request.Method == http.MethodOptions {
+			return
+		}
+
+		var userClaims *utils.Claims
+		if claims, ok := c.Get("user"); ok {
+			userClaims = claims.(*utils.Claims)
+		}
+		readOnly := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+		if readOnly && (userClaims == nil || !userClaims.IsImpersonation()) {
+			return
+		}
+
+		details := auditFor(c)
+		entry := &models.AuditLog{
+			Timestamp:  time.Now(),
+			Action:     details.action,
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			Status:     c.Writer.Status(),
+			TargetType: details.targetType,
+			TargetID:   details.targetID,
+			Changes:    details.changes,
+			Metadata:   details.metadata,
+			IP:         c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+			RequestID:  c.GetString("requestID"),
+		}
+		if entry.Action == "" {
+			entry.Action = strings.ToLower(c.Request.Method) + " " + c.FullPath()
+		}
+		if entry.TargetID == "" {
+			entry.TargetID = c.Param("id")
+		}
+
+		if userClaims != nil {
+			if id, err := primitive.ObjectIDFromHex(userClaims.Subject); err == nil {
+				entry.ActorID = &id
+			}
+			entry.ActorStudentID = userClaims.StudentID
+			entry.ActorRole = userClaims.Role
+			entry.ViaAPIToken = userClaims.IsAPIToken()
+			if userClaims.IsImpersonation() {
+				if id, err := primitive.ObjectIDFromHex(userClaims.Act.Subject); err == nil {
+					entry.ImpersonatorID = &id
+				}
+				entry.ImpersonatorStudentID = userClaims.Act.StudentID
+			}
+		} else if details.actor != nil {
+			entry.ActorID = &details.actor.ID
+			entry.ActorStudentID = details.actor.StudentID
+			entry.ActorRole = details.actor.Role
+		}
+
+		// The request context may already be cancelled if the client went
+		// away, which must not drop the entry.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := h.auditRepo.Create(ctx, entry); err != nil {
+			log.Printf("Error writing audit log for %s %s: %v", entry.Method, entry.Path, err)
+		}
+	}
+}
+
+func auditFor(c *gin.Context) *auditDetails {
+	if v, ok := c.Get(auditContextKey); ok {
+		return v.(*auditDetails)
+	}
+	details := &auditDetails{}
+	c.Set(auditContextKey, details)
+	return details
+}
+
+// audit names the action and its target for the current request.
+func audit(c *gin.Context, action, targetType, targetID string) {
+	details := auditFor(c)
+	details.action = action
+	details.targetType = targetType
+	details.targetID = targetID
+}
+
+// auditActor records who acted on unauthenticated endpoints such as login.
+func auditActor(c *gin.Context, user *models.User) {
+	auditFor(c).actor = user
+}
+
+func auditMeta(c *gin.Context, key, value string) {
+	details := auditFor(c)
+	if details.metadata == nil {
+		details.metadata = map[string]string{}
+	}
+	details.metadata[key] = value
+}
+
+// auditDiff records the fields that differ between two snapshots of a
+// document. Either side may be nil for creations and deletions.
+func auditDiff(c *gin.Context, before, after interface{}) {
+	old, err := auditDocument(before)
+	if err != nil {
+		log.Printf("Error encoding audit snapshot: %v", err)
+		return
+	}
+	updated, err := auditDocument(after)
+	if err != nil {
+		log.Printf("Error encoding audit snapshot: %v", err)
+		return
+	}
+
+	fields := map[string]bool{}
+	for field := range old {
+		fields[field] = true
+	}
+	for field := range updated {
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		if !auditIgnored[field] {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+
+	details := auditFor(c)
+	for _, field := range names {
+		oldValue, newValue := old[field], updated[field]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		change := &models.AuditChange{Field: field, Before: oldValue, After: newValue}
+		if auditRedacted[field] {
+			change.Before, change.After = nil, nil
+			if oldValue != nil {
+				change.Before = "[redacted]"
+			}
+			if newValue != nil {
+				change.After = "[redacted]"
+			}
+		}
+		details.changes = append(details.changes, change)
+	}
+}
+
+func auditDocument(v interface{}) (bson.M, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return bson.M{}, nil
+	}
+
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(data))
+	if err != nil {
+		return nil, err
+	}
+	dec.DefaultDocumentM()
+
+	doc := bson.M{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (h *Handler) ListAuditLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	filter := repository.AuditLogFilter{
+		Actor:        c.Query("actor"),
+		Impersonator: c.Query("impersonator"),
+		Action:       c.Query("action"),
+		TargetType:   c.Query("targetType"),
+		TargetID:     c.Query("targetId"),
+		Page:         page,
+		Limit:        limit,
+	}
+	if s := c.Query("from"); s != "" {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, use YYYY-MM-DD"})
+			return
+		}
+		filter.From = &d
+	}
+	if s := c.Query("to"); s != "" {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, use YYYY-MM-DD"})
+			return
+		}
+		end := d.AddDate(0, 0, 1)
+		filter.To = &end
+	}
+
+	entries, total, err := h.auditRepo.Find(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditLogResponse{
+		Entries: entries,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	})
+}
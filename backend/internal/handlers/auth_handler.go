@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
@@ -20,6 +22,11 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
+	if strings.HasPrefix(req.StudentID, deletedStudentPrefix) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
 	_, err := h.userRepo.FindByStudentID(c.Request.Context(), req.StudentID)
 	if err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "รหัสนักศึกษานี้ถูกใช้งานแล้ว"})
@@ -51,6 +58,10 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
+	audit(c, "auth.register", "user", user.ID.Hex())
+	auditActor(c, user)
+	auditDiff(c, nil, user)
+
 	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
 }
 
@@ -61,27 +72,127 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
+	audit(c, "auth.login", "user", req.StudentID)
+
 	user, err := h.userRepo.FindByStudentID(c.Request.Context(), req.StudentID)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "รหัสนักศึกษาหรือรหัสผ่านไม่ถูกต้อง"})
 		return
 	}
+	auditActor(c, user)
 
 	if !utils.CheckPasswordHash(req.Password, user.Password) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "รหัสนักศึกษาหรือรหัสผ่านไม่ถูกต้อง"})
 		return
 	}
 
-	token, err := utils.GenerateToken(user, h.jwtSecret, 24)
+	if !h.checkLoginAllowed(c, user) {
+		return
+	}
+
+	response, err := h.loginResponse(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.LoginResponse{
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	audit(c, "auth.password_reset", "user", "")
+
+	user, err := h.userRepo.FindByPasswordResetHash(c.Request.Context(), utils.HashToken(req.Token))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+	audit(c, "auth.password_reset", "user", user.ID.Hex())
+	auditActor(c, user)
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server error"})
+		return
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"password":            hashedPassword,
+			"must_reset_password": false,
+			"tokens_valid_after":  now,
+			"updated_at":          now,
+		},
+		"$unset": bson.M{
+			"password_reset_hash":    "",
+			"password_reset_expires": "",
+		},
+	}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// checkLoginAllowed writes an error response and returns false when the
+// account may not start a new session.
+func (h *Handler) checkLoginAllowed(c *gin.Context, user *models.User) bool {
+	if user.Suspended {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+		return false
+	}
+
+	if user.MustResetPassword {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":                 "Password reset required, check your email for the reset link",
+			"passwordResetRequired": true,
+		})
+		return false
+	}
+
+	return true
+}
+
+// loginResponse completes a first-factor login. Users with TOTP enabled get
+// a short-lived challenge token that must be exchanged at /auth/2fa/verify.
+func (h *Handler) loginResponse(user *models.User) (*models.LoginResponse, error) {
+	if user.TOTPEnabled {
+		challenge, err := utils.GenerateToken(user, h.keys, 5*time.Minute, utils.WithPurpose(mfaChallengePurpose))
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.LoginResponse{
+			StudentID:      user.StudentID,
+			Name:           user.Name,
+			Role:           user.Role,
+			MFARequired:    true,
+			ChallengeToken: challenge,
+		}, nil
+	}
+
+	return h.tokenResponse(user)
+}
+
+func (h *Handler) tokenResponse(user *models.User, opts ...utils.TokenOption) (*models.LoginResponse, error) {
+	token, err := utils.GenerateToken(user, h.keys, sessionTokenLifetime, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
 		Token:     token,
 		StudentID: user.StudentID,
 		Name:      user.Name,
 		Role:      user.Role,
-	})
+	}, nil
 }
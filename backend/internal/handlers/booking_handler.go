@@ -2,16 +2,19 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"net/smtp"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
 	"courtopia-reserve/backend/internal/repository"
 	"courtopia-reserve/backend/pkg/utils"
 )
@@ -36,64 +39,41 @@ func (h *Handler) CreateBooking(c *gin.Context) {
 		return
 	}
 
-	bookingDate, err := time.Parse("2006-01-02", req.BookingDate)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+	if req.PaymentMethod == "" {
+		req.PaymentMethod = models.PaymentMethodPromptPay
+	}
+	if req.PaymentMethod != models.PaymentMethodPromptPay && req.PaymentMethod != models.PaymentMethodWallet {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment method must be promptpay or wallet"})
 		return
 	}
 
-	layout := "15:04"
-	startTimeParsed, err := time.Parse(layout, req.StartTime)
+	bookingDate, startTime, endTime, err := parseBookingSlot(req.BookingDate, req.StartTime, req.EndTime)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start time format, use HH:MM"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	endTimeParsed, err := time.Parse(layout, req.EndTime)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end time format, use HH:MM"})
-		return
-	}
-
-	startTime := time.Date(
-		bookingDate.Year(),
-		bookingDate.Month(),
-		bookingDate.Day(),
-		startTimeParsed.Hour(),
-		startTimeParsed.Minute(),
-		0,
-		0,
-		bookingDate.Location(),
-	)
-
-	endTime := time.Date(
-		bookingDate.Year(),
-		bookingDate.Month(),
-		bookingDate.Day(),
-		endTimeParsed.Hour(),
-		endTimeParsed.Minute(),
-		0,
-		0,
-		bookingDate.Location(),
-	)
-
-	if startTime.Before(time.Now()) {
+	facility, ok := h.resolveFacility(c, req.FacilityID)
+	if !ok {
+		return
+	}
+
+	if facilityInstant(facility, startTime).Before(time.Now()) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking time must be in the future"})
 		return
 	}
 
-	if endTime.Before(startTime) || endTime.Equal(startTime) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "End time must be after start time"})
+	if err := validateBookingDuration(startTime, endTime); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	maxDuration := 2 * time.Hour
-	if endTime.Sub(startTime) > maxDuration {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking duration cannot exceed 2 hours"})
+	if err := checkOperatingHours(facility, startTime, endTime); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	court, err := h.courtRepo.FindByCourtNumber(c.Request.Context(), req.CourtNumber)
+	court, err := h.courtRepo.FindByCourtNumber(c.Request.Context(), facility.ID, req.CourtNumber)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
 		return
@@ -104,7 +84,7 @@ func (h *Handler) CreateBooking(c *gin.Context) {
 		return
 	}
 
-	isAvailable, err := h.bookingRepo.IsCourtAvailable(c.Request.Context(), req.CourtNumber, bookingDate, startTime, endTime)
+	isAvailable, err := h.bookingRepo.IsCourtAvailable(c.Request.Context(), court.ID, bookingDate, startTime, endTime)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check court availability"})
 		return
@@ -115,10 +95,42 @@ func (h *Handler) CreateBooking(c *gin.Context) {
 		return
 	}
 
+	user, err := h.userRepo.FindByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.BookingBanUntil != nil && user.BookingBanUntil.After(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":    "You cannot make bookings for a while after repeated late cancellations",
+			"banUntil": user.BookingBanUntil,
+		})
+		return
+	}
+
+	if !h.checkBookingQuota(c, user) {
+		return
+	}
+
+	quote, err := h.quoteBooking(c.Request.Context(), facility, court, pricing.TierFor(user), startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to price booking"})
+		return
+	}
+
+	var promoCode *models.PromoCode
+	if req.PromoCode != "" {
+		if promoCode, ok = h.applyPromo(c, req.PromoCode, user, facility, startTime, quote); !ok {
+			return
+		}
+	}
+
 	booking := &models.Booking{
 		ID:               primitive.NewObjectID(),
 		UserID:           userID,
 		StudentID:        userClaims.StudentID,
+		FacilityID:       facility.ID,
 		CourtID:          court.ID,
 		CourtNumber:      req.CourtNumber,
 		BookingDate:      bookingDate,
@@ -129,21 +141,81 @@ func (h *Handler) CreateBooking(c *gin.Context) {
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 		UserEmail:        userClaims.Eamil,
+		CreatedBy:        userClaims.StudentID,
+		Channel:          models.ChannelSelfService,
+		Price:            quote.Total,
+		PriceBreakdown:   quote,
+	}
+
+	// The promo code use is claimed up front and given back if the booking
+	// cannot be made.
+	if promoCode != nil && !h.redeemPromo(c, promoCode, booking) {
+		return
+	}
+
+	// Free slots and wallet payments are confirmed right away; PromptPay
+	// bookings are held until paid.
+	var payment *models.Payment
+	if quote.Total > 0 && req.PaymentMethod == models.PaymentMethodPromptPay {
+		payment, err = h.startPayment(c.Request.Context(), booking)
+		if errors.Is(err, errPaymentsDisabled) {
+			h.releasePromo(c.Request.Context(), booking)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "PromptPay is not available, pay from your wallet instead"})
+			return
+		}
+		if err != nil {
+			h.releasePromo(c.Request.Context(), booking)
+			log.Printf("Error creating payment: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to start payment"})
+			return
+		}
 	}
 
-	if err := h.bookingRepo.Create(c.Request.Context(), booking); err != nil {
+	if quote.Total > 0 && req.PaymentMethod == models.PaymentMethodWallet {
+		err = h.payFromWallet(c.Request.Context(), booking)
+		if errors.Is(err, repository.ErrInsufficientFunds) {
+			h.releasePromo(c.Request.Context(), booking)
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "Insufficient wallet balance"})
+			return
+		}
+	} else {
+		err = h.bookingRepo.Create(c.Request.Context(), booking)
+	}
+	if err != nil {
+		h.releasePromo(c.Request.Context(), booking)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
 		return
 	}
 
+	if payment != nil {
+		if err := h.paymentRepo.Create(c.Request.Context(), payment); err != nil {
+			h.bookingRepo.CancelBooking(c.Request.Context(), booking.ID)
+			h.releasePromo(c.Request.Context(), booking)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start payment"})
+			return
+		}
+	}
+
+	audit(c, "booking.create", "booking", booking.ID.Hex())
+	auditDiff(c, nil, booking)
+
+	if booking.PaidAt != nil {
+		h.issueBookingDocuments(c.Request.Context(), booking)
+	}
+
 	response := models.BookingResponse{
-		ID:          booking.ID.Hex(),
-		CourtNumber: booking.CourtNumber,
-		BookingDate: req.BookingDate,
-		StartTime:   req.StartTime,
-		EndTime:     req.EndTime,
-		Status:      booking.Status,
-		CreatedAt:   booking.CreatedAt,
+		ID:             booking.ID.Hex(),
+		CourtNumber:    booking.CourtNumber,
+		BookingDate:    req.BookingDate,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		Status:         booking.Status,
+		CreatedAt:      booking.CreatedAt,
+		Price:          booking.Price,
+		PriceBreakdown: booking.PriceBreakdown,
+		HoldExpiresAt:  booking.HoldExpiresAt,
+		PaymentMethod:  booking.PaymentMethod,
+		Payment:        payment,
 	}
 
 	c.JSON(http.StatusCreated, response)
@@ -170,74 +242,345 @@ func (h *Handler) GetUserBookings(c *gin.Context) {
 		return
 	}
 
+	joined, err := h.bookingRepo.FindByParticipant(c.Request.Context(), userClaims.StudentID)
+	if err != nil {
+		log.Printf("Error fetching bookings as participant: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bookings"})
+		return
+	}
+	if len(joined) > 0 {
+		bookings = append(bookings, joined...)
+		sort.SliceStable(bookings, func(i, j int) bool {
+			if !bookings[i].BookingDate.Equal(bookings[j].BookingDate) {
+				return bookings[i].BookingDate.After(bookings[j].BookingDate)
+			}
+			return bookings[i].StartTime.After(bookings[j].StartTime)
+		})
+	}
+
+	paid := []primitive.ObjectID{}
+	for _, booking := range bookings {
+		if booking.PaidAt != nil && booking.StudentID == userClaims.StudentID && bookingPayerStudentID(booking) == userClaims.StudentID {
+			paid = append(paid, booking.ID)
+		}
+	}
+	invoices := map[primitive.ObjectID][]*models.Invoice{}
+	if len(paid) > 0 {
+		if invoices, err = h.invoiceRepo.FindForBookings(c.Request.Context(), paid); err != nil {
+			log.Printf("Error fetching invoices: %v", err)
+		}
+	}
+
 	var response []models.BookingResponse
 	for _, booking := range bookings {
+		role := "owner"
+		if booking.StudentID != userClaims.StudentID {
+			role = "participant"
+		}
 		response = append(response, models.BookingResponse{
-			ID:          booking.ID.Hex(),
-			CourtNumber: booking.CourtNumber,
-			BookingDate: booking.BookingDate.Format("2006-01-02"),
-			StartTime:   booking.StartTime.Format("15:04"),
-			EndTime:     booking.EndTime.Format("15:04"),
-			Status:      booking.Status,
-			CreatedAt:   booking.CreatedAt,
+			ID:             booking.ID.Hex(),
+			CourtNumber:    booking.CourtNumber,
+			BookingDate:    booking.BookingDate.Format("2006-01-02"),
+			StartTime:      booking.StartTime.Format("15:04"),
+			EndTime:        booking.EndTime.Format("15:04"),
+			Status:         booking.Status,
+			CreatedAt:      booking.CreatedAt,
+			Price:          booking.Price,
+			PriceBreakdown: booking.PriceBreakdown,
+			HoldExpiresAt:  booking.HoldExpiresAt,
+			PaymentMethod:  booking.PaymentMethod,
+			Invoices:       invoices[booking.ID],
+			Role:           role,
+			OwnerStudentID: booking.StudentID,
+			Participants:   booking.Participants,
+			OpenGame:       booking.OpenGame,
+			Transfer:       booking.Transfer,
+			Equipment:      booking.Equipment,
 		})
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// CancelBooking applies the cancellation policy. Cancellations that cost a
+// fee or earn a strike must be confirmed with ?confirm=true; otherwise the
+// preview is returned with a 409 so the user sees the outcome first.
 func (h *Handler) CancelBooking(c *gin.Context) {
-	claims, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	userClaims := claims.(*utils.Claims)
+	audit(c, "booking.cancel", "booking", c.Param("id"))
 
-	idStr := c.Param("id")
-	id, err := primitive.ObjectIDFromHex(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+	booking, userClaims, exempt, ok := h.findCancellableBooking(c)
+	if !ok {
 		return
 	}
 
-	booking, err := h.bookingRepo.FindByID(c.Request.Context(), id)
+	outcome, err := h.cancellationFor(c.Request.Context(), booking, exempt, time.Now())
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply cancellation policy"})
 		return
 	}
 
-	isOwner := booking.StudentID == userClaims.StudentID
-	isAdmin := userClaims.Role == "admin"
-	if !isOwner && !isAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to cancel this booking"})
+	preview := cancellationPreview(booking, outcome)
+	if preview.RequiresConfirmation && c.Query("confirm") != "true" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "This cancellation has a penalty and must be confirmed",
+			"preview": preview,
+		})
 		return
 	}
 
-	if booking.Status == "cancelled" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking is already cancelled"})
+	now := time.Now()
+	outcome.CancelledBy = userClaims.StudentID
+	outcome.CancelledAt = &now
+
+	err = h.cancelBooking(c.Request.Context(), booking, outcome)
+	if errors.Is(err, errBookingNotCancellable) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking can no longer be cancelled"})
 		return
 	}
-
-	if err := h.bookingRepo.CancelBooking(c.Request.Context(), id); err != nil {
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
 		return
 	}
 
+	if outcome.Strike {
+		h.recordStrike(c.Request.Context(), booking, outcome)
+	}
+
+	cancelled := *booking
+	cancelled.Status = "cancelled"
+	cancelled.Cancellation = outcome
+	auditDiff(c, booking, &cancelled)
+	if booking.StudentID != userClaims.StudentID {
+		auditMeta(c, "owner", booking.StudentID)
+	}
+	auditMeta(c, "window", outcome.Window)
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Booking cancelled successfully",
+		"message":      "Booking cancelled successfully",
+		"cancellation": outcome,
 	})
 }
 
 func (h *Handler) GetAllBookings(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Admin bookings endpoint"})
+	filter, err := parseBookingFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.scopeBookingFilter(c, &filter) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	bookings, total, err := h.bookingRepo.List(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bookings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.BookingListResponse{
+		Bookings: bookings,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	})
+}
+
+// parseBookingFilter reads the admin booking filters shared by the listing
+// and the exports from the query string.
+func parseBookingFilter(c *gin.Context) (repository.BookingFilter, error) {
+	req := models.BookingFilterRequest{
+		FacilityID: c.Query("facilityId"),
+		StudentID:  c.Query("studentId"),
+		Status:     c.Query("status"),
+		Channel:    c.Query("channel"),
+		From:       c.Query("from"),
+		To:         c.Query("to"),
+	}
+
+	if s := c.Query("courtNumber"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return repository.BookingFilter{}, errors.New("Invalid court number")
+		}
+		req.CourtNumber = n
+	}
+
+	return buildBookingFilter(req)
 }
 
+// buildBookingFilter validates a filter request. Dates are inclusive and
+// use YYYY-MM-DD.
+func buildBookingFilter(req models.BookingFilterRequest) (repository.BookingFilter, error) {
+	filter := repository.BookingFilter{
+		StudentID: req.StudentID,
+		Status:    req.Status,
+		Channel:   req.Channel,
+	}
+
+	if req.FacilityID != "" {
+		id, err := primitive.ObjectIDFromHex(req.FacilityID)
+		if err != nil {
+			return filter, errors.New("Invalid facility ID")
+		}
+		filter.FacilityIDs = []primitive.ObjectID{id}
+	}
+
+	if req.CourtNumber < 0 {
+		return filter, errors.New("Invalid court number")
+	}
+	filter.CourtNumber = req.CourtNumber
+
+	if req.From != "" {
+		d, err := time.Parse("2006-01-02", req.From)
+		if err != nil {
+			return filter, errors.New("Invalid from date, use YYYY-MM-DD")
+		}
+		filter.From = &d
+	}
+	if req.To != "" {
+		d, err := time.Parse("2006-01-02", req.To)
+		if err != nil {
+			return filter, errors.New("Invalid to date, use YYYY-MM-DD")
+		}
+		filter.To = &d
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return filter, errors.New("The to date must not be before the from date")
+	}
+
+	return filter, nil
+}
+
+// CheckAvailability reports availability for the signed-in user, quoted at
+// their own pricing tier, for one court or every court of a facility.
 func (h *Handler) CheckAvailability(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Check availability endpoint"})
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	var req models.AvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	bookingDate, startTime, endTime, err := parseBookingSlot(req.BookingDate, req.StartTime, req.EndTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	facility, ok := h.resolveFacility(c, req.FacilityID)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, err := primitive.ObjectIDFromHex(userClaims.Subject)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	availabilities, err := h.bookingRepo.GetAvailableCourts(ctx, facility.ID, bookingDate, startTime, endTime, h.courtRepo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check court availability"})
+		return
+	}
+
+	if req.CourtNumber != 0 {
+		var matched []*models.CourtAvailability
+		for _, court := range availabilities {
+			if court.CourtNumber == req.CourtNumber {
+				matched = append(matched, court)
+			}
+		}
+		if len(matched) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
+			return
+		}
+		availabilities = matched
+	}
+
+	if err := h.quoteCourts(ctx, facility, availabilities, pricing.TierFor(user), startTime, endTime); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to price courts"})
+		return
+	}
+
+	// A promo code is previewed against each court's quote but not claimed.
+	if req.PromoCode != "" {
+		for _, court := range availabilities {
+			if court.Quote == nil {
+				continue
+			}
+			if _, ok := h.applyPromo(c, req.PromoCode, user, facility, startTime, court.Quote); !ok {
+				return
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, models.AvailabilityResponse{
+		FacilityID:  facility.ID,
+		BookingDate: req.BookingDate,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		Courts:      availabilities,
+	})
 }
 
-func SendMail(bookingRepo *repository.BookingRepository, userRepo *repository.UserRepository) {
+// parseBookingSlot reads a YYYY-MM-DD date and HH:MM start and end times
+// into the UTC wall-clock times bookings are stored with.
+func parseBookingSlot(date, start, end string) (time.Time, time.Time, time.Time, error) {
+	bookingDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, errors.New("Invalid date format, use YYYY-MM-DD")
+	}
+
+	layout := "15:04"
+	startTimeParsed, err := time.Parse(layout, start)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, errors.New("Invalid start time format, use HH:MM")
+	}
+
+	endTimeParsed, err := time.Parse(layout, end)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, errors.New("Invalid end time format, use HH:MM")
+	}
+
+	startTime := time.Date(bookingDate.Year(), bookingDate.Month(), bookingDate.Day(),
+		startTimeParsed.Hour(), startTimeParsed.Minute(), 0, 0, bookingDate.Location())
+	endTime := time.Date(bookingDate.Year(), bookingDate.Month(), bookingDate.Day(),
+		endTimeParsed.Hour(), endTimeParsed.Minute(), 0, 0, bookingDate.Location())
+
+	return bookingDate, startTime, endTime, nil
+}
+
+func validateBookingDuration(startTime, endTime time.Time) error {
+	if !endTime.After(startTime) {
+		return errors.New("End time must be after start time")
+	}
+
+	maxDuration := 2 * time.Hour
+	if endTime.Sub(startTime) > maxDuration {
+		return errors.New("Booking duration cannot exceed 2 hours")
+	}
+
+	return nil
+}
+
+func SendMail(bookingRepo *repository.BookingRepository, userRepo *repository.UserRepository, notificationRepo *repository.NotificationRepository) {
 	log.Println("Starting SendMail function...")
 
 	bookings, err := bookingRepo.FindUpcomingBookings(context.Background(), time.Now().Add(1*time.Minute))
@@ -248,13 +591,6 @@ func SendMail(bookingRepo *repository.BookingRepository, userRepo *repository.Us
 
 	log.Printf("Found %d upcoming bookings", len(bookings))
 
-	auth := smtp.PlainAuth(
-		"",
-		"natthawat48.noi@gmail.com",
-		"mjxn rpse favy jidl",      
-		"smtp.gmail.com",            
-	)
-
 	for _, booking := range bookings {
 		log.Printf("Processing booking ID: %s", booking.ID.Hex())
 
@@ -266,23 +602,15 @@ func SendMail(bookingRepo *repository.BookingRepository, userRepo *repository.Us
 
 		log.Printf("Sending email to: %s", user.Email)
 
-		msg := []byte(fmt.Sprintf(
-			"To: %s\r\nSubject: Upcoming Booking Reminder\r\n\r\nDear user,\n\nThis is a reminder for your upcoming booking:\n\nCourt Number: %d\nDate: %s\nTime: %s - %s\n\nThank you for using Courtminton!",
-			user.Email,
+		body := fmt.Sprintf(
+			"Dear user,\n\nThis is a reminder for your upcoming booking:\n\nCourt Number: %d\nDate: %s\nTime: %s - %s\n\nThank you for using Courtminton!",
 			booking.CourtNumber,
 			booking.BookingDate.Format("2006-01-02"),
 			booking.StartTime.Format("15:04"),
 			booking.EndTime.Format("15:04"),
-		))
-
-		err = smtp.SendMail(
-			"smtp.gmail.com:587",
-			auth,
-			"natthawat48.noi@gmail.com",
-			[]string{user.Email},
-			msg,
 		)
 
+		err = deliverEmail(context.Background(), notificationRepo, user, "booking_reminder", "Upcoming Booking Reminder", body, &booking.ID)
 		if err != nil {
 			log.Printf("Error sending email to %s: %v", user.Email, err)
 			continue
@@ -302,7 +630,7 @@ func SendMail(bookingRepo *repository.BookingRepository, userRepo *repository.Us
 }
 
 func (h *Handler) TriggerEmailNotifications(c *gin.Context) {
-	SendMail(h.bookingRepo, h.userRepo)
+	SendMail(h.bookingRepo, h.userRepo, h.notificationRepo)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Email notifications triggered"})
 }
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// maxBulkBookings caps how many bookings a single bulk operation may touch
+// so a loose filter cannot rewrite the whole collection.
+const maxBulkBookings = 1000
+
+type bulkAction struct {
+	status   string
+	expected []string
+	subject  string
+	verb     string
+}
+
+var bulkActions = map[string]bulkAction{
+	"cancel": {
+		status:   "cancelled",
+		expected: []string{"active"},
+		subject:  "Your court booking has been cancelled",
+		verb:     "has been cancelled",
+	},
+	"move": {
+		expected: []string{"active"},
+		subject:  "Your court booking has changed",
+	},
+	"complete": {
+		status:   "completed",
+		expected: []string{"active"},
+	},
+	"no_show": {
+		status:   "no_show",
+		expected: []string{"active", "completed"},
+		subject:  "You were marked as a no-show",
+		verb:     "has been marked as a no-show",
+	},
+}
+
+func (h *Handler) BulkUpdateBookings(c *gin.Context) {
+	var req models.BulkBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	audit(c, "booking.bulk_"+req.Action, "booking", "")
+	auditMeta(c, "dryRun", strconv.FormatBool(req.DryRun))
+	if req.Reason != "" {
+		auditMeta(c, "reason", req.Reason)
+	}
+
+	action, ok := bulkActions[req.Action]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Action must be cancel, move, complete or no_show"})
+		return
+	}
+
+	if req.Filter.From == "" || req.Filter.To == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A from and to date are required for bulk operations"})
+		return
+	}
+	filter, err := buildBookingFilter(req.Filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.scopeBookingFilter(c, &filter) {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var target *models.Court
+	if req.Action == "move" {
+		if req.TargetCourtNumber < 1 || req.TargetCourtNumber == req.Filter.CourtNumber {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A different targetCourtNumber is required to move bookings"})
+			return
+		}
+
+		facility, ok := h.resolveFacility(c, req.Filter.FacilityID)
+		if !ok {
+			return
+		}
+
+		target, err = h.courtRepo.FindByCourtNumber(ctx, facility.ID, req.TargetCourtNumber)
+		if err != nil || !target.IsActive {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Target court is not available"})
+			return
+		}
+		if !h.requireFacilityAccess(c, target.FacilityID) {
+			return
+		}
+	}
+
+	bookings, err := h.bookingRepo.FindMatching(ctx, filter, maxBulkBookings+1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bookings"})
+		return
+	}
+	if len(bookings) > maxBulkBookings {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Filter matches more than %d bookings; narrow it down", maxBulkBookings)})
+		return
+	}
+
+	result := &models.BulkBookingResponse{
+		Action:   req.Action,
+		DryRun:   req.DryRun,
+		Matched:  len(bookings),
+		Affected: []*models.Booking{},
+		Skipped:  []*models.BulkBookingSkip{},
+	}
+
+	now := time.Now()
+	var claimed []*models.Booking
+	for _, booking := range bookings {
+		reason := bulkSkipReason(booking, req.Action, action, now)
+		if reason == "" && target != nil {
+			reason, err = h.moveConflict(ctx, booking, target, claimed)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check court availability"})
+				return
+			}
+		}
+
+		if reason != "" {
+			result.Skipped = append(result.Skipped, &models.BulkBookingSkip{BookingID: booking.ID, Reason: reason})
+			continue
+		}
+
+		if target != nil {
+			claimed = append(claimed, booking)
+		}
+		result.Affected = append(result.Affected, booking)
+	}
+
+	auditMeta(c, "matched", strconv.Itoa(result.Matched))
+	auditMeta(c, "affected", strconv.Itoa(len(result.Affected)))
+
+	if req.DryRun || len(result.Affected) == 0 {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	if target != nil {
+		for _, booking := range result.Affected {
+			if err := h.bookingRepo.MoveToCourt(ctx, booking.ID, target); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move booking " + booking.ID.Hex()})
+				return
+			}
+		}
+	} else {
+		ids := make([]primitive.ObjectID, 0, len(result.Affected))
+		for _, booking := range result.Affected {
+			// Paid bookings are cancelled one by one so their refunds are
+			// settled with them.
+			if req.Action == "cancel" && booking.PaymentMethod != "" {
+				err := h.cancelBooking(ctx, booking, exemptCancellation(booking, c.MustGet("user").(*utils.Claims).StudentID))
+				if err != nil && !errors.Is(err, errBookingNotCancellable) {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking " + booking.ID.Hex()})
+					return
+				}
+				continue
+			}
+			ids = append(ids, booking.ID)
+		}
+		if _, err := h.bookingRepo.SetStatus(ctx, ids, action.expected, action.status); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bookings"})
+			return
+		}
+	}
+
+	ids := make([]string, len(result.Affected))
+	for i, booking := range result.Affected {
+		ids[i] = booking.ID.Hex()
+	}
+	auditMeta(c, "bookingIds", strings.Join(ids, ","))
+
+	if action.subject != "" {
+		for _, booking := range result.Affected {
+			h.notifyBulkChange(ctx, booking, req.Action, action, target, req.Reason)
+		}
+	}
+
+	for _, booking := range result.Affected {
+		if target != nil {
+			booking.FacilityID = target.FacilityID
+			booking.CourtID = target.ID
+			booking.CourtNumber = target.CourtNumber
+		} else {
+			booking.Status = action.status
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// bulkSkipReason explains why a matched booking is left alone by the
+// action, or returns an empty string when it applies.
+func bulkSkipReason(booking *models.Booking, name string, action bulkAction, now time.Time) string {
+	allowed := false
+	for _, status := range action.expected {
+		if booking.Status == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Sprintf("Booking is %s", booking.Status)
+	}
+
+	switch name {
+	case "cancel", "move":
+		if !booking.EndTime.After(now) {
+			return "Booking has already ended"
+		}
+	case "complete", "no_show":
+		if booking.StartTime.After(now) {
+			return "Booking has not started yet"
+		}
+	}
+
+	return ""
+}
+
+// moveConflict checks the target court against stored bookings and the
+// bookings already moved in this batch.
+func (h *Handler) moveConflict(ctx context.Context, booking *models.Booking, target *models.Court, claimed []*models.Booking) (string, error) {
+	if booking.CourtID == target.ID {
+		return "Booking is already on the target court", nil
+	}
+	if booking.FacilityID != target.FacilityID {
+		return "Booking is in a different facility", nil
+	}
+
+	free, err := h.bookingRepo.IsCourtAvailable(ctx, target.ID, booking.BookingDate, booking.StartTime, booking.EndTime)
+	if err != nil {
+		return "", err
+	}
+	if !free {
+		return "Target court is already booked at this time", nil
+	}
+
+	for _, other := range claimed {
+		if other.StartTime.Before(booking.EndTime) && booking.StartTime.Before(other.EndTime) {
+			return "Overlaps another booking moved to the target court", nil
+		}
+	}
+
+	return "", nil
+}
+
+func (h *Handler) notifyBulkChange(ctx context.Context, booking *models.Booking, name string, action bulkAction, target *models.Court, reason string) {
+	when := fmt.Sprintf("%s at %s - %s",
+		booking.BookingDate.Format("2006-01-02"), booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04"))
+
+	var body string
+	if target != nil {
+		body = fmt.Sprintf("Your booking on %s has been moved from court %d to court %d.", when, booking.CourtNumber, target.CourtNumber)
+	} else {
+		body = fmt.Sprintf("Your booking for court %d on %s %s.", booking.CourtNumber, when, action.verb)
+	}
+	if reason != "" {
+		body += "\n\nReason: " + reason
+	}
+
+	h.notifyStudent(ctx, booking.StudentID, "bulk_"+name, action.subject, body, &booking.ID)
+}
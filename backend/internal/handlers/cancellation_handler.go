@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/cancellation"
+	"courtopia-reserve/backend/internal/ledger"
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// maxStoredStrikes bounds the strike history kept on a user.
+const maxStoredStrikes = 20
+
+var errBookingNotCancellable = errors.New("booking can no longer be cancelled")
+
+// PreviewCancellation shows what cancelling a booking would refund and
+// whether it would earn a strike, so the user can decide before confirming.
+func (h *Handler) PreviewCancellation(c *gin.Context) {
+	booking, _, exempt, ok := h.findCancellableBooking(c)
+	if !ok {
+		return
+	}
+
+	outcome, err := h.cancellationFor(c.Request.Context(), booking, exempt, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply cancellation policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cancellationPreview(booking, outcome))
+}
+
+// ClearCancelStrikes forgives a user's late-cancellation strikes and lifts
+// any booking ban.
+func (h *Handler) ClearCancelStrikes(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "user.clear_cancel_strikes", "user", user.ID.Hex())
+
+	update := bson.M{
+		"$unset": bson.M{"cancel_strikes": "", "booking_ban_until": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+	}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear strikes"})
+		return
+	}
+
+	updated := *user
+	updated.CancelStrikes = nil
+	updated.BookingBanUntil = nil
+	auditDiff(c, user, &updated)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cancellation strikes cleared"})
+}
+
+// findCancellableBooking loads the booking in the id parameter and checks
+// that the caller may cancel it. Staff cancelling a booking in a facility
+// they manage, other than their own, are exempt from the policy. Admin
+// powers need an interactive session that meets the admin 2FA requirement,
+// so an admin's API token only cancels the admin's own bookings.
+func (h *Handler) findCancellableBooking(c *gin.Context) (*models.Booking, *utils.Claims, bool, bool) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return nil, nil, false, false
+	}
+
+	booking, err := h.bookingRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return nil, nil, false, false
+	}
+
+	isOwner := booking.StudentID == userClaims.StudentID
+	isAdmin := false
+	if isAdminRole(userClaims.Role) && !userClaims.IsAPIToken() && (!h.cfg.AdminRequire2FA || userClaims.MFA) {
+		isAdmin, err = h.canManageFacility(c, booking.FacilityID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check facility permissions"})
+			return nil, nil, false, false
+		}
+	}
+	if !isOwner && !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to cancel this booking"})
+		return nil, nil, false, false
+	}
+
+	switch booking.Status {
+	case "cancelled":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking is already cancelled"})
+		return nil, nil, false, false
+	case "expired":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking hold has already expired"})
+		return nil, nil, false, false
+	case "active", "pending_payment":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Booking is %s and cannot be cancelled", booking.Status)})
+		return nil, nil, false, false
+	}
+
+	return booking, userClaims, isAdmin && !isOwner, true
+}
+
+// cancellationFor applies the cancellation policy to cancelling the booking
+// at now.
+func (h *Handler) cancellationFor(ctx context.Context, booking *models.Booking, exempt bool, now time.Time) (*models.CancellationOutcome, error) {
+	if exempt {
+		return cancellation.Exempt(booking), nil
+	}
+
+	facility, err := h.facilityRepo.FindByID(ctx, booking.FacilityID)
+	if err != nil {
+		return nil, err
+	}
+
+	var strikes []time.Time
+	if owner, err := h.userRepo.FindByID(ctx, booking.UserID); err == nil {
+		strikes = owner.CancelStrikes
+	}
+
+	return h.cancellationPolicy().Evaluate(booking, facilityInstant(facility, booking.StartTime), strikes, now), nil
+}
+
+func (h *Handler) cancellationPolicy() cancellation.Policy {
+	return cancellation.Policy{
+		FreeWindow:       h.cfg.CancelFreeWindow,
+		LateWindow:       h.cfg.CancelLateWindow,
+		PartialRefundPct: h.cfg.CancelPartialRefundPct,
+		StrikeLimit:      h.cfg.LateCancelStrikeLimit,
+		StrikeWindow:     h.cfg.LateCancelStrikeWindow,
+		BanDuration:      h.cfg.BookingBanDuration,
+	}
+}
+
+// exemptCancellation is the outcome recorded when staff cancel a booking
+// outside the policy, for example when retiring a court.
+func exemptCancellation(booking *models.Booking, actor string) *models.CancellationOutcome {
+	now := time.Now()
+	outcome := cancellation.Exempt(booking)
+	outcome.CancelledBy = actor
+	outcome.CancelledAt = &now
+	return outcome
+}
+
+func cancellationPreview(booking *models.Booking, outcome *models.CancellationOutcome) *models.CancellationPreview {
+	return &models.CancellationPreview{
+		BookingID:            booking.ID,
+		Price:                booking.Price,
+		Cancellation:         outcome,
+		RequiresConfirmation: outcome.Fee > 0 || outcome.Strike,
+	}
+}
+
+// cancelBooking cancels a booking that still holds its slot, records the
+// policy outcome and settles the payment. Wallet refunds are credited in
+// the same transaction as the cancellation; refunds of PromptPay payments
+// are flagged for staff to pay out.
+func (h *Handler) cancelBooking(ctx context.Context, booking *models.Booking, outcome *models.CancellationOutcome) error {
+	if booking.PaymentMethod == models.PaymentMethodWallet && outcome.RefundAmount > 0 {
+		err := repository.RunInTransaction(ctx, h.db, func(ctx context.Context) error {
+			cancelled, err := h.bookingRepo.CancelWithOutcome(ctx, booking.ID, outcome)
+			if err != nil {
+				return err
+			}
+			if !cancelled {
+				return errBookingNotCancellable
+			}
+
+			txn := ledger.RefundCredit(booking, outcome.RefundAmount)
+			txn.CreatedBy = outcome.CancelledBy
+			return h.walletRepo.Post(ctx, txn)
+		})
+		if err == nil {
+			h.releasePromo(ctx, booking)
+			h.notifyParticipants(ctx, booking)
+		}
+		return err
+	}
+
+	cancelled, err := h.bookingRepo.CancelWithOutcome(ctx, booking.ID, outcome)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return errBookingNotCancellable
+	}
+
+	h.releasePromo(ctx, booking)
+	h.notifyParticipants(ctx, booking)
+
+	if booking.PaymentID != nil {
+		if _, err := h.paymentRepo.SetStatus(ctx, *booking.PaymentID, models.PaymentCancelled, models.PaymentPending); err != nil {
+			log.Printf("Error cancelling payment for booking %s: %v", booking.ID.Hex(), err)
+		}
+		if outcome.RefundAmount > 0 {
+			if _, err := h.paymentRepo.FlagRefund(ctx, *booking.PaymentID, outcome.RefundAmount, models.PaymentPaid); err != nil {
+				log.Printf("Error flagging payment for booking %s: %v", booking.ID.Hex(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordStrike adds a late-cancellation strike to the booking's owner.
+// Reaching the limit bans them from booking and starts a fresh count.
+func (h *Handler) recordStrike(ctx context.Context, booking *models.Booking, outcome *models.CancellationOutcome) {
+	now := time.Now()
+
+	var update bson.M
+	if outcome.BanUntil != nil {
+		update = bson.M{
+			"$set":   bson.M{"booking_ban_until": outcome.BanUntil, "updated_at": now},
+			"$unset": bson.M{"cancel_strikes": ""},
+		}
+	} else {
+		update = bson.M{
+			"$push": bson.M{"cancel_strikes": bson.M{"$each": []time.Time{now}, "$slice": -maxStoredStrikes}},
+			"$set":  bson.M{"updated_at": now},
+		}
+	}
+
+	if err := h.userRepo.UpdateOne(ctx, bson.M{"_id": booking.UserID}, update); err != nil {
+		log.Printf("Error recording cancellation strike for %s: %v", booking.StudentID, err)
+		return
+	}
+
+	if outcome.BanUntil != nil {
+		body := fmt.Sprintf("You have cancelled %d bookings at short notice, so you cannot make new bookings until %s.",
+			outcome.Strikes, outcome.BanUntil.Format("2006-01-02 15:04"))
+		h.notifyStudent(ctx, booking.StudentID, "booking_ban", "Your booking privileges are paused", body, &booking.ID)
+	}
+}
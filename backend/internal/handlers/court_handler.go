@@ -1,17 +1,37 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 
 	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
 )
 
 func (h *Handler) GetCourts(c *gin.Context) {
-	courts, err := h.courtRepo.FindAll(c.Request.Context())
+	var courts []*models.Court
+	var err error
+	if s := c.Query("facilityId"); s != "" {
+		facilityID, parseErr := primitive.ObjectIDFromHex(s)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid facility ID"})
+			return
+		}
+		courts, err = h.courtRepo.FindByFacility(c.Request.Context(), facilityID)
+	} else {
+		courts, err = h.courtRepo.FindAll(c.Request.Context())
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch courts"})
 		return
@@ -28,13 +48,20 @@ func (h *Handler) GetCourt(c *gin.Context) {
 		return
 	}
 
+	// Retired courts are kept for booking history but are not public.
 	court, err := h.courtRepo.FindByID(c.Request.Context(), id)
-	if err != nil {
+	if err != nil || court.IsRetired {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, court)
+	notices, err := h.announcementRepo.FindActive(c.Request.Context(), time.Now(), &court.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch court notices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CourtDetailResponse{Court: court, Notices: notices})
 }
 
 func (h *Handler) GetAvailableCourts(c *gin.Context) {
@@ -47,49 +74,26 @@ func (h *Handler) GetAvailableCourts(c *gin.Context) {
 		return
 	}
 
-	bookingDate, err := time.Parse("2006-01-02", dateStr)
+	bookingDate, startTime, endTime, err := parseBookingSlot(dateStr, startTimeStr, endTimeStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	layout := "15:04"
-	startTimeParsed, err := time.Parse(layout, startTimeStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start time format, use HH:MM"})
+	tier := c.DefaultQuery("tier", pricing.TierMember)
+	if !pricing.Tiers[tier] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tier must be member or non_member"})
 		return
 	}
 
-	endTimeParsed, err := time.Parse(layout, endTimeStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end time format, use HH:MM"})
+	facility, ok := h.resolveFacility(c, c.Query("facilityId"))
+	if !ok {
 		return
 	}
 
-	startTime := time.Date(
-		bookingDate.Year(),
-		bookingDate.Month(),
-		bookingDate.Day(),
-		startTimeParsed.Hour(),
-		startTimeParsed.Minute(),
-		0,
-		0,
-		bookingDate.Location(),
-	)
-
-	endTime := time.Date(
-		bookingDate.Year(),
-		bookingDate.Month(),
-		bookingDate.Day(),
-		endTimeParsed.Hour(),
-		endTimeParsed.Minute(),
-		0,
-		0,
-		bookingDate.Location(),
-	)
-
 	availabilities, err := h.bookingRepo.GetAvailableCourts(
 		c.Request.Context(),
+		facility.ID,
 		bookingDate,
 		startTime,
 		endTime,
@@ -100,7 +104,13 @@ func (h *Handler) GetAvailableCourts(c *gin.Context) {
 		return
 	}
 
+	if err := h.quoteCourts(c.Request.Context(), facility, availabilities, tier, startTime, endTime); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to price courts"})
+		return
+	}
+
 	response := models.AvailabilityResponse{
+		FacilityID:  facility.ID,
 		BookingDate: dateStr,
 		StartTime:   startTimeStr,
 		EndTime:     endTimeStr,
@@ -119,6 +129,8 @@ func (h *Handler) UpdateCourtStatus(c *gin.Context) {
 		return
 	}
 
+	audit(c, "court.status", "court", idStr)
+
 	var req struct {
 		IsActive bool `json:"isActive"`
 	}
@@ -128,10 +140,303 @@ func (h *Handler) UpdateCourtStatus(c *gin.Context) {
 		return
 	}
 
+	court, err := h.courtRepo.FindByID(c.Request.Context(), id)
+	if err != nil || court.IsRetired {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
+		return
+	}
+	if !h.requireFacilityAccess(c, court.FacilityID) {
+		return
+	}
+
 	if err := h.courtRepo.UpdateStatus(c.Request.Context(), id, req.IsActive); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update court status"})
 		return
 	}
 
+	updated := *court
+	updated.IsActive = req.IsActive
+	auditDiff(c, court, &updated)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Court status updated successfully"})
 }
+
+func (h *Handler) CreateCourt(c *gin.Context) {
+	var req models.CreateCourtRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	facility, ok := h.resolveFacility(c, req.FacilityID)
+	if !ok || !h.requireFacilityAccess(c, facility.ID) {
+		return
+	}
+
+	if _, err := h.courtRepo.FindByCourtNumber(c.Request.Context(), facility.ID, req.CourtNumber); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Court number is already in use"})
+		return
+	} else if err != mongo.ErrNoDocuments {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	court := &models.Court{
+		ID:          primitive.NewObjectID(),
+		FacilityID:  facility.ID,
+		CourtNumber: req.CourtNumber,
+		Name:        req.Name,
+		Location:    req.Location,
+		Attributes:  req.Attributes,
+		Capacity:    req.Capacity,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := h.courtRepo.Create(c.Request.Context(), court); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Court number is already in use"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create court"})
+		return
+	}
+
+	audit(c, "court.create", "court", court.ID.Hex())
+	auditDiff(c, nil, court)
+
+	c.JSON(http.StatusCreated, court)
+}
+
+func (h *Handler) UpdateCourt(c *gin.Context) {
+	audit(c, "court.update", "court", c.Param("id"))
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court ID"})
+		return
+	}
+
+	var req models.UpdateCourtRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	court, err := h.courtRepo.FindByID(ctx, id)
+	if err != nil || court.IsRetired {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
+		return
+	}
+	if !h.requireFacilityAccess(c, court.FacilityID) {
+		return
+	}
+
+	before := *court
+
+	renumbered := req.CourtNumber != nil && *req.CourtNumber != court.CourtNumber
+	if renumbered {
+		if _, err := h.courtRepo.FindByCourtNumber(ctx, court.FacilityID, *req.CourtNumber); err == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Court number is already in use"})
+			return
+		} else if err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		court.CourtNumber = *req.CourtNumber
+	}
+	if req.Name != nil {
+		court.Name = *req.Name
+	}
+	if req.Location != nil {
+		court.Location = *req.Location
+	}
+	if req.Attributes != nil {
+		court.Attributes = req.Attributes
+	}
+	if req.Capacity != nil {
+		court.Capacity = *req.Capacity
+	}
+
+	if err := h.courtRepo.Update(ctx, court); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Court number is already in use"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update court"})
+		return
+	}
+	auditDiff(c, &before, court)
+
+	if renumbered {
+		if err := h.bookingRepo.UpdateCourtNumber(ctx, court.ID, court.CourtNumber); err != nil {
+			log.Printf("Error renumbering bookings for court %s: %v", court.ID.Hex(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Court updated but bookings could not be renumbered"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, court)
+}
+
+var errCourtMigrationConflict = errors.New("bookings conflict on the target court")
+
+// RetireCourt takes a court out of service. Courts with upcoming bookings
+// can only be retired when the admin chooses to cancel those bookings or to
+// migrate them to another court.
+func (h *Handler) RetireCourt(c *gin.Context) {
+	audit(c, "court.retire", "court", c.Param("id"))
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	actor := c.MustGet("user").(*utils.Claims).StudentID
+	court, err := h.courtRepo.FindByID(ctx, id)
+	if err != nil || court.IsRetired {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
+		return
+	}
+	if !h.requireFacilityAccess(c, court.FacilityID) {
+		return
+	}
+
+	facility, err := h.facilityRepo.FindByID(ctx, court.FacilityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch facility"})
+		return
+	}
+	now := facilityWallClock(facility, time.Now())
+
+	bookings, err := h.bookingRepo.FindFutureByCourtID(ctx, court.ID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch court bookings"})
+		return
+	}
+
+	action := c.Query("futureBookings")
+	if len(bookings) > 0 && action != "cancel" && action != "migrate" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          "Court has upcoming bookings; set futureBookings to cancel or migrate",
+			"futureBookings": len(bookings),
+		})
+		return
+	}
+
+	var target *models.Court
+	if len(bookings) > 0 && action == "migrate" {
+		targetID, err := primitive.ObjectIDFromHex(c.Query("targetCourtId"))
+		if err != nil || targetID == court.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A different targetCourtId is required to migrate bookings"})
+			return
+		}
+
+		target, err = h.courtRepo.FindByID(ctx, targetID)
+		if err != nil || target.IsRetired || !target.IsActive {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Target court is not available"})
+			return
+		}
+		if target.FacilityID != court.FacilityID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Target court must be in the same facility"})
+			return
+		}
+	}
+
+	if target != nil {
+		// The availability check and the moves commit together. Touching
+		// both courts makes a concurrent retirement or migration involving
+		// either of them conflict instead of double-booking the target.
+		var conflicts []string
+		err := repository.RunInTransaction(ctx, h.db, func(ctx context.Context) error {
+			conflicts = nil
+			if err := h.courtRepo.Touch(ctx, court.ID); err != nil {
+				return err
+			}
+			if err := h.courtRepo.Touch(ctx, target.ID); err != nil {
+				return err
+			}
+
+			current, err := h.bookingRepo.FindFutureByCourtID(ctx, court.ID, now)
+			if err != nil {
+				return err
+			}
+			for _, booking := range current {
+				free, err := h.bookingRepo.IsCourtAvailable(ctx, target.ID, booking.BookingDate, booking.StartTime, booking.EndTime)
+				if err != nil {
+					return err
+				}
+				if !free {
+					conflicts = append(conflicts, booking.ID.Hex())
+				}
+			}
+			if len(conflicts) > 0 {
+				return errCourtMigrationConflict
+			}
+
+			for _, booking := range current {
+				if err := h.bookingRepo.MoveToCourt(ctx, booking.ID, target); err != nil {
+					return err
+				}
+			}
+			bookings = current
+			return h.courtRepo.Retire(ctx, court.ID)
+		})
+		if errors.Is(err, errCourtMigrationConflict) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":       "Some bookings cannot be moved to the target court",
+				"conflicting": conflicts,
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to migrate bookings"})
+			return
+		}
+	} else {
+		// Retiring first stops new bookings, so none slip in between
+		// listing the bookings and cancelling them.
+		if err := h.courtRepo.Retire(ctx, court.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retire court"})
+			return
+		}
+		if bookings, err = h.bookingRepo.FindFutureByCourtID(ctx, court.ID, now); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch court bookings"})
+			return
+		}
+	}
+
+	for _, booking := range bookings {
+		subject := "Your court booking has changed"
+		var body string
+		if target != nil {
+			body = fmt.Sprintf("Court %d is being retired, so your booking on %s at %s - %s has been moved to court %d.",
+				court.CourtNumber, booking.BookingDate.Format("2006-01-02"), booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04"), target.CourtNumber)
+		} else {
+			if err := h.cancelBooking(ctx, booking, exemptCancellation(booking, actor)); err != nil && !errors.Is(err, errBookingNotCancellable) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking " + booking.ID.Hex()})
+				return
+			}
+			subject = "Your court booking has been cancelled"
+			body = fmt.Sprintf("Court %d is being retired, so your booking on %s at %s - %s has been cancelled.",
+				court.CourtNumber, booking.BookingDate.Format("2006-01-02"), booking.StartTime.Format("15:04"), booking.EndTime.Format("15:04"))
+		}
+		h.notifyStudent(ctx, booking.StudentID, "court_retired", subject, body, &booking.ID)
+	}
+
+	auditMeta(c, "affectedBookings", strconv.Itoa(len(bookings)))
+	if action != "" {
+		auditMeta(c, "futureBookings", action)
+	}
+	if target != nil {
+		auditMeta(c, "targetCourtNumber", strconv.Itoa(target.CourtNumber))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Court retired successfully",
+		"affectedBookings": len(bookings),
+		"action":           action,
+	})
+}
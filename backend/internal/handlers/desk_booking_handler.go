@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// StaffMiddleware admits front-desk staff and admins. Admins are held to
+// the same 2FA requirement as on the admin routes.
+func (h *Handler) StaffMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("user").(*utils.Claims)
+		if claims.Role != "staff" && claims.Role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Staff access required"})
+			c.Abort()
+			return
+		}
+
+		if claims.Role == "admin" && h.cfg.AdminRequire2FA && !claims.MFA {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for admin access"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CreateDeskBooking books a court at the front desk for a registered
+// student or a walk-in guest. Unlike self-service bookings the slot may
+// already have started, but it must not overlap another booking.
+func (h *Handler) CreateDeskBooking(c *gin.Context) {
+	claims := c.MustGet("user").(*utils.Claims)
+
+	var req models.DeskBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	req.StudentID = strings.TrimSpace(req.StudentID)
+	req.GuestName = strings.TrimSpace(req.GuestName)
+	if (req.StudentID == "") == (req.GuestName == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either a studentId or a guestName"})
+		return
+	}
+
+	bookingDate, startTime, endTime, err := parseBookingSlot(req.BookingDate, req.StartTime, req.EndTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	facility, ok := h.resolveFacility(c, req.FacilityID)
+	if !ok {
+		return
+	}
+
+	if !facilityInstant(facility, endTime).After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking has already ended"})
+		return
+	}
+
+	if err := validateBookingDuration(startTime, endTime); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := checkOperatingHours(facility, startTime, endTime); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tier := pricing.TierNonMember
+	booking := &models.Booking{
+		ID:          primitive.NewObjectID(),
+		FacilityID:  facility.ID,
+		BookingDate: bookingDate,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		CreatedBy:   claims.StudentID,
+		Channel:     models.ChannelDesk,
+	}
+
+	if req.StudentID != "" {
+		user, err := h.userRepo.FindByStudentID(ctx, req.StudentID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if user.Suspended {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+			return
+		}
+
+		booking.UserID = user.ID
+		booking.StudentID = user.StudentID
+		booking.UserEmail = user.Email
+		tier = pricing.TierFor(user)
+	} else {
+		// Guests have no account to send a reminder to.
+		booking.GuestName = req.GuestName
+		booking.NotificationSent = true
+	}
+
+	court, err := h.courtRepo.FindByCourtNumber(ctx, facility.ID, req.CourtNumber)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
+		return
+	}
+
+	if !court.IsActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Court is not available for booking"})
+		return
+	}
+
+	isAvailable, err := h.bookingRepo.IsCourtAvailable(ctx, court.ID, bookingDate, startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check court availability"})
+		return
+	}
+
+	if !isAvailable {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Court is not available for the selected time"})
+		return
+	}
+
+	quote, err := h.quoteBooking(ctx, facility, court, tier, startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to price booking"})
+		return
+	}
+
+	booking.CourtID = court.ID
+	booking.CourtNumber = court.CourtNumber
+	booking.Price = quote.Total
+	booking.PriceBreakdown = quote
+
+	if err := h.bookingRepo.Create(ctx, booking); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
+		return
+	}
+
+	audit(c, "booking.desk_create", "booking", booking.ID.Hex())
+	auditDiff(c, nil, booking)
+
+	c.JSON(http.StatusCreated, booking)
+}
@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+var (
+	errEquipmentUnavailable = errors.New("not enough equipment available")
+	errEquipmentHandedOver  = errors.New("equipment has already been handed over")
+	errEquipmentSlotGone    = errors.New("booking no longer holds its slot")
+)
+
+// GetEquipment lists a facility's equipment with how many of each item are
+// free for a slot.
+func (h *Handler) GetEquipment(c *gin.Context) {
+	_, startTime, endTime, err := parseBookingSlot(c.Query("date"), c.Query("startTime"), c.Query("endTime"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	facility, ok := h.resolveFacility(c, c.Query("facilityId"))
+	if !ok {
+		return
+	}
+
+	items, err := h.equipmentRepo.FindByFacility(c.Request.Context(), facility.ID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch equipment"})
+		return
+	}
+
+	response := []models.EquipmentAvailability{}
+	for _, item := range items {
+		reserved, err := h.bookingRepo.ReservedEquipment(c.Request.Context(), item, primitive.NilObjectID, startTime, endTime)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check equipment availability"})
+			return
+		}
+		response = append(response, models.EquipmentAvailability{
+			Equipment: item,
+			Available: max(item.Quantity-reserved, 0),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReserveEquipment sets how many of an item the caller's booking needs for
+// its slot. A quantity of zero drops the item. Equipment that has been
+// handed over cannot be changed.
+func (h *Handler) ReserveEquipment(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	var req models.ReserveEquipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	itemID, err := primitive.ObjectIDFromHex(req.EquipmentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid equipment ID"})
+		return
+	}
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if booking.StudentID != userClaims.StudentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the booker can reserve equipment"})
+		return
+	}
+	if !h.bookingOpen(c, booking) {
+		return
+	}
+
+	item, err := h.equipmentRepo.FindByID(c.Request.Context(), itemID)
+	if err != nil || item.FacilityID != booking.FacilityID || (!item.IsActive && req.Quantity > 0) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Equipment not found"})
+		return
+	}
+
+	var available int
+	var equipment []*models.BookingEquipment
+	err = repository.RunInTransaction(c.Request.Context(), h.db, func(ctx context.Context) error {
+		if err := h.equipmentRepo.Touch(ctx, item.ID); err != nil {
+			return err
+		}
+		item, err := h.equipmentRepo.FindByID(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+		current, err := h.bookingRepo.FindByID(ctx, booking.ID)
+		if err != nil {
+			return err
+		}
+
+		equipment = []*models.BookingEquipment{}
+		for _, e := range current.Equipment {
+			if e.EquipmentID != item.ID {
+				equipment = append(equipment, e)
+			} else if e.Status != models.EquipmentReserved {
+				return errEquipmentHandedOver
+			}
+		}
+
+		if req.Quantity > 0 {
+			reserved, err := h.bookingRepo.ReservedEquipment(ctx, item, booking.ID, booking.StartTime, booking.EndTime)
+			if err != nil {
+				return err
+			}
+			if available = item.Quantity - reserved; req.Quantity > available {
+				return errEquipmentUnavailable
+			}
+			equipment = append(equipment, &models.BookingEquipment{
+				EquipmentID: item.ID,
+				Name:        item.Name,
+				Kind:        item.Kind,
+				Quantity:    req.Quantity,
+				UnitPrice:   item.Price,
+				Amount:      item.Price * int64(req.Quantity),
+				Status:      models.EquipmentReserved,
+				ReservedAt:  time.Now(),
+			})
+		}
+
+		updated, err := h.bookingRepo.SetEquipment(ctx, booking.ID, equipment)
+		if err == nil && !updated {
+			err = errEquipmentSlotGone
+		}
+		return err
+	})
+	switch {
+	case errors.Is(err, errEquipmentUnavailable):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     fmt.Sprintf("Only %d %s available for this slot", max(available, 0), item.Name),
+			"available": max(available, 0),
+		})
+		return
+	case errors.Is(err, errEquipmentHandedOver):
+		c.JSON(http.StatusConflict, gin.H{"error": "This equipment has already been handed over"})
+		return
+	case errors.Is(err, errEquipmentSlotGone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking no longer holds its slot"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reserve equipment"})
+		return
+	}
+
+	audit(c, "booking.equipment.reserve", "booking", booking.ID.Hex())
+	auditMeta(c, "equipment", item.ID.Hex())
+	auditMeta(c, "quantity", fmt.Sprint(req.Quantity))
+
+	c.JSON(http.StatusOK, equipment)
+}
+
+// CheckOutEquipment records that the front desk handed over the equipment
+// reserved on a booking. Sold items leave stock at this point.
+func (h *Handler) CheckOutEquipment(c *gin.Context) {
+	claims := c.MustGet("user").(*utils.Claims)
+
+	booking, entry, ok := h.findBookingEquipment(c)
+	if !ok {
+		return
+	}
+	if entry.Status != models.EquipmentReserved {
+		c.JSON(http.StatusConflict, gin.H{"error": "This equipment has already been handed over"})
+		return
+	}
+	if booking.Status != "active" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Booking is %s", booking.Status)})
+		return
+	}
+
+	now := time.Now()
+	err := repository.RunInTransaction(c.Request.Context(), h.db, func(ctx context.Context) error {
+		if entry.Kind == models.EquipmentSale {
+			taken, err := h.equipmentRepo.TakeStock(ctx, entry.EquipmentID, entry.Quantity)
+			if err != nil {
+				return err
+			}
+			if !taken {
+				return errEquipmentUnavailable
+			}
+		}
+
+		checkedOut, err := h.bookingRepo.CheckOutEquipment(ctx, booking.ID, entry.EquipmentID, claims.StudentID, now)
+		if err == nil && !checkedOut {
+			err = errEquipmentHandedOver
+		}
+		return err
+	})
+	switch {
+	case errors.Is(err, errEquipmentUnavailable):
+		c.JSON(http.StatusConflict, gin.H{"error": "Not enough " + entry.Name + " left in stock"})
+		return
+	case errors.Is(err, errEquipmentHandedOver):
+		c.JSON(http.StatusConflict, gin.H{"error": "This equipment has already been handed over"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check out equipment"})
+		return
+	}
+
+	audit(c, "booking.equipment.checkout", "booking", booking.ID.Hex())
+	auditMeta(c, "equipment", entry.EquipmentID.Hex())
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Equipment checked out",
+		"amountDue": entry.Amount,
+	})
+}
+
+func (h *Handler) ReturnEquipment(c *gin.Context) {
+	claims := c.MustGet("user").(*utils.Claims)
+
+	booking, entry, ok := h.findBookingEquipment(c)
+	if !ok {
+		return
+	}
+	if entry.Kind != models.EquipmentRental {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sold items are not returned"})
+		return
+	}
+
+	returned, err := h.bookingRepo.ReturnEquipment(c.Request.Context(), booking.ID, entry.EquipmentID, claims.StudentID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to return equipment"})
+		return
+	}
+	if !returned {
+		c.JSON(http.StatusConflict, gin.H{"error": "This equipment is not checked out"})
+		return
+	}
+
+	audit(c, "booking.equipment.return", "booking", booking.ID.Hex())
+	auditMeta(c, "equipment", entry.EquipmentID.Hex())
+
+	c.JSON(http.StatusOK, gin.H{"message": "Equipment returned"})
+}
+
+func (h *Handler) ListEquipment(c *gin.Context) {
+	facility, ok := h.resolveFacility(c, c.Query("facilityId"))
+	if !ok || !h.requireFacilityAccess(c, facility.ID) {
+		return
+	}
+
+	items, err := h.equipmentRepo.FindByFacility(c.Request.Context(), facility.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch equipment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *Handler) CreateEquipment(c *gin.Context) {
+	var req models.EquipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	facility, ok := h.resolveFacility(c, req.FacilityID)
+	if !ok || !h.requireFacilityAccess(c, facility.ID) {
+		return
+	}
+
+	item := &models.Equipment{ID: primitive.NewObjectID(), FacilityID: facility.ID, IsActive: true}
+	if !applyEquipment(c, item, &req) {
+		return
+	}
+
+	if err := h.equipmentRepo.Create(c.Request.Context(), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create equipment"})
+		return
+	}
+
+	audit(c, "equipment.create", "equipment", item.ID.Hex())
+	auditDiff(c, nil, item)
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateEquipment replaces an item's details. Lowering the quantity does
+// not touch existing reservations.
+func (h *Handler) UpdateEquipment(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid equipment ID"})
+		return
+	}
+
+	item, err := h.equipmentRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Equipment not found"})
+		return
+	}
+	if !h.requireFacilityAccess(c, item.FacilityID) {
+		return
+	}
+	audit(c, "equipment.update", "equipment", item.ID.Hex())
+
+	var req models.EquipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	before := *item
+	if !applyEquipment(c, item, &req) {
+		return
+	}
+
+	if err := h.equipmentRepo.Update(c.Request.Context(), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update equipment"})
+		return
+	}
+	auditDiff(c, &before, item)
+
+	c.JSON(http.StatusOK, item)
+}
+
+func applyEquipment(c *gin.Context, item *models.Equipment, req *models.EquipmentRequest) bool {
+	item.Name = strings.TrimSpace(req.Name)
+	if item.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return false
+	}
+	item.Kind = req.Kind
+	item.Quantity = req.Quantity
+	item.Price = req.Price
+	if req.IsActive != nil {
+		item.IsActive = *req.IsActive
+	}
+	return true
+}
+
+// findBookingEquipment loads the booking in the id parameter and its
+// equipment reservation for the equipmentId parameter.
+func (h *Handler) findBookingEquipment(c *gin.Context) (*models.Booking, *models.BookingEquipment, bool) {
+	itemID, err := primitive.ObjectIDFromHex(c.Param("equipmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid equipment ID"})
+		return nil, nil, false
+	}
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	for _, e := range booking.Equipment {
+		if e.EquipmentID == itemID {
+			return booking, e, true
+		}
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "No such equipment on this booking"})
+	return nil, nil, false
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// exportFlushEvery controls how often buffered rows are pushed to the client
+// while a booking export is streaming.
+const exportFlushEvery = 500
+
+var bookingExportHeader = []interface{}{
+	"Booking ID", "Court Number", "Student ID", "Guest Name", "Email", "Date", "Start", "End", "Status", "Channel", "Price (THB)", "Created By", "Created At",
+}
+
+type tableWriter interface {
+	WriteRow(values ...interface{}) error
+	Close() error
+}
+
+type csvTableWriter struct {
+	w *csv.Writer
+}
+
+// newCSVTableWriter starts the output with a UTF-8 byte order mark so Excel
+// opens Thai names correctly instead of guessing a legacy code page.
+func newCSVTableWriter(w io.Writer) (*csvTableWriter, error) {
+	if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+		return nil, err
+	}
+	return &csvTableWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (t *csvTableWriter) WriteRow(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			record[i] = neutralizeFormula(s)
+		} else {
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return t.w.Write(record)
+}
+
+func (t *csvTableWriter) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// neutralizeFormula stops spreadsheet applications from evaluating user
+// supplied text such as names as formulas.
+func neutralizeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// startExport validates the format, sets the download headers and returns
+// the writer rows should be streamed to.
+func startExport(c *gin.Context, name string) (tableWriter, bool) {
+	format := c.DefaultQuery("format", "csv")
+
+	var contentType string
+	switch format {
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+	case "xlsx":
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be csv or xlsx"})
+		return nil, false
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	var w tableWriter
+	var err error
+	if format == "xlsx" {
+		w, err = utils.NewXLSXWriter(c.Writer, name)
+	} else {
+		w, err = newCSVTableWriter(c.Writer)
+	}
+	if err != nil {
+		log.Printf("Error starting %s export: %v", name, err)
+		return nil, false
+	}
+
+	return w, true
+}
+
+func (h *Handler) ExportBookings(c *gin.Context) {
+	filter, err := parseBookingFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.scopeBookingFilter(c, &filter) {
+		return
+	}
+
+	w, ok := startExport(c, "bookings")
+	if !ok {
+		return
+	}
+
+	if err := w.WriteRow(bookingExportHeader...); err != nil {
+		log.Printf("Error writing bookings export: %v", err)
+		return
+	}
+
+	// Headers are already sent at this point, so failures can only be
+	// logged and the download ends up truncated.
+	rows := 0
+	err = h.bookingRepo.Stream(c.Request.Context(), filter, func(b *models.Booking) error {
+		if err := w.WriteRow(
+			b.ID.Hex(),
+			b.CourtNumber,
+			b.StudentID,
+			b.GuestName,
+			b.UserEmail,
+			b.BookingDate.UTC().Format("2006-01-02"),
+			b.StartTime.UTC().Format("15:04"),
+			b.EndTime.UTC().Format("15:04"),
+			b.Status,
+			bookingChannel(b),
+			pricing.FormatAmount(b.Price),
+			b.CreatedBy,
+			b.CreatedAt.Format(time.RFC3339),
+		); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error streaming bookings export: %v", err)
+		return
+	}
+
+	if err := w.Close(); err != nil {
+		log.Printf("Error finishing bookings export: %v", err)
+	}
+}
+
+func bookingChannel(b *models.Booking) string {
+	if b.Channel == "" {
+		return models.ChannelSelfService
+	}
+	return b.Channel
+}
+
+func (h *Handler) ExportAnalytics(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report := c.Param("report")
+	var rows [][]interface{}
+
+	switch report {
+	case "utilization":
+		granularity := c.DefaultQuery("granularity", "day")
+		if granularity != "day" && granularity != "week" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Granularity must be day or week"})
+			return
+		}
+
+		entries, err := h.utilization(c, from, to, granularity == "week")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute utilization"})
+			return
+		}
+
+		rows = append(rows, []interface{}{"Court Number", "Period", "Booked Minutes", "Open Minutes", "Utilization %"})
+		for _, e := range entries {
+			rows = append(rows, []interface{}{e.CourtNumber, e.Period, e.BookedMinutes, e.OpenMinutes, e.UtilizationPct})
+		}
+
+	case "heatmap":
+		cells, err := h.analyticsRepo.Heatmap(c.Request.Context(), from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute heatmap"})
+			return
+		}
+
+		rows = append(rows, []interface{}{"Weekday", "Hour", "Bookings"})
+		for _, cell := range cells {
+			weekday := time.Weekday(cell.Weekday % 7).String()
+			rows = append(rows, []interface{}{weekday, fmt.Sprintf("%02d:00", cell.Hour), cell.Bookings})
+		}
+
+	case "summary":
+		summary, err := h.analyticsRepo.Summary(c.Request.Context(), from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute summary"})
+			return
+		}
+
+		rows = append(rows,
+			[]interface{}{"Metric", "Value"},
+			[]interface{}{"From", from.Format("2006-01-02")},
+			[]interface{}{"To", to.Format("2006-01-02")},
+			[]interface{}{"Total Bookings", summary.TotalBookings},
+			[]interface{}{"Unique Users", summary.UniqueUsers},
+			[]interface{}{"Booked Hours", summary.BookedHours},
+			[]interface{}{"Cancellation Rate", summary.CancellationRate},
+			[]interface{}{"No-show Rate", summary.NoShowRate},
+		)
+
+		statuses := make([]string, 0, len(summary.ByStatus))
+		for status := range summary.ByStatus {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			rows = append(rows, []interface{}{"Status: " + status, summary.ByStatus[status]})
+		}
+
+		channels := make([]string, 0, len(summary.ByChannel))
+		for channel := range summary.ByChannel {
+			channels = append(channels, channel)
+		}
+		sort.Strings(channels)
+		for _, channel := range channels {
+			rows = append(rows, []interface{}{"Channel: " + channel, summary.ByChannel[channel]})
+		}
+
+	case "top-bookers":
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+		if err != nil || limit < 1 || limit > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be between 1 and 100"})
+			return
+		}
+
+		bookers, err := h.analyticsRepo.TopBookers(c.Request.Context(), from, to, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute top bookers"})
+			return
+		}
+
+		rows = append(rows, []interface{}{"Student ID", "Name", "Bookings", "Booked Hours"})
+		for _, b := range bookers {
+			rows = append(rows, []interface{}{b.StudentID, b.Name, b.Bookings, b.BookedHours})
+		}
+
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown report"})
+		return
+	}
+
+	w, ok := startExport(c, report)
+	if !ok {
+		return
+	}
+
+	for _, row := range rows {
+		if err := w.WriteRow(row...); err != nil {
+			log.Printf("Error writing %s export: %v", report, err)
+			return
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		log.Printf("Error finishing %s export: %v", report, err)
+	}
+}
@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+const roleFacilityAdmin = "facility_admin"
+
+// isAdminRole reports whether a role manages courts and bookings, either
+// everywhere or for the facilities it is assigned to.
+func isAdminRole(role string) bool {
+	return role == "admin" || role == roleFacilityAdmin
+}
+
+// FacilityAdminMiddleware admits admins and facility admins. Handlers behind
+// it still check that a facility admin manages the facility involved.
+func (h *Handler) FacilityAdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("user").(*utils.Claims)
+		if !isAdminRole(claims.Role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+
+		if h.cfg.AdminRequire2FA && !claims.MFA {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for admin access"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// managedFacilityIDs returns the facilities the caller may manage, or nil
+// for admins, who manage every facility.
+func (h *Handler) managedFacilityIDs(c *gin.Context) ([]primitive.ObjectID, error) {
+	claims := c.MustGet("user").(*utils.Claims)
+	if claims.Role == "admin" {
+		return nil, nil
+	}
+
+	ids := []primitive.ObjectID{}
+	if claims.Role != roleFacilityAdmin {
+		return ids, nil
+	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	facilities, err := h.facilityRepo.FindByAdmin(c.Request.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, facility := range facilities {
+		ids = append(ids, facility.ID)
+	}
+
+	return ids, nil
+}
+
+func (h *Handler) canManageFacility(c *gin.Context, facilityID primitive.ObjectID) (bool, error) {
+	ids, err := h.managedFacilityIDs(c)
+	if err != nil {
+		return false, err
+	}
+	return ids == nil || containsObjectID(ids, facilityID), nil
+}
+
+// requireFacilityAccess writes a 403 unless the caller manages the facility.
+func (h *Handler) requireFacilityAccess(c *gin.Context, facilityID primitive.ObjectID) bool {
+	ok, err := h.canManageFacility(c, facilityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check facility permissions"})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not manage this facility"})
+		return false
+	}
+	return true
+}
+
+// scopeBookingFilter limits a booking filter to the caller's facilities.
+func (h *Handler) scopeBookingFilter(c *gin.Context, filter *repository.BookingFilter) bool {
+	ids, err := h.managedFacilityIDs(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check facility permissions"})
+		return false
+	}
+	if ids == nil {
+		return true
+	}
+
+	if filter.FacilityIDs == nil {
+		filter.FacilityIDs = ids
+		return true
+	}
+	for _, requested := range filter.FacilityIDs {
+		if !containsObjectID(ids, requested) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not manage this facility"})
+			return false
+		}
+	}
+	return true
+}
+
+func containsObjectID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveFacility looks up the facility a request refers to. Clients that
+// predate facilities send no ID and get the default facility.
+func (h *Handler) resolveFacility(c *gin.Context, idHex string) (*models.Facility, bool) {
+	ctx := c.Request.Context()
+
+	if idHex == "" {
+		facility, err := h.facilityRepo.FindDefault(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch facility"})
+			return nil, false
+		}
+		return facility, true
+	}
+
+	id, err := primitive.ObjectIDFromHex(idHex)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid facility ID"})
+		return nil, false
+	}
+
+	facility, err := h.facilityRepo.FindByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Facility not found"})
+		return nil, false
+	}
+
+	return facility, true
+}
+
+// facilityInstant turns a stored wall-clock booking time into the instant
+// it happens at in the facility's timezone.
+func facilityInstant(facility *models.Facility, wall time.Time) time.Time {
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, facilityLocation(facility))
+}
+
+// facilityWallClock is the inverse of facilityInstant: the facility's local
+// time at t, in the zone-less form booking times are stored in.
+func facilityWallClock(facility *models.Facility, t time.Time) time.Time {
+	local := t.In(facilityLocation(facility))
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), 0, 0, time.UTC)
+}
+
+func facilityLocation(facility *models.Facility) *time.Location {
+	loc, err := time.LoadLocation(facility.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// checkOperatingHours rejects slots outside the facility's opening hours.
+func checkOperatingHours(facility *models.Facility, startTime, endTime time.Time) error {
+	start := startTime.Format("15:04")
+	end := endTime.Format("15:04")
+	if start < facility.OpeningTime || end > facility.ClosingTime {
+		return fmt.Errorf("%s is open from %s to %s", facility.Name, facility.OpeningTime, facility.ClosingTime)
+	}
+	return nil
+}
+
+func validateFacility(facility *models.Facility) error {
+	if facility.Name == "" {
+		return errors.New("Name is required")
+	}
+	if _, err := time.LoadLocation(facility.Timezone); err != nil || facility.Timezone == "" {
+		return errors.New("Invalid timezone")
+	}
+
+	openAt, errOpen := time.Parse("15:04", facility.OpeningTime)
+	closeAt, errClose := time.Parse("15:04", facility.ClosingTime)
+	if errOpen != nil || errClose != nil {
+		return errors.New("Invalid operating hours, use HH:MM")
+	}
+	if !closeAt.After(openAt) {
+		return errors.New("Closing time must be after opening time")
+	}
+	return nil
+}
+
+func (h *Handler) GetFacilities(c *gin.Context) {
+	facilities, err := h.facilityRepo.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch facilities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, facilities)
+}
+
+func (h *Handler) GetFacility(c *gin.Context) {
+	facility, ok := h.findFacilityParam(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, facility)
+}
+
+func (h *Handler) GetFacilityCourts(c *gin.Context) {
+	facility, ok := h.findFacilityParam(c)
+	if !ok {
+		return
+	}
+
+	courts, err := h.courtRepo.FindByFacility(c.Request.Context(), facility.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch courts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, courts)
+}
+
+// ListManagedFacilities returns every facility to admins and the assigned
+// ones to facility admins.
+func (h *Handler) ListManagedFacilities(c *gin.Context) {
+	ids, err := h.managedFacilityIDs(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch facilities"})
+		return
+	}
+
+	facilities, err := h.facilityRepo.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch facilities"})
+		return
+	}
+
+	managed := []*models.Facility{}
+	for _, facility := range facilities {
+		if ids == nil || containsObjectID(ids, facility.ID) {
+			managed = append(managed, facility)
+		}
+	}
+
+	c.JSON(http.StatusOK, managed)
+}
+
+func (h *Handler) CreateFacility(c *gin.Context) {
+	var req models.CreateFacilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	facility := &models.Facility{
+		ID:          primitive.NewObjectID(),
+		Name:        strings.TrimSpace(req.Name),
+		Address:     strings.TrimSpace(req.Address),
+		Timezone:    req.Timezone,
+		OpeningTime: req.OpeningTime,
+		ClosingTime: req.ClosingTime,
+
+		WeekendSurchargePct: req.WeekendSurchargePct,
+	}
+	if facility.Timezone == "" {
+		facility.Timezone = h.cfg.FacilityTimezone
+	}
+	if facility.OpeningTime == "" {
+		facility.OpeningTime = h.cfg.OpeningTime
+	}
+	if facility.ClosingTime == "" {
+		facility.ClosingTime = h.cfg.ClosingTime
+	}
+
+	if err := validateFacility(facility); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.facilityRepo.Create(c.Request.Context(), facility); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create facility"})
+		return
+	}
+
+	audit(c, "facility.create", "facility", facility.ID.Hex())
+	auditDiff(c, nil, facility)
+
+	c.JSON(http.StatusCreated, facility)
+}
+
+func (h *Handler) UpdateFacility(c *gin.Context) {
+	facility, ok := h.findFacilityParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "facility.update", "facility", facility.ID.Hex())
+
+	var req models.UpdateFacilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	before := *facility
+	if req.Name != nil {
+		facility.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Address != nil {
+		facility.Address = strings.TrimSpace(*req.Address)
+	}
+	if req.Timezone != nil {
+		facility.Timezone = *req.Timezone
+	}
+	if req.OpeningTime != nil {
+		facility.OpeningTime = *req.OpeningTime
+	}
+	if req.ClosingTime != nil {
+		facility.ClosingTime = *req.ClosingTime
+	}
+	if req.WeekendSurchargePct != nil {
+		facility.WeekendSurchargePct = *req.WeekendSurchargePct
+	}
+
+	if err := validateFacility(facility); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.facilityRepo.Update(c.Request.Context(), facility); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update facility"})
+		return
+	}
+	auditDiff(c, &before, facility)
+
+	c.JSON(http.StatusOK, facility)
+}
+
+// SetFacilityAdmins replaces the facility's admins. Every listed user must
+// already have the facility_admin role.
+func (h *Handler) SetFacilityAdmins(c *gin.Context) {
+	facility, ok := h.findFacilityParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "facility.admins", "facility", facility.ID.Hex())
+
+	var req models.FacilityAdminsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	admins := []primitive.ObjectID{}
+	for _, studentID := range req.StudentIDs {
+		user, err := h.userRepo.FindByStudentID(ctx, strings.TrimSpace(studentID))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User " + studentID + " not found"})
+			return
+		}
+		if user.Role != roleFacilityAdmin {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User " + studentID + " must have the facility_admin role"})
+			return
+		}
+		if !containsObjectID(admins, user.ID) {
+			admins = append(admins, user.ID)
+		}
+	}
+
+	before := *facility
+	facility.AdminIDs = admins
+	if err := h.facilityRepo.Update(ctx, facility); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update facility admins"})
+		return
+	}
+	auditDiff(c, &before, facility)
+
+	c.JSON(http.StatusOK, facility)
+}
+
+func (h *Handler) findFacilityParam(c *gin.Context) (*models.Facility, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid facility ID"})
+		return nil, false
+	}
+
+	facility, err := h.facilityRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Facility not found"})
+		return nil, false
+	}
+
+	return facility, true
+}
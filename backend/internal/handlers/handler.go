@@ -1,22 +1,45 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"courtopia-reserve/backend/internal/config"
+	"courtopia-reserve/backend/internal/payments"
 	"courtopia-reserve/backend/internal/repository"
 	"courtopia-reserve/backend/pkg/utils"
 )
 
 type Handler struct {
-	db          *mongo.Database
-	userRepo    *repository.UserRepository
-	courtRepo   *repository.CourtRepository
-	bookingRepo *repository.BookingRepository
-	jwtSecret   string
+	db               *mongo.Database
+	userRepo         *repository.UserRepository
+	courtRepo        *repository.CourtRepository
+	bookingRepo      *repository.BookingRepository
+	tokenRepo        *repository.APITokenRepository
+	notificationRepo *repository.NotificationRepository
+	analyticsRepo    *repository.AnalyticsRepository
+	auditRepo        *repository.AuditLogRepository
+	announcementRepo *repository.AnnouncementRepository
+	facilityRepo     *repository.FacilityRepository
+	pricingRepo      *repository.PricingRuleRepository
+	paymentRepo      *repository.PaymentRepository
+	walletRepo       *repository.WalletRepository
+	promoRepo        *repository.PromoCodeRepository
+	membershipRepo   *repository.MembershipPlanRepository
+	invoiceRepo      *repository.InvoiceRepository
+	equipmentRepo    *repository.EquipmentRepository
+	cfg              *config.Config
+	keys             *utils.KeySet
+	oidc             *utils.OIDCProvider
+	payments         payments.Provider
+	invoiceFont      *utils.TrueTypeFont
 }
 
 func NewHandler(
@@ -24,14 +47,74 @@ func NewHandler(
 	userRepo *repository.UserRepository,
 	courtRepo *repository.CourtRepository,
 	bookingRepo *repository.BookingRepository,
-	jwtSecret string,
+	tokenRepo *repository.APITokenRepository,
+	notificationRepo *repository.NotificationRepository,
+	analyticsRepo *repository.AnalyticsRepository,
+	auditRepo *repository.AuditLogRepository,
+	announcementRepo *repository.AnnouncementRepository,
+	facilityRepo *repository.FacilityRepository,
+	pricingRepo *repository.PricingRuleRepository,
+	paymentRepo *repository.PaymentRepository,
+	walletRepo *repository.WalletRepository,
+	promoRepo *repository.PromoCodeRepository,
+	membershipRepo *repository.MembershipPlanRepository,
+	invoiceRepo *repository.InvoiceRepository,
+	equipmentRepo *repository.EquipmentRepository,
+	keys *utils.KeySet,
+	cfg *config.Config,
 ) *Handler {
+	var invoiceFont *utils.TrueTypeFont
+	if cfg.InvoiceFontPath != "" {
+		font, err := utils.LoadTrueTypeFont(cfg.InvoiceFontPath)
+		if err != nil {
+			log.Printf("Warning: cannot load invoice font, documents will be in English only: %v", err)
+		}
+		invoiceFont = font
+	}
+
 	return &Handler{
-		db:          db,
-		userRepo:    userRepo,
-		courtRepo:   courtRepo,
-		bookingRepo: bookingRepo,
-		jwtSecret:   jwtSecret,
+		db:               db,
+		userRepo:         userRepo,
+		courtRepo:        courtRepo,
+		bookingRepo:      bookingRepo,
+		tokenRepo:        tokenRepo,
+		notificationRepo: notificationRepo,
+		analyticsRepo:    analyticsRepo,
+		auditRepo:        auditRepo,
+		announcementRepo: announcementRepo,
+		facilityRepo:     facilityRepo,
+		pricingRepo:      pricingRepo,
+		paymentRepo:      paymentRepo,
+		walletRepo:       walletRepo,
+		promoRepo:        promoRepo,
+		membershipRepo:   membershipRepo,
+		invoiceRepo:      invoiceRepo,
+		equipmentRepo:    equipmentRepo,
+		cfg:              cfg,
+		keys:             keys,
+		oidc: utils.NewOIDCProvider(utils.OIDCConfig{
+			Issuer:         cfg.OIDCIssuer,
+			ClientID:       cfg.OIDCClientID,
+			ClientSecret:   cfg.OIDCClientSecret,
+			RedirectURL:    cfg.OIDCRedirectURL,
+			Scopes:         cfg.OIDCScopes,
+			StudentIDClaim: cfg.OIDCStudentIDClaim,
+			NameClaim:      cfg.OIDCNameClaim,
+			EmailClaim:     cfg.OIDCEmailClaim,
+		}),
+		payments:    newPaymentProvider(cfg),
+		invoiceFont: invoiceFont,
+	}
+}
+
+// newPaymentProvider returns the configured provider, or nil when online
+// payments are turned off.
+func newPaymentProvider(cfg *config.Config) payments.Provider {
+	switch cfg.PaymentProvider {
+	case "fake":
+		return payments.NewFakeProvider(cfg.PromptPayID, cfg.PaymentWebhookSecret)
+	default:
+		return nil
 	}
 }
 
@@ -51,14 +134,174 @@ func (h *Handler) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := utils.ValidateToken(bearerToken[1], h.jwtSecret)
-		if err != nil {
+		var claims *utils.Claims
+		var err error
+		if utils.IsAPIToken(bearerToken[1]) {
+			claims, err = h.apiTokenClaims(c, bearerToken[1])
+		} else {
+			claims, err = utils.ValidateToken(bearerToken[1], h.keys)
+		}
+		if err != nil || claims.Purpose != "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
+		if status, message := h.checkAccount(c, claims); status != 0 {
+			c.JSON(status, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+
 		c.Set("user", claims)
+
+		if claims.IsImpersonation() {
+			if status, message := h.checkImpersonation(c, claims); status != 0 {
+				c.JSON(status, gin.H{"error": message})
+				c.Abort()
+				return
+			}
+			c.Header("X-Impersonated-By", claims.Act.StudentID)
+		}
+
+		c.Next()
+	}
+}
+
+// impersonationSafeRoutes are the requests an impersonation token may make
+// without allowDestructive: reads of the user's own data and checks that
+// change nothing. Anything else could move money or ownership, so a route
+// must be added here deliberately before an admin can use it as the user.
+var impersonationSafeRoutes = map[string]bool{
+	"GET /api/bookings":                   true,
+	"GET /api/bookings/transfers":         true,
+	"POST /api/bookings/check":            true,
+	"GET /api/bookings/:id/cancellation":  true,
+	"GET /api/bookings/:id/payment":       true,
+	"GET /api/bookings/:id/invoices":      true,
+	"GET /api/open-games":                 true,
+	"GET /api/equipment":                  true,
+	"GET /api/invoices":                   true,
+	"GET /api/invoices/:id/pdf":           true,
+	"GET /api/wallet":                     true,
+	"GET /api/wallet/statement":           true,
+	"GET /api/wallet/topups/:id/invoices": true,
+	"GET /api/profile":                    true,
+}
+
+// checkImpersonation re-checks the admin behind an impersonation token on
+// every request and refuses requests outside impersonationSafeRoutes that
+// the token was not issued for.
+func (h *Handler) checkImpersonation(c *gin.Context, claims *utils.Claims) (int, string) {
+	actorID, err := primitive.ObjectIDFromHex(claims.Act.Subject)
+	if err != nil {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+
+	actor, err := h.userRepo.FindByID(c.Request.Context(), actorID)
+	if err != nil || actor.Role != "admin" || actor.Suspended {
+		return http.StatusUnauthorized, "Impersonation is no longer allowed"
+	}
+
+	if !claims.AllowDestructive && !impersonationSafeRoutes[c.Request.Method+" "+c.FullPath()] {
+		return http.StatusForbidden, "Only read-only actions are allowed while impersonating"
+	}
+
+	return 0, ""
+}
+
+// checkAccount rejects suspended users and sessions issued before the
+// user's tokens were revoked. It returns a zero status when access is
+// allowed.
+func (h *Handler) checkAccount(c *gin.Context, claims *utils.Claims) (int, string) {
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+
+	user, err := h.userRepo.FindByID(c.Request.Context(), userID)
+	if err != nil {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+
+	if user.Suspended {
+		return http.StatusForbidden, "Account is suspended"
+	}
+
+	if !claims.IsAPIToken() && user.TokensValidAfter != nil &&
+		(claims.IssuedAt == nil || claims.IssuedAt.Time.Before(user.TokensValidAfter.Truncate(time.Second))) {
+		return http.StatusUnauthorized, "Session has been revoked, please log in again"
+	}
+
+	return 0, ""
+}
+
+// apiTokenClaims resolves a personal API token to the same claims a login
+// session carries, restricted to the token's scopes.
+func (h *Handler) apiTokenClaims(c *gin.Context, rawToken string) (*utils.Claims, error) {
+	ctx := c.Request.Context()
+
+	token, err := h.tokenRepo.FindByHash(ctx, utils.HashAPIToken(rawToken))
+	if err != nil {
+		return nil, err
+	}
+	if token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, errInvalidAPIToken
+	}
+
+	user, err := h.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.tokenRepo.TouchLastUsed(ctx, token.ID); err != nil {
+		log.Printf("Error updating API token last use: %v", err)
+	}
+
+	return &utils.Claims{
+		StudentID: user.StudentID,
+		Role:      user.Role,
+		Eamil:     user.Email,
+		Scopes:    token.Scopes,
+		TokenID:   token.ID.Hex(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.Hex(),
+			ExpiresAt: jwt.NewNumericDate(token.ExpiresAt),
+		},
+	}, nil
+}
+
+// RequireScope lets login sessions through unchanged and requires API
+// tokens to carry the given scope.
+func (h *Handler) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("user").(*utils.Claims)
+		if claims.IsAPIToken() && !claims.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API token is missing the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SessionOnly rejects API tokens on routes that manage credentials or
+// require an interactive login.
+func (h *Handler) SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("user").(*utils.Claims)
+		if claims.IsAPIToken() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used with an API token"})
+			c.Abort()
+			return
+		}
+		if claims.IsImpersonation() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used while impersonating"})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -78,17 +321,31 @@ func (h *Handler) AdminMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if h.cfg.AdminRequire2FA && !claims.(*utils.Claims).MFA {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for admin access"})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
 
 func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	r.Use(h.RequestID(), h.AuditMiddleware())
+
+	r.GET("/.well-known/jwks.json", h.JWKS)
+
 	api := r.Group("/api")
 
 	auth := api.Group("/auth")
 	{
 		auth.POST("/register", h.Register)
 		auth.POST("/login", h.Login)
+		auth.GET("/oidc/login", h.OIDCLogin)
+		auth.GET("/oidc/callback", h.OIDCCallback)
+		auth.POST("/2fa/verify", h.VerifyTwoFactorLogin)
+		auth.POST("/password/reset", h.ResetPassword)
 	}
 
 	courts := api.Group("/courts")
@@ -96,29 +353,168 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 		courts.GET("", h.GetCourts)
 		courts.GET("/available", h.GetAvailableCourts)
 		courts.GET("/:id", h.GetCourt)
+		courts.GET("/:id/announcements", h.GetCourtAnnouncements)
+	}
+
+	facilities := api.Group("/facilities")
+	{
+		facilities.GET("", h.GetFacilities)
+		facilities.GET("/:id", h.GetFacility)
+		facilities.GET("/:id/courts", h.GetFacilityCourts)
+	}
+
+	api.GET("/memberships/plans", h.GetMembershipPlans)
+
+	announcements := api.Group("/announcements")
+	{
+		announcements.GET("", h.GetAnnouncements)
 	}
 
 	bookings := api.Group("/bookings")
 	bookings.Use(h.AuthMiddleware())
 	{
-		bookings.POST("", h.CreateBooking)
-		bookings.GET("", h.GetUserBookings)
-		bookings.POST("/check", h.CheckAvailability)
-		bookings.DELETE("/:id", h.CancelBooking)
+		bookings.POST("", h.RequireScope(scopeBookingsWrite), h.CreateBooking)
+		bookings.GET("", h.RequireScope(scopeBookingsRead), h.GetUserBookings)
+		bookings.GET("/transfers", h.RequireScope(scopeBookingsRead), h.GetTransferOffers)
+		bookings.POST("/check", h.RequireScope(scopeBookingsRead), h.CheckAvailability)
+		bookings.DELETE("/:id", h.RequireScope(scopeBookingsWrite), h.CancelBooking)
+		bookings.GET("/:id/cancellation", h.RequireScope(scopeBookingsRead), h.PreviewCancellation)
+		bookings.GET("/:id/payment", h.RequireScope(scopeBookingsRead), h.GetBookingPayment)
+		bookings.GET("/:id/invoices", h.RequireScope(scopeBookingsRead), h.GetBookingInvoices)
+		bookings.POST("/:id/participants", h.RequireScope(scopeBookingsWrite), h.AddParticipant)
+		bookings.DELETE("/:id/participants/:participantId", h.RequireScope(scopeBookingsWrite), h.RemoveParticipant)
+		bookings.POST("/:id/invitation/accept", h.RequireScope(scopeBookingsWrite), h.AcceptInvitation)
+		bookings.POST("/:id/invitation/decline", h.RequireScope(scopeBookingsWrite), h.DeclineInvitation)
+		bookings.PUT("/:id/open-game", h.RequireScope(scopeBookingsWrite), h.OpenBookingGame)
+		bookings.DELETE("/:id/open-game", h.RequireScope(scopeBookingsWrite), h.CloseBookingGame)
+		bookings.POST("/:id/participants/:participantId/approve", h.RequireScope(scopeBookingsWrite), h.ApproveParticipant)
+		bookings.POST("/:id/participants/:participantId/reject", h.RequireScope(scopeBookingsWrite), h.RejectParticipant)
+		bookings.POST("/:id/transfer", h.RequireScope(scopeBookingsWrite), h.OfferBookingTransfer)
+		bookings.DELETE("/:id/transfer", h.RequireScope(scopeBookingsWrite), h.WithdrawBookingTransfer)
+		bookings.POST("/:id/transfer/accept", h.RequireScope(scopeBookingsWrite), h.AcceptBookingTransfer)
+		bookings.POST("/:id/transfer/decline", h.RequireScope(scopeBookingsWrite), h.DeclineBookingTransfer)
+		bookings.PUT("/:id/equipment", h.RequireScope(scopeBookingsWrite), h.ReserveEquipment)
+	}
+
+	openGames := api.Group("/open-games")
+	openGames.Use(h.AuthMiddleware())
+	{
+		openGames.GET("", h.RequireScope(scopeBookingsRead), h.GetOpenGames)
+		openGames.POST("/:id/join", h.RequireScope(scopeBookingsWrite), h.JoinOpenGame)
+		openGames.POST("/:id/leave", h.RequireScope(scopeBookingsWrite), h.LeaveOpenGame)
+	}
+
+	api.GET("/equipment", h.AuthMiddleware(), h.RequireScope(scopeBookingsRead), h.GetEquipment)
+
+	invoices := api.Group("/invoices")
+	invoices.Use(h.AuthMiddleware())
+	{
+		invoices.GET("", h.RequireScope(scopeBookingsRead), h.GetInvoices)
+		invoices.GET("/:id/pdf", h.RequireScope(scopeBookingsRead), h.DownloadInvoice)
+	}
+
+	api.POST("/payments/webhook", h.PaymentWebhook)
+
+	wallet := api.Group("/wallet")
+	wallet.Use(h.AuthMiddleware())
+	{
+		wallet.GET("", h.RequireScope(scopeWalletRead), h.GetWallet)
+		wallet.GET("/statement", h.RequireScope(scopeWalletRead), h.GetWalletStatement)
+		wallet.POST("/topups", h.RequireScope(scopeWalletWrite), h.CreateWalletTopUp)
+		wallet.GET("/topups/:id/invoices", h.RequireScope(scopeWalletRead), h.GetTopUpInvoices)
 	}
 
 	profile := api.Group("/profile")
 	profile.Use(h.AuthMiddleware())
 	{
-		profile.GET("", h.GetProfile)                   
-		profile.PUT("", h.UpdateProfile)                
-		profile.POST("/upload", h.UploadProfilePicture) 
+		profile.GET("", h.RequireScope(scopeProfileRead), h.GetProfile)
+		profile.PUT("", h.RequireScope(scopeProfileWrite), h.UpdateProfile)
+		profile.POST("/upload", h.RequireScope(scopeProfileWrite), h.UploadProfilePicture)
+	}
+
+	session := profile.Group("")
+	session.Use(h.SessionOnly())
+	{
+		session.GET("/2fa", h.GetTwoFactorStatus)
+		session.POST("/oidc/link", h.StartOIDCLink)
+		session.POST("/2fa/setup", h.SetupTwoFactor)
+		session.POST("/2fa/enable", h.EnableTwoFactor)
+		session.POST("/2fa/disable", h.DisableTwoFactor)
+		session.POST("/2fa/recovery-codes", h.RegenerateRecoveryCodes)
+		session.GET("/tokens", h.ListAPITokens)
+		session.POST("/tokens", h.CreateAPIToken)
+		session.DELETE("/tokens/:id", h.RevokeAPIToken)
+		session.GET("/export", h.ExportAccountData)
+		session.DELETE("", h.DeleteAccount)
+	}
+
+	staff := api.Group("/staff")
+	staff.Use(h.AuthMiddleware(), h.SessionOnly(), h.StaffMiddleware())
+	{
+		staff.POST("/bookings", h.CreateDeskBooking)
+		staff.POST("/bookings/:id/equipment/:equipmentId/checkout", h.CheckOutEquipment)
+		staff.POST("/bookings/:id/equipment/:equipmentId/return", h.ReturnEquipment)
+	}
+
+	manage := api.Group("/admin")
+	manage.Use(h.AuthMiddleware(), h.SessionOnly(), h.FacilityAdminMiddleware())
+	{
+		manage.GET("/facilities", h.ListManagedFacilities)
+		manage.POST("/courts", h.CreateCourt)
+		manage.PUT("/courts/:id", h.UpdateCourt)
+		manage.DELETE("/courts/:id", h.RetireCourt)
+		manage.PATCH("/courts/:id/status", h.UpdateCourtStatus)
+		manage.GET("/bookings", h.GetAllBookings)
+		manage.POST("/bookings/bulk", h.BulkUpdateBookings)
+		manage.GET("/exports/bookings", h.ExportBookings)
+		manage.GET("/pricing-rules", h.ListPricingRules)
+		manage.POST("/pricing-rules", h.CreatePricingRule)
+		manage.PUT("/pricing-rules/:id", h.UpdatePricingRule)
+		manage.DELETE("/pricing-rules/:id", h.DeletePricingRule)
+		manage.GET("/equipment", h.ListEquipment)
+		manage.POST("/equipment", h.CreateEquipment)
+		manage.PUT("/equipment/:id", h.UpdateEquipment)
 	}
 
 	admin := api.Group("/admin")
-	admin.Use(h.AuthMiddleware(), h.AdminMiddleware())
+	admin.Use(h.AuthMiddleware(), h.SessionOnly(), h.AdminMiddleware())
 	{
-		admin.PATCH("/courts/:id/status", h.UpdateCourtStatus)
-		admin.GET("/bookings", h.GetAllBookings)
+		admin.POST("/facilities", h.CreateFacility)
+		admin.PUT("/facilities/:id", h.UpdateFacility)
+		admin.PUT("/facilities/:id/admins", h.SetFacilityAdmins)
+		admin.GET("/users", h.ListUsers)
+		admin.GET("/users/:id", h.GetUser)
+		admin.PATCH("/users/:id/role", h.UpdateUserRole)
+		admin.PATCH("/users/:id/pricing-tier", h.UpdateUserPricingTier)
+		admin.GET("/users/:id/wallet", h.GetUserWalletStatement)
+		admin.POST("/users/:id/wallet/adjustments", h.AdjustUserWallet)
+		admin.DELETE("/users/:id/cancel-strikes", h.ClearCancelStrikes)
+		admin.PUT("/users/:id/membership", h.GrantMembership)
+		admin.DELETE("/users/:id/membership", h.RevokeMembership)
+		admin.POST("/users/:id/suspend", h.SuspendUser)
+		admin.POST("/users/:id/unsuspend", h.UnsuspendUser)
+		admin.POST("/users/:id/password-reset", h.ForcePasswordReset)
+		admin.DELETE("/users/:id", h.DeleteUser)
+		admin.POST("/users/:id/impersonate", h.ImpersonateUser)
+		admin.GET("/analytics/utilization", h.GetUtilization)
+		admin.GET("/analytics/heatmap", h.GetPeakHeatmap)
+		admin.GET("/analytics/summary", h.GetAnalyticsSummary)
+		admin.GET("/analytics/top-bookers", h.GetTopBookers)
+		admin.GET("/exports/analytics/:report", h.ExportAnalytics)
+		admin.GET("/audit-logs", h.ListAuditLogs)
+		admin.GET("/wallets/reconciliation", h.GetWalletReconciliation)
+		admin.GET("/invoices", h.ListInvoices)
+		admin.GET("/invoices/:id/pdf", h.DownloadAdminInvoice)
+		admin.GET("/bookings/:id/invoices", h.GetAdminBookingInvoices)
+		admin.GET("/membership-plans", h.ListMembershipPlans)
+		admin.POST("/membership-plans", h.CreateMembershipPlan)
+		admin.PUT("/membership-plans/:id", h.UpdateMembershipPlan)
+		admin.GET("/promo-codes", h.ListPromoCodes)
+		admin.POST("/promo-codes", h.CreatePromoCode)
+		admin.PUT("/promo-codes/:id", h.UpdatePromoCode)
+		admin.GET("/announcements", h.ListAllAnnouncements)
+		admin.POST("/announcements", h.CreateAnnouncement)
+		admin.PUT("/announcements/:id", h.UpdateAnnouncement)
+		admin.POST("/announcements/:id/expire", h.ExpireAnnouncement)
 	}
 }
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"courtopia-reserve/backend/internal/invoice"
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+var errNotPaid = errors.New("nothing has been paid")
+
+// GetInvoices lists the caller's invoices and receipts.
+func (h *Handler) GetInvoices(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := parseInvoiceFilter(c)
+	if !ok {
+		return
+	}
+	filter.UserID = &user.ID
+	filter.StudentID = ""
+
+	h.listInvoices(c, filter)
+}
+
+func (h *Handler) ListInvoices(c *gin.Context) {
+	filter, ok := parseInvoiceFilter(c)
+	if !ok {
+		return
+	}
+
+	h.listInvoices(c, filter)
+}
+
+// GetBookingInvoices returns the invoice and receipt of one of the caller's
+// paid bookings, issuing them first if that has not happened yet.
+func (h *Handler) GetBookingInvoices(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	booking, ok := h.findInvoiceBooking(c)
+	if !ok {
+		return
+	}
+	if bookingPayerStudentID(booking) != userClaims.StudentID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+
+	h.respondBookingInvoices(c, booking)
+}
+
+func (h *Handler) GetAdminBookingInvoices(c *gin.Context) {
+	booking, ok := h.findInvoiceBooking(c)
+	if !ok {
+		return
+	}
+
+	h.respondBookingInvoices(c, booking)
+}
+
+// GetTopUpInvoices returns the invoice and receipt of one of the caller's
+// paid wallet top-ups.
+func (h *Handler) GetTopUpInvoices(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid top-up ID"})
+		return
+	}
+
+	payment, err := h.paymentRepo.FindByID(c.Request.Context(), id)
+	if err != nil || payment.UserID != user.ID || payment.Purpose != models.PaymentForWalletTopUp {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Top-up not found"})
+		return
+	}
+
+	invoices, err := h.issueTopUpInvoices(c.Request.Context(), payment)
+	if errors.Is(err, errNotPaid) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "This top-up has not been paid"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue documents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+func (h *Handler) DownloadInvoice(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	inv, ok := h.findInvoiceParam(c)
+	if !ok {
+		return
+	}
+	if inv.UserID != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	sendInvoicePDF(c, inv)
+}
+
+func (h *Handler) DownloadAdminInvoice(c *gin.Context) {
+	inv, ok := h.findInvoiceParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "invoice.download", "invoice", inv.ID.Hex())
+
+	sendInvoicePDF(c, inv)
+}
+
+func (h *Handler) listInvoices(c *gin.Context, filter repository.InvoiceFilter) {
+	invoices, total, err := h.invoiceRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch documents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invoices": invoices,
+		"total":    total,
+		"page":     filter.Page,
+		"limit":    filter.Limit,
+	})
+}
+
+func (h *Handler) respondBookingInvoices(c *gin.Context, booking *models.Booking) {
+	invoices, err := h.issueBookingInvoices(c.Request.Context(), booking)
+	if errors.Is(err, errNotPaid) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "This booking has not been paid"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue documents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+func parseInvoiceFilter(c *gin.Context) (repository.InvoiceFilter, bool) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	kind := c.Query("kind")
+	if kind != "" && kind != models.InvoiceKindInvoice && kind != models.InvoiceKindReceipt {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kind must be invoice or receipt"})
+		return repository.InvoiceFilter{}, false
+	}
+
+	from, to, ok := parseLedgerRange(c)
+	if !ok {
+		return repository.InvoiceFilter{}, false
+	}
+
+	return repository.InvoiceFilter{
+		StudentID: c.Query("studentId"),
+		Kind:      kind,
+		From:      from,
+		To:        to,
+		Page:      page,
+		Limit:     limit,
+	}, true
+}
+
+func (h *Handler) findInvoiceBooking(c *gin.Context) (*models.Booking, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return nil, false
+	}
+
+	booking, err := h.bookingRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return nil, false
+	}
+
+	return booking, true
+}
+
+func (h *Handler) findInvoiceParam(c *gin.Context) (*models.Invoice, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return nil, false
+	}
+
+	inv, err := h.invoiceRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return nil, false
+	}
+
+	return inv, true
+}
+
+func sendInvoicePDF(c *gin.Context, inv *models.Invoice) {
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, inv.Number))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/pdf", inv.PDF)
+}
+
+// issueBookingInvoices returns a paid booking's documents, issuing any that
+// are missing. Free bookings get none.
+func (h *Handler) issueBookingInvoices(ctx context.Context, booking *models.Booking) ([]*models.Invoice, error) {
+	if booking.PaidAt == nil || booking.Price <= 0 {
+		return nil, errNotPaid
+	}
+
+	existing, err := h.invoiceRepo.FindForBookings(ctx, []primitive.ObjectID{booking.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.issueInvoices(ctx, existing[booking.ID], func() (*models.Invoice, error) {
+		payer := booking.UserID
+		if booking.PaidByUserID != nil {
+			payer = *booking.PaidByUserID
+		}
+		user, err := h.userRepo.FindByID(ctx, payer)
+		if err != nil {
+			return nil, err
+		}
+		facility, err := h.facilityRepo.FindByID(ctx, booking.FacilityID)
+		if err != nil {
+			return nil, err
+		}
+		return invoice.ForBooking(booking, user, facility), nil
+	})
+}
+
+// issueBookingDocuments issues a booking's documents as soon as it is paid
+// so they are ready to download. Failures are retried on first request.
+func (h *Handler) issueBookingDocuments(ctx context.Context, booking *models.Booking) {
+	if _, err := h.issueBookingInvoices(ctx, booking); err != nil && !errors.Is(err, errNotPaid) {
+		log.Printf("Error issuing documents for booking %s: %v", booking.ID.Hex(), err)
+	}
+}
+
+func (h *Handler) issueTopUpInvoices(ctx context.Context, payment *models.Payment) ([]*models.Invoice, error) {
+	if payment.Status != models.PaymentPaid || payment.PaidAt == nil {
+		return nil, errNotPaid
+	}
+
+	existing, err := h.invoiceRepo.FindForPayment(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.issueInvoices(ctx, existing, func() (*models.Invoice, error) {
+		user, err := h.userRepo.FindByID(ctx, payment.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return invoice.ForTopUp(payment, user), nil
+	})
+}
+
+// issueInvoices numbers, renders and stores each kind of document that is
+// not among the existing ones. If two requests race to issue the same
+// document the loser fails and its number goes unused; asking again returns
+// the winner's document.
+func (h *Handler) issueInvoices(ctx context.Context, existing []*models.Invoice, build func() (*models.Invoice, error)) ([]*models.Invoice, error) {
+	issued := map[string]*models.Invoice{}
+	for _, inv := range existing {
+		issued[inv.Kind] = inv
+	}
+
+	loc, err := time.LoadLocation(h.cfg.FacilityTimezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	invoices := []*models.Invoice{}
+	for _, kind := range invoice.Kinds {
+		if inv, ok := issued[kind]; ok {
+			invoices = append(invoices, inv)
+			continue
+		}
+
+		inv, err := build()
+		if err != nil {
+			return nil, err
+		}
+		inv.Kind = kind
+		inv.IssuedAt = time.Now()
+
+		year := inv.IssuedAt.In(loc).Year()
+		seq, err := h.invoiceRepo.NextSequence(ctx, kind, year)
+		if err != nil {
+			return nil, err
+		}
+		inv.Number = invoice.Number(kind, year, seq)
+
+		if inv.PDF, err = invoice.Render(inv, h.invoiceIssuer(), h.invoiceFont, loc); err != nil {
+			return nil, err
+		}
+
+		err = h.invoiceRepo.Create(ctx, inv)
+		if mongo.IsDuplicateKeyError(err) {
+			log.Printf("Document %s was issued concurrently; number %s is unused", kind, inv.Number)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		inv.PDF = nil
+		invoices = append(invoices, inv)
+	}
+
+	return invoices, nil
+}
+
+// bookingPayerStudentID is the student who paid for a booking, who keeps
+// its documents if the booking is transferred.
+func bookingPayerStudentID(booking *models.Booking) string {
+	if booking.PaidByStudentID != "" {
+		return booking.PaidByStudentID
+	}
+	return booking.StudentID
+}
+
+func (h *Handler) invoiceIssuer() invoice.Issuer {
+	return invoice.Issuer{
+		Name:    h.cfg.InvoiceIssuerName,
+		NameTH:  h.cfg.InvoiceIssuerNameTH,
+		TaxID:   h.cfg.InvoiceTaxID,
+		Address: h.cfg.InvoiceAddress,
+	}
+}
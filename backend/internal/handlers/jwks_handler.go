@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"courtopia-reserve/backend/internal/config"
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+const (
+	sessionTokenLifetime = 24 * time.Hour
+
+	// jwksMaxAge is how long clients may cache the JWKS. A new signing key
+	// is published for twice that before it signs, so verifiers refreshing
+	// on their own schedule already know it.
+	jwksMaxAge     = 5 * time.Minute
+	keyPublishLead = 2 * jwksMaxAge
+)
+
+// KeyRotator keeps the in-memory key set in sync with the signing_keys
+// collection and rotates the asymmetric signing key on schedule. In HS256
+// mode the key set only ever holds JWT_SECRET.
+type KeyRotator struct {
+	repo   *repository.SigningKeyRepository
+	cfg    *config.Config
+	keys   *utils.KeySet
+	legacy *utils.SigningKey
+}
+
+func NewKeyRotator(repo *repository.SigningKeyRepository, cfg *config.Config) *KeyRotator {
+	hmac := utils.NewHMACKey("", cfg.JWTSecret)
+	if cfg.JWTAlgorithm == utils.AlgHS256 {
+		return &KeyRotator{repo: repo, cfg: cfg, keys: utils.NewKeySet(hmac)}
+	}
+
+	rotator := &KeyRotator{repo: repo, cfg: cfg, keys: utils.NewKeySet(nil)}
+	if cfg.JWTSecret != config.DefaultJWTSecret {
+		// Sessions issued with the shared secret before switching to
+		// asymmetric keys stay valid for their remaining lifetime. The
+		// expiry is read from the database on the first rotation.
+		rotator.legacy = hmac
+	}
+
+	return rotator
+}
+
+func (kr *KeyRotator) Keys() *utils.KeySet {
+	return kr.keys
+}
+
+func (kr *KeyRotator) Rotate(ctx context.Context) error {
+	if kr.cfg.JWTAlgorithm == utils.AlgHS256 {
+		return kr.repo.ClearLegacy(ctx)
+	}
+
+	if kr.legacy != nil && kr.legacy.ExpiresAt.IsZero() {
+		expiresAt, err := kr.repo.RetireLegacy(ctx, time.Now().Add(sessionTokenLifetime))
+		if err != nil {
+			return err
+		}
+		kr.legacy.ExpiresAt = expiresAt
+	}
+
+	records, err := kr.repo.FindUsable(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The newest key that is due signs; a newer one that is not yet due is
+	// only published. A key that cannot be decrypted, for example after the
+	// encryption key changed, is replaced rather than left unable to sign.
+	now := time.Now()
+	var active, next *models.SigningKey
+	for _, record := range records {
+		if record.RetiredAt != nil || record.Algorithm != kr.cfg.JWTAlgorithm {
+			continue
+		}
+		if record.ActivatesAt != nil && now.Before(*record.ActivatesAt) {
+			if next == nil {
+				next = record
+			}
+			continue
+		}
+		if _, _, err := kr.openKey(record); err == nil {
+			active = record
+		}
+		break
+	}
+
+	// Without a usable key there is nothing to sign with, so the
+	// replacement signs straight away instead of waiting to be published.
+	rotated := active == nil || active.ActivatesAt != nil
+	if active == nil {
+		active, err = kr.createKey(ctx, nil)
+		if err != nil {
+			return err
+		}
+	} else if next == nil && time.Since(active.CreatedAt) >= kr.cfg.JWTRotationInterval-keyPublishLead {
+		activatesAt := now.Add(keyPublishLead)
+		next, err = kr.createKey(ctx, &activatesAt)
+		if err != nil {
+			return err
+		}
+		if records, err = kr.repo.FindUsable(ctx); err != nil {
+			return err
+		}
+		log.Printf("Published next JWT signing key %s, signing from %s", next.ID, activatesAt.Format(time.RFC3339))
+	}
+
+	if rotated {
+		if err := kr.repo.RetireOthers(ctx, active.ID, now.Add(sessionTokenLifetime)); err != nil {
+			return err
+		}
+		if err := kr.repo.Activate(ctx, active.ID); err != nil {
+			return err
+		}
+		if err := kr.repo.DeleteExpired(ctx); err != nil {
+			log.Printf("Error deleting expired signing keys: %v", err)
+		}
+
+		records, err = kr.repo.FindUsable(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Rotated JWT signing key, new kid %s", active.ID)
+	}
+
+	var activeKey *utils.SigningKey
+	var others []*utils.SigningKey
+	for _, record := range records {
+		key, privatePEM, err := kr.openKey(record)
+		if err != nil {
+			log.Printf("Skipping unreadable signing key %s: %v", record.ID, err)
+			continue
+		}
+		if privatePEM != nil {
+			kr.sealStoredKey(ctx, record.ID, privatePEM)
+		}
+		key.CreatedAt = record.CreatedAt
+		if record.ExpiresAt != nil {
+			key.ExpiresAt = *record.ExpiresAt
+		}
+
+		if record.ID == active.ID {
+			activeKey = key
+		} else {
+			others = append(others, key)
+		}
+	}
+	if kr.legacy != nil && time.Now().Before(kr.legacy.ExpiresAt) {
+		others = append(others, kr.legacy)
+	}
+
+	kr.keys.Replace(activeKey, others...)
+	return nil
+}
+
+// createKey stores a new signing key. A key with activatesAt is published
+// now and starts signing once that time passes.
+func (kr *KeyRotator) createKey(ctx context.Context, activatesAt *time.Time) (*models.SigningKey, error) {
+	key, err := utils.GenerateSigningKey(kr.cfg.JWTAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	privatePEM, err := key.PrivateKeyPEM()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := utils.SealPrivateKey(kr.cfg.JWTKeyEncryptionKey, privatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.SigningKey{
+		ID:          key.ID,
+		Algorithm:   key.Algorithm,
+		PrivateKey:  sealed,
+		CreatedAt:   key.CreatedAt,
+		ActivatesAt: activatesAt,
+	}
+	if err := kr.repo.Create(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// openKey decrypts and parses a stored key. The plain PEM is returned only
+// when the record was not yet encrypted.
+func (kr *KeyRotator) openKey(record *models.SigningKey) (*utils.SigningKey, []byte, error) {
+	privatePEM, sealed, err := utils.OpenPrivateKey(kr.cfg.JWTKeyEncryptionKey, record.PrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := utils.ParseSigningKey(record.ID, record.Algorithm, privatePEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sealed {
+		return key, nil, nil
+	}
+	return key, privatePEM, nil
+}
+
+// sealStoredKey encrypts a key that was stored as plain PEM before private
+// keys were encrypted at rest.
+func (kr *KeyRotator) sealStoredKey(ctx context.Context, id string, privatePEM []byte) {
+	sealed, err := utils.SealPrivateKey(kr.cfg.JWTKeyEncryptionKey, privatePEM)
+	if err == nil {
+		err = kr.repo.SetPrivateKey(ctx, id, sealed)
+	}
+	if err != nil {
+		log.Printf("Error encrypting stored signing key %s: %v", id, err)
+	}
+}
+
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksMaxAge.Seconds())))
+	c.JSON(http.StatusOK, gin.H{"keys": h.keys.JWKS()})
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// GetMembershipPlans lists the plans on sale.
+func (h *Handler) GetMembershipPlans(c *gin.Context) {
+	plans, err := h.membershipRepo.FindAll(c.Request.Context(), true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch membership plans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+func (h *Handler) ListMembershipPlans(c *gin.Context) {
+	plans, err := h.membershipRepo.FindAll(c.Request.Context(), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch membership plans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+func (h *Handler) CreateMembershipPlan(c *gin.Context) {
+	var req models.MembershipPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	plan := &models.MembershipPlan{ID: primitive.NewObjectID(), IsActive: true}
+	if !applyMembershipPlan(c, plan, &req) {
+		return
+	}
+
+	if err := h.membershipRepo.Create(c.Request.Context(), plan); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create membership plan"})
+		return
+	}
+
+	audit(c, "membership_plan.create", "membership_plan", plan.ID.Hex())
+	auditDiff(c, nil, plan)
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// UpdateMembershipPlan changes a plan for future grants only; members keep
+// the terms they were granted.
+func (h *Handler) UpdateMembershipPlan(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid membership plan ID"})
+		return
+	}
+	audit(c, "membership_plan.update", "membership_plan", id.Hex())
+
+	plan, err := h.membershipRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Membership plan not found"})
+		return
+	}
+
+	var req models.MembershipPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	before := *plan
+	if !applyMembershipPlan(c, plan, &req) {
+		return
+	}
+
+	if err := h.membershipRepo.Update(c.Request.Context(), plan); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update membership plan"})
+		return
+	}
+	auditDiff(c, &before, plan)
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// GrantMembership gives a user a membership on a plan, replacing any
+// membership they already have.
+func (h *Handler) GrantMembership(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "user.grant_membership", "user", user.ID.Hex())
+
+	var req models.GrantMembershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	planID, err := primitive.ObjectIDFromHex(req.PlanID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid membership plan ID"})
+		return
+	}
+	plan, err := h.membershipRepo.FindByID(c.Request.Context(), planID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Membership plan not found"})
+		return
+	}
+
+	startsAt := time.Now()
+	if req.StartDate != "" {
+		start, err := h.parseLocalDate(req.StartDate, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date, use YYYY-MM-DD"})
+			return
+		}
+		startsAt = *start
+	}
+	endsAt := startsAt.AddDate(0, 0, plan.DurationDays)
+	if req.EndDate != "" {
+		end, err := h.parseLocalDate(req.EndDate, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date, use YYYY-MM-DD"})
+			return
+		}
+		endsAt = *end
+	}
+	if !endsAt.After(startsAt) || !endsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Membership must end after it starts and in the future"})
+		return
+	}
+
+	membership := &models.Membership{
+		PlanID:              plan.ID,
+		PlanName:            plan.Name,
+		PricingTier:         plan.PricingTier,
+		MaxUpcomingBookings: plan.MaxUpcomingBookings,
+		StartsAt:            startsAt,
+		EndsAt:              endsAt,
+		GrantedBy:           c.MustGet("user").(*utils.Claims).StudentID,
+	}
+
+	update := bson.M{"$set": bson.M{"membership": membership, "updated_at": time.Now()}}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant membership"})
+		return
+	}
+
+	updated := *user
+	updated.Membership = membership
+	auditDiff(c, user, &updated)
+
+	c.JSON(http.StatusOK, membership)
+}
+
+func (h *Handler) RevokeMembership(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "user.revoke_membership", "user", user.ID.Hex())
+
+	if user.Membership == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User has no membership"})
+		return
+	}
+
+	update := bson.M{"$unset": bson.M{"membership": ""}, "$set": bson.M{"updated_at": time.Now()}}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke membership"})
+		return
+	}
+
+	updated := *user
+	updated.Membership = nil
+	auditDiff(c, user, &updated)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Membership revoked"})
+}
+
+func applyMembershipPlan(c *gin.Context, plan *models.MembershipPlan, req *models.MembershipPlanRequest) bool {
+	plan.Name = strings.TrimSpace(req.Name)
+	plan.Description = strings.TrimSpace(req.Description)
+	plan.PricingTier = req.PricingTier
+	plan.DurationDays = req.DurationDays
+	plan.MaxUpcomingBookings = req.MaxUpcomingBookings
+	plan.Price = req.Price
+	if req.IsActive != nil {
+		plan.IsActive = *req.IsActive
+	}
+
+	switch {
+	case plan.Name == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return false
+	case !pricing.Tiers[plan.PricingTier]:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pricing tier must be member or non_member"})
+		return false
+	case plan.DurationDays <= 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Duration must be at least one day"})
+		return false
+	case plan.MaxUpcomingBookings < 0 || plan.Price < 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quota and price must not be negative"})
+		return false
+	}
+	return true
+}
+
+// bookingQuota is the number of upcoming bookings the user may hold at
+// once, or zero for no limit. A membership's quota replaces the default.
+func (h *Handler) bookingQuota(user *models.User) int {
+	if membership := pricing.ActiveMembership(user, time.Now()); membership != nil && membership.MaxUpcomingBookings > 0 {
+		return membership.MaxUpcomingBookings
+	}
+	return h.cfg.BookingQuota
+}
+
+// checkBookingQuota rejects a new booking when the user already holds as
+// many upcoming bookings as their quota allows.
+func (h *Handler) checkBookingQuota(c *gin.Context, user *models.User) bool {
+	quota := h.bookingQuota(user)
+	if quota == 0 {
+		return true
+	}
+
+	facilities, err := h.facilityRepo.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check booking quota"})
+		return false
+	}
+	now := make(map[primitive.ObjectID]time.Time, len(facilities))
+	for _, facility := range facilities {
+		now[facility.ID] = facilityWallClock(facility, time.Now())
+	}
+
+	upcoming, err := h.bookingRepo.CountUpcoming(c.Request.Context(), user.StudentID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check booking quota"})
+		return false
+	}
+	if upcoming >= int64(quota) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You already have " + strconv.FormatInt(upcoming, 10) + " upcoming bookings, the most you can hold at once",
+			"quota": quota,
+		})
+		return false
+	}
+	return true
+}
+
+// SendMembershipReminders emails members whose membership ends within the
+// given number of days, once per membership.
+func SendMembershipReminders(userRepo *repository.UserRepository, notificationRepo *repository.NotificationRepository, days int) {
+	ctx := context.Background()
+	now := time.Now()
+
+	users, err := userRepo.FindMembershipsEndingBefore(ctx, now, now.AddDate(0, 0, days))
+	if err != nil {
+		log.Printf("Error fetching expiring memberships: %v", err)
+		return
+	}
+
+	for _, user := range users {
+		body := fmt.Sprintf(
+			"Dear %s,\n\nYour %s membership ends on %s. Renew it with the student union to keep your member benefits.\n\nThank you for using Courtminton!",
+			user.Name, user.Membership.PlanName, user.Membership.EndsAt.Format("2006-01-02"),
+		)
+
+		if err := deliverEmail(ctx, notificationRepo, user, "membership_renewal", "Your membership is ending soon", body, nil); err != nil {
+			log.Printf("Error sending membership reminder to %s: %v", user.StudentID, err)
+			continue
+		}
+
+		update := bson.M{"$set": bson.M{"membership.reminder_sent_at": now}}
+		if err := userRepo.UpdateOne(ctx, bson.M{"_id": user.ID}, update); err != nil {
+			log.Printf("Error marking membership reminder for %s: %v", user.StudentID, err)
+		}
+	}
+}
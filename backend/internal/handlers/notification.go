@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/repository"
+)
+
+const (
+	smtpHost     = "smtp.gmail.com"
+	smtpAddr     = "smtp.gmail.com:587"
+	smtpUsername = "natthawat48.noi@gmail.com"
+	smtpPassword = "mjxn rpse favy jidl"
+)
+
+func sendEmail(to, subject, body string) error {
+	auth := smtp.PlainAuth("", smtpUsername, smtpPassword, smtpHost)
+
+	msg := []byte(fmt.Sprintf(
+		"To: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		to,
+		subject,
+		body,
+	))
+
+	return smtp.SendMail(smtpAddr, auth, smtpUsername, []string{to}, msg)
+}
+
+// deliverEmail sends an email to a user and records it in the user's
+// notification history whether or not delivery succeeded.
+func deliverEmail(ctx context.Context, notificationRepo *repository.NotificationRepository, user *models.User, kind, subject, body string, bookingID *primitive.ObjectID) error {
+	sendErr := sendEmail(user.Email, subject, body)
+
+	notification := &models.Notification{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		StudentID: user.StudentID,
+		BookingID: bookingID,
+		Type:      kind,
+		Channel:   "email",
+		Recipient: user.Email,
+		Subject:   subject,
+		Body:      body,
+		Status:    "sent",
+	}
+	if sendErr != nil {
+		notification.Status = "failed"
+		notification.Error = sendErr.Error()
+	}
+
+	if err := notificationRepo.Create(ctx, notification); err != nil {
+		log.Printf("Error recording notification for %s: %v", user.StudentID, err)
+	}
+
+	return sendErr
+}
+
+func (h *Handler) notifyStudent(ctx context.Context, studentID, kind, subject, body string, bookingID *primitive.ObjectID) {
+	user, err := h.userRepo.FindByStudentID(ctx, studentID)
+	if err != nil {
+		log.Printf("Error fetching user %s for %s notification: %v", studentID, kind, err)
+		return
+	}
+	if user.Email == "" {
+		return
+	}
+
+	if err := deliverEmail(ctx, h.notificationRepo, user, kind, subject, body, bookingID); err != nil {
+		log.Printf("Error sending %s email to %s: %v", kind, user.Email, err)
+	}
+}
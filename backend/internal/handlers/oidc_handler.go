@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcNonceCookie = "oidc_nonce"
+	oidcCookiePath  = "/api/auth/oidc"
+	oidcLinkCookie  = "oidc_link"
+	oidcCookieTTL   = 10 * 60
+
+	oidcLinkPurpose = "oidc_link"
+)
+
+// errOIDCAccountExists is returned when a new identity maps to the student
+// ID of an existing local account. Identities are only ever linked to an
+// existing account from a signed-in session, never by matching claims.
+var errOIDCAccountExists = errors.New("an account with this student ID already exists")
+
+func (h *Handler) OIDCLogin(c *gin.Context) {
+	if !h.oidc.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	authURL, ok := h.startOIDC(c)
+	if !ok {
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// StartOIDCLink begins a single sign-on round trip that links the identity
+// to the signed-in account instead of logging in. The account is carried
+// through the round trip in a short-lived cookie; the client then sends the
+// browser to the returned authorization URL.
+func (h *Handler) StartOIDCLink(c *gin.Context) {
+	if !h.oidc.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if user.OIDCSubject != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Single sign-on is already linked to this account"})
+		return
+	}
+
+	linkToken, err := utils.GenerateToken(user, h.keys, oidcCookieTTL*time.Second, utils.WithPurpose(oidcLinkPurpose))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	authURL, ok := h.startOIDC(c)
+	if !ok {
+		return
+	}
+	c.SetCookie(oidcLinkCookie, linkToken, oidcCookieTTL, oidcCookiePath, "", h.cfg.Environment == "production", true)
+
+	c.JSON(http.StatusOK, gin.H{"authorizationUrl": authURL})
+}
+
+// startOIDC sets the state and nonce cookies for a new round trip and
+// returns the provider's authorization URL.
+func (h *Handler) startOIDC(c *gin.Context) (string, bool) {
+	state, err := utils.RandomString(24)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server error"})
+		return "", false
+	}
+	nonce, err := utils.RandomString(24)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server error"})
+		return "", false
+	}
+
+	authURL, err := h.oidc.AuthCodeURL(c.Request.Context(), state, nonce)
+	if err != nil {
+		log.Printf("OIDC discovery error: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+		return "", false
+	}
+
+	secure := h.cfg.Environment == "production"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, oidcCookieTTL, oidcCookiePath, "", secure, true)
+	c.SetCookie(oidcNonceCookie, nonce, oidcCookieTTL, oidcCookiePath, "", secure, true)
+	c.SetCookie(oidcLinkCookie, "", -1, oidcCookiePath, "", secure, true)
+
+	return authURL, true
+}
+
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	if !h.oidc.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	if errCode := c.Query("error"); errCode != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on was rejected: " + errCode})
+		return
+	}
+
+	state, _ := c.Cookie(oidcStateCookie)
+	nonce, _ := c.Cookie(oidcNonceCookie)
+	linkToken, _ := c.Cookie(oidcLinkCookie)
+	secure := h.cfg.Environment == "production"
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", secure, true)
+	c.SetCookie(oidcNonceCookie, "", -1, oidcCookiePath, "", secure, true)
+	c.SetCookie(oidcLinkCookie, "", -1, oidcCookiePath, "", secure, true)
+
+	if state == "" || c.Query("state") != state {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired login state"})
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code is required"})
+		return
+	}
+
+	identity, err := h.oidc.Exchange(c.Request.Context(), code, nonce)
+	if err != nil {
+		log.Printf("OIDC code exchange error: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on failed"})
+		return
+	}
+
+	if linkToken != "" {
+		h.linkOIDCIdentity(c, linkToken, identity)
+		return
+	}
+
+	user, err := h.findOrProvisionOIDCUser(c, identity)
+	if err == errOIDCAccountExists {
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this student ID already exists. Sign in with your password and link single sign-on from your profile"})
+		return
+	}
+	if err != nil {
+		log.Printf("OIDC user provisioning error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your account does not provide a student ID"})
+		return
+	}
+
+	if user.Suspended {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+		return
+	}
+
+	response, err := h.loginResponse(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	if h.cfg.OIDCPostLoginRedirect != "" {
+		fragment := url.Values{}
+		if response.MFARequired {
+			fragment.Set("challengeToken", response.ChallengeToken)
+		} else {
+			fragment.Set("token", response.Token)
+		}
+		c.Redirect(http.StatusFound, h.cfg.OIDCPostLoginRedirect+"#"+fragment.Encode())
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// linkOIDCIdentity finishes a round trip started by StartOIDCLink, linking
+// the identity to the account named in the link token.
+func (h *Handler) linkOIDCIdentity(c *gin.Context, linkToken string, identity *utils.OIDCIdentity) {
+	ctx := c.Request.Context()
+
+	claims, err := utils.ValidateToken(linkToken, h.keys)
+	if err != nil || claims.Purpose != oidcLinkPurpose {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired link request"})
+		return
+	}
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired link request"})
+		return
+	}
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.OIDCSubject != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Single sign-on is already linked to this account"})
+		return
+	}
+
+	if _, err := h.userRepo.FindByOIDCSubject(ctx, identity.Issuer, identity.Subject); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "This identity is already linked to another account"})
+		return
+	} else if err != mongo.ErrNoDocuments {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link single sign-on"})
+		return
+	}
+
+	user.OIDCIssuer = identity.Issuer
+	user.OIDCSubject = identity.Subject
+	user.UpdatedAt = time.Now()
+	if err := h.userRepo.Update(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link single sign-on"})
+		return
+	}
+
+	auditActor(c, user)
+	audit(c, "user.oidc.link", "user", user.ID.Hex())
+	auditMeta(c, "issuer", identity.Issuer)
+
+	if h.cfg.OIDCPostLoginRedirect != "" {
+		c.Redirect(http.StatusFound, h.cfg.OIDCPostLoginRedirect+"#linked=true")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Single sign-on linked"})
+}
+
+// findOrProvisionOIDCUser resolves an identity to a local user: first by the
+// linked issuer/subject pair, and otherwise by creating a new password-less
+// user. An unlinked identity whose student ID is already taken is refused
+// with errOIDCAccountExists rather than taking over that account. It returns
+// nil when the identity cannot be mapped to a student ID.
+func (h *Handler) findOrProvisionOIDCUser(c *gin.Context, identity *utils.OIDCIdentity) (*models.User, error) {
+	ctx := c.Request.Context()
+
+	user, err := h.userRepo.FindByOIDCSubject(ctx, identity.Issuer, identity.Subject)
+	if err == nil {
+		return user, nil
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	if identity.StudentID == "" || strings.HasPrefix(identity.StudentID, deletedStudentPrefix) {
+		return nil, nil
+	}
+
+	_, err = h.userRepo.FindByStudentID(ctx, identity.StudentID)
+	if err == nil {
+		return nil, errOIDCAccountExists
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	user = &models.User{
+		ID:          primitive.NewObjectID(),
+		StudentID:   identity.StudentID,
+		Name:        identity.Name,
+		Email:       identity.Email,
+		Role:        "user",
+		OIDCIssuer:  identity.Issuer,
+		OIDCSubject: identity.Subject,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if user.Name == "" {
+		user.Name = identity.StudentID
+	}
+
+	if err := h.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// GetOpenGames lists the games on a facility's board for a day that have
+// not started and still have open spots.
+func (h *Handler) GetOpenGames(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+		return
+	}
+
+	skill := c.Query("skillLevel")
+	switch skill {
+	case "", models.SkillAny, models.SkillBeginner, models.SkillIntermediate, models.SkillAdvanced:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Skill level must be any, beginner, intermediate or advanced"})
+		return
+	}
+	if skill == models.SkillAny {
+		skill = ""
+	}
+
+	facility, ok := h.resolveFacility(c, c.Query("facilityId"))
+	if !ok {
+		return
+	}
+
+	bookings, err := h.bookingRepo.FindOpenGames(c.Request.Context(), facility.ID, date, facilityWallClock(facility, time.Now()), skill)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch open games"})
+		return
+	}
+
+	hosts := map[primitive.ObjectID]string{}
+	games := []models.OpenGameResponse{}
+	for _, booking := range bookings {
+		if booking.StudentID == userClaims.StudentID {
+			continue
+		}
+
+		host, ok := hosts[booking.UserID]
+		if !ok {
+			if user, err := h.userRepo.FindByID(c.Request.Context(), booking.UserID); err == nil {
+				host = user.Name
+			}
+			hosts[booking.UserID] = host
+		}
+
+		players := 1
+		for _, p := range booking.Participants {
+			if p.Status == models.ParticipantAccepted {
+				players++
+			}
+		}
+
+		games = append(games, models.OpenGameResponse{
+			BookingID:       booking.ID.Hex(),
+			FacilityID:      booking.FacilityID.Hex(),
+			CourtNumber:     booking.CourtNumber,
+			BookingDate:     booking.BookingDate.Format("2006-01-02"),
+			StartTime:       booking.StartTime.Format("15:04"),
+			EndTime:         booking.EndTime.Format("15:04"),
+			HostName:        host,
+			SkillLevel:      booking.OpenGame.SkillLevel,
+			SpotsLeft:       booking.OpenGame.Spots,
+			Players:         players,
+			RequireApproval: booking.OpenGame.RequireApproval,
+			Note:            booking.OpenGame.Note,
+			OpenedAt:        booking.OpenGame.OpenedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, games)
+}
+
+// OpenBookingGame puts the caller's booking on the matchmaking board or
+// changes its listing. The open spots cannot exceed the places left on the
+// court.
+func (h *Handler) OpenBookingGame(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	var req models.OpenGameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if booking.StudentID != userClaims.StudentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the booker can open a game"})
+		return
+	}
+	if !h.bookingOpen(c, booking) {
+		return
+	}
+
+	capacity, err := h.bookingCapacity(c.Request.Context(), booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch court"})
+		return
+	}
+	free := capacity - 1
+	for _, p := range booking.Participants {
+		if p.Status != models.ParticipantDeclined {
+			free--
+		}
+	}
+	if req.Spots > free {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Only %d places are left on this court", max(free, 0))})
+		return
+	}
+
+	game := &models.OpenGame{
+		SkillLevel:      req.SkillLevel,
+		Spots:           req.Spots,
+		RequireApproval: req.RequireApproval,
+		Note:            req.Note,
+		OpenedAt:        time.Now(),
+	}
+	if booking.OpenGame != nil {
+		game.OpenedAt = booking.OpenGame.OpenedAt
+	}
+
+	updated, err := h.bookingRepo.SetOpenGame(c.Request.Context(), booking.ID, game)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open game"})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking can no longer be opened"})
+		return
+	}
+
+	audit(c, "booking.open_game.set", "booking", booking.ID.Hex())
+	opened := *booking
+	opened.OpenGame = game
+	auditDiff(c, booking, &opened)
+
+	c.JSON(http.StatusOK, game)
+}
+
+func (h *Handler) CloseBookingGame(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if booking.StudentID != userClaims.StudentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the booker can close a game"})
+		return
+	}
+	if booking.OpenGame == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking is not an open game"})
+		return
+	}
+
+	if err := h.bookingRepo.CloseOpenGame(c.Request.Context(), booking.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close game"})
+		return
+	}
+	audit(c, "booking.open_game.close", "booking", booking.ID.Hex())
+
+	c.JSON(http.StatusOK, gin.H{"message": "Game closed"})
+}
+
+// JoinOpenGame takes one of a game's open spots. The player is on the game
+// straight away unless the host approves players, in which case the spot
+// is held until the host decides.
+func (h *Handler) JoinOpenGame(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if booking.OpenGame == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking is not an open game"})
+		return
+	}
+	if booking.StudentID == user.StudentID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You are hosting this game"})
+		return
+	}
+	if !h.bookingOpen(c, booking) {
+		return
+	}
+
+	now := time.Now()
+	participant := &models.Participant{
+		ID:          primitive.NewObjectID(),
+		UserID:      &user.ID,
+		StudentID:   user.StudentID,
+		Name:        user.Name,
+		ViaOpenGame: true,
+		Status:      models.ParticipantAccepted,
+		AddedBy:     user.StudentID,
+		AddedAt:     now,
+		RespondedAt: &now,
+	}
+	if booking.OpenGame.RequireApproval {
+		participant.Status = models.ParticipantRequested
+		participant.RespondedAt = nil
+	}
+
+	capacity, err := h.bookingCapacity(c.Request.Context(), booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch court"})
+		return
+	}
+
+	joined, err := h.bookingRepo.JoinOpenGame(c.Request.Context(), booking.ID, participant, capacity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join game"})
+		return
+	}
+	if !joined {
+		c.JSON(http.StatusConflict, gin.H{"error": "The game is full or you have already joined it"})
+		return
+	}
+
+	audit(c, "booking.open_game.join", "booking", booking.ID.Hex())
+	auditMeta(c, "participant", participant.ID.Hex())
+
+	slot := bookingSlot(booking)
+	if participant.Status == models.ParticipantRequested {
+		h.notifyStudent(c.Request.Context(), booking.StudentID, "open_game_request", "A player asked to join your game",
+			fmt.Sprintf("%s has asked to join your game on %s.\n\nOpen your bookings to approve or reject the request.", user.Name, slot), &booking.ID)
+	} else {
+		h.notifyStudent(c.Request.Context(), booking.StudentID, "open_game_join", "A player joined your game",
+			fmt.Sprintf("%s has joined your game on %s.", user.Name, slot), &booking.ID)
+	}
+
+	c.JSON(http.StatusCreated, participant)
+}
+
+// LeaveOpenGame takes the caller off a game they joined from the board and
+// gives the spot back.
+func (h *Handler) LeaveOpenGame(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+
+	var participant *models.Participant
+	for _, p := range booking.Participants {
+		if p.StudentID == userClaims.StudentID && p.ViaOpenGame && p.Status != models.ParticipantDeclined {
+			participant = p
+		}
+	}
+	if participant == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "You have not joined this game"})
+		return
+	}
+
+	removed, err := h.bookingRepo.RemoveParticipant(c.Request.Context(), booking.ID, participant.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave game"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "You have not joined this game"})
+		return
+	}
+	h.releaseOpenSpot(c, booking, participant)
+
+	audit(c, "booking.open_game.leave", "booking", booking.ID.Hex())
+	auditMeta(c, "participant", participant.ID.Hex())
+
+	h.notifyStudent(c.Request.Context(), booking.StudentID, "open_game_leave", "A player left your game",
+		fmt.Sprintf("%s has left your game on %s.", participant.Name, bookingSlot(booking)), &booking.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "You have left the game"})
+}
+
+func (h *Handler) ApproveParticipant(c *gin.Context) {
+	h.reviewParticipant(c, models.ParticipantAccepted)
+}
+
+func (h *Handler) RejectParticipant(c *gin.Context) {
+	h.reviewParticipant(c, models.ParticipantDeclined)
+}
+
+// reviewParticipant lets the host decide on a request to join their game.
+// A rejected player's spot goes back on the board.
+func (h *Handler) reviewParticipant(c *gin.Context, status string) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	participantID, err := primitive.ObjectIDFromHex(c.Param("participantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participant ID"})
+		return
+	}
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if booking.StudentID != userClaims.StudentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can review requests"})
+		return
+	}
+
+	var participant *models.Participant
+	for _, p := range booking.Participants {
+		if p.ID == participantID {
+			participant = p
+		}
+	}
+	if participant == nil || participant.Status != models.ParticipantRequested {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending request for this participant"})
+		return
+	}
+	if status == models.ParticipantAccepted && !h.bookingOpen(c, booking) {
+		return
+	}
+
+	updated, err := h.bookingRepo.SetParticipantStatusByID(c.Request.Context(), booking.ID, participantID, models.ParticipantRequested, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review request"})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending request for this participant"})
+		return
+	}
+
+	audit(c, "booking.open_game."+status, "booking", booking.ID.Hex())
+	auditMeta(c, "participant", participantID.Hex())
+
+	slot := bookingSlot(booking)
+	if status == models.ParticipantAccepted {
+		h.notifyStudent(c.Request.Context(), participant.StudentID, "open_game_approved", "Your request to join a game was approved",
+			"You are on the game on "+slot+".", &booking.ID)
+	} else {
+		h.releaseOpenSpot(c, booking, participant)
+		h.notifyStudent(c.Request.Context(), participant.StudentID, "open_game_rejected", "Your request to join a game was declined",
+			"The host has declined your request to join the game on "+slot+".", &booking.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Request " + status})
+}
+
+// releaseOpenSpot returns the spot of a player who came from the board and
+// no longer holds a place.
+func (h *Handler) releaseOpenSpot(c *gin.Context, booking *models.Booking, participant *models.Participant) {
+	if !participant.ViaOpenGame || participant.Status == models.ParticipantDeclined {
+		return
+	}
+	if err := h.bookingRepo.ReleaseOpenSpot(c.Request.Context(), booking.ID); err != nil {
+		log.Printf("Error releasing open spot on booking %s: %v", booking.ID.Hex(), err)
+	}
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// AddParticipant invites a registered student to the caller's booking or
+// adds a named guest. Students hold a place from the invitation until they
+// decline; guests are on the booking straight away.
+func (h *Handler) AddParticipant(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	var req models.AddParticipantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.StudentID = strings.TrimSpace(req.StudentID)
+	req.GuestName = strings.TrimSpace(req.GuestName)
+	if (req.StudentID == "") == (req.GuestName == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either studentId or guestName"})
+		return
+	}
+	if len(req.GuestName) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Guest name is too long"})
+		return
+	}
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if booking.StudentID != userClaims.StudentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the booker can add participants"})
+		return
+	}
+	if !h.bookingOpen(c, booking) {
+		return
+	}
+
+	now := time.Now()
+	participant := &models.Participant{
+		ID:      primitive.NewObjectID(),
+		Status:  models.ParticipantInvited,
+		AddedBy: userClaims.StudentID,
+		AddedAt: now,
+	}
+
+	var invitee *models.User
+	if req.StudentID != "" {
+		if req.StudentID == booking.StudentID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You are already on this booking"})
+			return
+		}
+		user, err := h.userRepo.FindByStudentID(c.Request.Context(), req.StudentID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+			return
+		}
+		invitee = user
+		participant.UserID = &user.ID
+		participant.StudentID = user.StudentID
+		participant.Name = user.Name
+	} else {
+		participant.Name = req.GuestName
+		participant.Guest = true
+		participant.Status = models.ParticipantAccepted
+		participant.RespondedAt = &now
+	}
+
+	capacity, err := h.bookingCapacity(c.Request.Context(), booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch court"})
+		return
+	}
+
+	added, err := h.bookingRepo.AddParticipant(c.Request.Context(), booking.ID, participant, capacity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add participant"})
+		return
+	}
+	if !added {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "The court is full or this student is already on the booking",
+			"capacity": capacity,
+		})
+		return
+	}
+
+	audit(c, "booking.participant.add", "booking", booking.ID.Hex())
+	auditMeta(c, "participant", participant.ID.Hex())
+	if invitee != nil {
+		auditMeta(c, "studentId", invitee.StudentID)
+		body := fmt.Sprintf("%s has invited you to play on %s.\n\nOpen your bookings to accept or decline the invitation.",
+			userClaims.StudentID, bookingSlot(booking))
+		h.notifyStudent(c.Request.Context(), invitee.StudentID, "booking_invitation", "You have been invited to a booking", body, &booking.ID)
+	}
+
+	c.JSON(http.StatusCreated, participant)
+}
+
+// RemoveParticipant takes someone off a booking. The booker can remove
+// anyone; a participant can only remove themselves.
+func (h *Handler) RemoveParticipant(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	participantID, err := primitive.ObjectIDFromHex(c.Param("participantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participant ID"})
+		return
+	}
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+
+	var participant *models.Participant
+	for _, p := range booking.Participants {
+		if p.ID == participantID {
+			participant = p
+		}
+	}
+	isOwner := booking.StudentID == userClaims.StudentID
+	if participant == nil || !isOwner && participant.StudentID != userClaims.StudentID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Participant not found"})
+		return
+	}
+
+	removed, err := h.bookingRepo.RemoveParticipant(c.Request.Context(), booking.ID, participantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove participant"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Participant not found"})
+		return
+	}
+
+	h.releaseOpenSpot(c, booking, participant)
+
+	audit(c, "booking.participant.remove", "booking", booking.ID.Hex())
+	auditMeta(c, "participant", participantID.Hex())
+
+	if participant.StudentID != "" && participant.Status != models.ParticipantDeclined {
+		slot := bookingSlot(booking)
+		if isOwner {
+			h.notifyStudent(c.Request.Context(), participant.StudentID, "booking_participant_removed",
+				"You have been removed from a booking", "You are no longer on the booking for "+slot+".", &booking.ID)
+		} else {
+			h.notifyStudent(c.Request.Context(), booking.StudentID, "booking_participant_left",
+				"A player left your booking", participant.Name+" has left your booking for "+slot+".", &booking.ID)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Participant removed"})
+}
+
+func (h *Handler) AcceptInvitation(c *gin.Context) {
+	h.respondToInvitation(c, models.ParticipantAccepted)
+}
+
+func (h *Handler) DeclineInvitation(c *gin.Context) {
+	h.respondToInvitation(c, models.ParticipantDeclined)
+}
+
+func (h *Handler) respondToInvitation(c *gin.Context, status string) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if status == models.ParticipantAccepted && !h.bookingOpen(c, booking) {
+		return
+	}
+
+	updated, err := h.bookingRepo.SetParticipantStatus(c.Request.Context(), booking.ID, userClaims.StudentID, models.ParticipantInvited, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update invitation"})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending invitation for this booking"})
+		return
+	}
+
+	audit(c, "booking.invitation."+status, "booking", booking.ID.Hex())
+
+	name := userClaims.StudentID
+	if user, err := h.userRepo.FindByStudentID(c.Request.Context(), userClaims.StudentID); err == nil && user.Name != "" {
+		name = user.Name
+	}
+	h.notifyStudent(c.Request.Context(), booking.StudentID, "booking_invitation_"+status,
+		fmt.Sprintf("%s has %s your invitation", name, status),
+		fmt.Sprintf("%s has %s your invitation to play on %s.", name, status, bookingSlot(booking)), &booking.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation " + status})
+}
+
+// notifyParticipants tells every student still on a booking that it has
+// been cancelled.
+func (h *Handler) notifyParticipants(ctx context.Context, booking *models.Booking) {
+	for _, p := range booking.Participants {
+		if p.StudentID == "" || p.Status == models.ParticipantDeclined {
+			continue
+		}
+		h.notifyStudent(ctx, p.StudentID, "booking_cancelled", "A booking you were on was cancelled",
+			"The booking for "+bookingSlot(booking)+" has been cancelled.", &booking.ID)
+	}
+}
+
+// bookingCapacity is how many players, the booker included, may be on the
+// booking's court.
+func (h *Handler) bookingCapacity(ctx context.Context, booking *models.Booking) (int, error) {
+	court, err := h.courtRepo.FindByID(ctx, booking.CourtID)
+	if err != nil {
+		return 0, err
+	}
+	if court.Capacity > 0 {
+		return court.Capacity, nil
+	}
+	return h.cfg.CourtCapacity, nil
+}
+
+func (h *Handler) findParticipantBooking(c *gin.Context) (*models.Booking, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return nil, false
+	}
+
+	booking, err := h.bookingRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return nil, false
+	}
+
+	return booking, true
+}
+
+// bookingOpen rejects changes to bookings that no longer hold their slot or
+// have already started.
+func (h *Handler) bookingOpen(c *gin.Context, booking *models.Booking) bool {
+	if booking.Status != "active" && booking.Status != "pending_payment" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Booking is %s", booking.Status)})
+		return false
+	}
+
+	facility, err := h.facilityRepo.FindByID(c.Request.Context(), booking.FacilityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch facility"})
+		return false
+	}
+	if !facilityInstant(facility, booking.StartTime).After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking has already started"})
+		return false
+	}
+
+	return true
+}
+
+func bookingSlot(booking *models.Booking) string {
+	return fmt.Sprintf("court %d on %s from %s to %s",
+		booking.CourtNumber,
+		booking.BookingDate.Format("2006-01-02"),
+		booking.StartTime.Format("15:04"),
+		booking.EndTime.Format("15:04"),
+	)
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/ledger"
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/payments"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// startPayment puts a priced booking on hold and opens a PromptPay charge
+// for it. The booking is created by the caller.
+func (h *Handler) startPayment(ctx context.Context, booking *models.Booking) (*models.Payment, error) {
+	now := time.Now()
+	holdExpiresAt := now.Add(h.cfg.PaymentHold)
+
+	payment := &models.Payment{
+		ID:        primitive.NewObjectID(),
+		Purpose:   models.PaymentForBooking,
+		BookingID: booking.ID,
+		UserID:    booking.UserID,
+		StudentID: booking.StudentID,
+		Amount:    booking.Price,
+		Currency:  booking.PriceBreakdown.Currency,
+		ExpiresAt: holdExpiresAt,
+	}
+
+	description := fmt.Sprintf("Court %d, %s %s-%s",
+		booking.CourtNumber,
+		booking.BookingDate.Format("2006-01-02"),
+		booking.StartTime.Format("15:04"),
+		booking.EndTime.Format("15:04"),
+	)
+	if err := h.openCharge(ctx, payment, description); err != nil {
+		return nil, err
+	}
+
+	booking.Status = "pending_payment"
+	booking.HoldExpiresAt = &holdExpiresAt
+	booking.PaymentID = &payment.ID
+	booking.PaymentMethod = models.PaymentMethodPromptPay
+
+	return payment, nil
+}
+
+var errPaymentsDisabled = errors.New("online payments are not configured")
+
+// openCharge asks the provider for a PromptPay charge for the payment.
+func (h *Handler) openCharge(ctx context.Context, payment *models.Payment, description string) error {
+	if h.payments == nil {
+		return errPaymentsDisabled
+	}
+
+	charge, err := h.payments.CreateCharge(ctx, payments.ChargeRequest{
+		Reference:   payment.ID.Hex(),
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		Description: description,
+		ExpiresAt:   payment.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	payment.Provider = h.payments.Name()
+	payment.ProviderRef = charge.ProviderRef
+	payment.QRPayload = charge.QRPayload
+	payment.Status = models.PaymentPending
+	return nil
+}
+
+// PaymentWebhook receives payment results from the provider. Events are
+// idempotent: a payment is confirmed at most once, and a payment for a
+// hold that already expired or was cancelled is flagged for a refund.
+func (h *Handler) PaymentWebhook(c *gin.Context) {
+	if h.payments == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Online payments are not configured"})
+		return
+	}
+
+	event, err := h.payments.ParseWebhook(c.Request)
+	if err != nil {
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook signature"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook payload"})
+		return
+	}
+
+	payment, err := h.paymentRepo.FindByProviderRef(c.Request.Context(), h.payments.Name(), event.ProviderRef)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return
+	}
+	audit(c, "payment.webhook", "payment", payment.ID.Hex())
+	auditMeta(c, "event", event.Status)
+
+	switch event.Status {
+	case payments.StatusPaid:
+		if event.Amount != payment.Amount {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Payment amount does not match"})
+			return
+		}
+		if err := h.confirmPayment(c.Request.Context(), payment); err != nil {
+			log.Printf("Error confirming payment %s: %v", payment.ID.Hex(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm payment"})
+			return
+		}
+	case payments.StatusFailed:
+		// The slot stays held until the hold expires.
+		if _, err := h.paymentRepo.SetStatus(c.Request.Context(), payment.ID, models.PaymentFailed, models.PaymentPending); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown payment status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook processed"})
+}
+
+func (h *Handler) confirmPayment(ctx context.Context, payment *models.Payment) error {
+	if payment.Purpose == models.PaymentForWalletTopUp {
+		if err := h.creditTopUp(ctx, payment); err != nil {
+			return err
+		}
+		// Documents that fail to issue here are issued on first request.
+		if paid, err := h.paymentRepo.FindByID(ctx, payment.ID); err == nil {
+			if _, err := h.issueTopUpInvoices(ctx, paid); err != nil && !errors.Is(err, errNotPaid) {
+				log.Printf("Error issuing documents for top-up %s: %v", payment.ID.Hex(), err)
+			}
+		}
+		return nil
+	}
+
+	var confirmed bool
+	err := repository.RunInTransaction(ctx, h.db, func(ctx context.Context) error {
+		confirmed = false
+		now := time.Now()
+
+		// A failed attempt leaves the slot held, so a later successful
+		// one still confirms the booking.
+		paid, err := h.paymentRepo.MarkPaid(ctx, payment.ID, now, models.PaymentPending, models.PaymentFailed)
+		if err != nil {
+			return err
+		}
+		if !paid {
+			flagged, err := h.paymentRepo.FlagRefund(ctx, payment.ID, payment.Amount, models.PaymentExpired, models.PaymentCancelled)
+			if err != nil {
+				return err
+			}
+			if flagged {
+				log.Printf("Payment %s arrived after its hold ended; flagged for refund", payment.ID.Hex())
+			}
+			return nil
+		}
+
+		confirmed, err = h.bookingRepo.ConfirmPayment(ctx, payment.BookingID, now)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			// The hold expired or the booking was cancelled between the
+			// charge being paid and the webhook arriving.
+			if _, err := h.paymentRepo.FlagRefund(ctx, payment.ID, payment.Amount, models.PaymentPaid); err != nil {
+				return err
+			}
+			log.Printf("Payment %s arrived after its hold ended; flagged for refund", payment.ID.Hex())
+		}
+		return nil
+	})
+	if err != nil || !confirmed {
+		return err
+	}
+
+	if booking, err := h.bookingRepo.FindByID(ctx, payment.BookingID); err == nil {
+		h.issueBookingDocuments(ctx, booking)
+	}
+
+	return nil
+}
+
+// creditTopUp adds a paid top-up to the wallet. Top-ups hold no slot, so
+// one paid after its QR code expired is still credited.
+func (h *Handler) creditTopUp(ctx context.Context, payment *models.Payment) error {
+	return repository.RunInTransaction(ctx, h.db, func(ctx context.Context) error {
+		paid, err := h.paymentRepo.MarkPaid(ctx, payment.ID, time.Now(), models.PaymentPending, models.PaymentExpired, models.PaymentFailed)
+		if err != nil || !paid {
+			return err
+		}
+
+		txn := ledger.TopUp(payment)
+		txn.CreatedBy = "payment:" + payment.Provider
+		return h.walletRepo.Post(ctx, txn)
+	})
+}
+
+// GetBookingPayment returns the latest payment of a booking, including the
+// PromptPay QR payload while the payment is pending.
+func (h *Handler) GetBookingPayment(c *gin.Context) {
+	claims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userClaims := claims.(*utils.Claims)
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	booking, err := h.bookingRepo.FindByID(c.Request.Context(), id)
+	if err != nil || booking.StudentID != userClaims.StudentID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+
+	payment, err := h.paymentRepo.FindLatestByBooking(c.Request.Context(), booking.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "This booking has no payment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// ExpirePaymentHolds releases the slots of unpaid bookings once their hold
+// runs out.
+func ExpirePaymentHolds(bookingRepo *repository.BookingRepository, paymentRepo *repository.PaymentRepository, promoRepo *repository.PromoCodeRepository) {
+	now := time.Now()
+
+	expired, err := paymentRepo.ExpireStale(context.Background(), now)
+	if err != nil {
+		log.Printf("Error expiring payments: %v", err)
+	}
+
+	holds, err := bookingRepo.ExpireHolds(context.Background(), now)
+	if err != nil {
+		log.Printf("Error expiring payment holds: %v", err)
+		return
+	}
+
+	for _, id := range holds {
+		if err := promoRepo.Release(context.Background(), id); err != nil {
+			log.Printf("Error releasing promo code for booking %s: %v", id.Hex(), err)
+		}
+	}
+
+	if expired > 0 || len(holds) > 0 {
+		log.Printf("Expired %d payment holds and %d payments", len(holds), expired)
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"courtopia-reserve/backend/internal/config"
+	"courtopia-reserve/backend/internal/payments"
+)
+
+func postWebhook(h *Handler, signature string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/payments/webhook", h.PaymentWebhook)
+
+	body := `{"providerRef":"fake_abc","reference":"64b000000000000000000000","status":"paid","amount":15000}`
+	req := httptest.NewRequest(http.MethodPost, "/api/payments/webhook", strings.NewReader(body))
+	if signature != "" {
+		req.Header.Set(payments.FakeSignatureHeader, signature)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPaymentWebhookDisabled(t *testing.T) {
+	h := &Handler{cfg: &config.Config{}}
+
+	if w := postWebhook(h, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestPaymentWebhookRejectsUnsignedEvents(t *testing.T) {
+	cfg := &config.Config{PaymentProvider: "fake", PromptPayID: "0812345678", PaymentWebhookSecret: "whsec"}
+	h := &Handler{cfg: cfg, payments: newPaymentProvider(cfg)}
+
+	for _, signature := range []string{"", "00", strings.Repeat("ab", 32)} {
+		if w := postWebhook(h, signature); w.Code != http.StatusUnauthorized {
+			t.Errorf("signature %q: status = %d, want %d", signature, w.Code, http.StatusUnauthorized)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+)
+
+func (h *Handler) ListPricingRules(c *gin.Context) {
+	facility, ok := h.resolveFacility(c, c.Query("facilityId"))
+	if !ok || !h.requireFacilityAccess(c, facility.ID) {
+		return
+	}
+
+	rules, err := h.pricingRepo.FindByFacility(c.Request.Context(), facility.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pricing rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"facilityId":          facility.ID,
+		"currency":            pricing.Currency,
+		"weekendSurchargePct": facility.WeekendSurchargePct,
+		"rules":               rules,
+	})
+}
+
+func (h *Handler) CreatePricingRule(c *gin.Context) {
+	var req models.PricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	facility, ok := h.resolveFacility(c, req.FacilityID)
+	if !ok || !h.requireFacilityAccess(c, facility.ID) {
+		return
+	}
+
+	rule := &models.PricingRule{ID: primitive.NewObjectID(), FacilityID: facility.ID}
+	if !h.applyPricingRule(c, rule, &req) {
+		return
+	}
+
+	if err := h.pricingRepo.Create(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create pricing rule"})
+		return
+	}
+
+	audit(c, "pricing_rule.create", "pricing_rule", rule.ID.Hex())
+	auditDiff(c, nil, rule)
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdatePricingRule replaces a rule. Rules stay with their facility.
+func (h *Handler) UpdatePricingRule(c *gin.Context) {
+	rule, ok := h.findPricingRuleParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "pricing_rule.update", "pricing_rule", rule.ID.Hex())
+
+	var req models.PricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	before := *rule
+	if !h.applyPricingRule(c, rule, &req) {
+		return
+	}
+
+	if err := h.pricingRepo.Update(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update pricing rule"})
+		return
+	}
+	auditDiff(c, &before, rule)
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *Handler) DeletePricingRule(c *gin.Context) {
+	rule, ok := h.findPricingRuleParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "pricing_rule.delete", "pricing_rule", rule.ID.Hex())
+
+	if err := h.pricingRepo.Delete(c.Request.Context(), rule.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete pricing rule"})
+		return
+	}
+	auditDiff(c, rule, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pricing rule deleted successfully"})
+}
+
+// UpdateUserPricingTier moves a user between member and non-member rates,
+// for example for staff of partner institutions or outside clubs.
+func (h *Handler) UpdateUserPricingTier(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "user.pricing_tier", "user", user.ID.Hex())
+
+	var req models.UpdatePricingTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if !pricing.Tiers[req.PricingTier] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pricing tier must be member or non_member"})
+		return
+	}
+
+	update := bson.M{"$set": bson.M{"pricing_tier": req.PricingTier, "updated_at": time.Now()}}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update pricing tier"})
+		return
+	}
+
+	updated := *user
+	updated.PricingTier = req.PricingTier
+	auditDiff(c, user, &updated)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pricing tier updated successfully"})
+}
+
+func (h *Handler) findPricingRuleParam(c *gin.Context) (*models.PricingRule, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pricing rule ID"})
+		return nil, false
+	}
+
+	rule, err := h.pricingRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pricing rule not found"})
+		return nil, false
+	}
+
+	if !h.requireFacilityAccess(c, rule.FacilityID) {
+		return nil, false
+	}
+
+	return rule, true
+}
+
+func (h *Handler) applyPricingRule(c *gin.Context, rule *models.PricingRule, req *models.PricingRuleRequest) bool {
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Weekdays = req.Weekdays
+	rule.StartTime = req.StartTime
+	rule.EndTime = req.EndTime
+	rule.MemberRate = req.MemberRate
+	rule.NonMemberRate = req.NonMemberRate
+	rule.Priority = req.Priority
+	rule.CourtID = nil
+
+	if req.CourtID != "" {
+		courtID, err := primitive.ObjectIDFromHex(req.CourtID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court ID"})
+			return false
+		}
+		court, err := h.courtRepo.FindByID(c.Request.Context(), courtID)
+		if err != nil || court.IsRetired || court.FacilityID != rule.FacilityID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Court not found in this facility"})
+			return false
+		}
+		rule.CourtID = &court.ID
+	}
+
+	if err := pricing.ValidateRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// quoteCourts adds a price quote for the slot to each court.
+func (h *Handler) quoteCourts(ctx context.Context, facility *models.Facility, courts []*models.CourtAvailability, tier string, startTime, endTime time.Time) error {
+	rules, err := h.pricingRepo.FindByFacility(ctx, facility.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, court := range courts {
+		court.Quote = pricing.Quote(rules, facility, court.CourtID, tier, startTime, endTime)
+	}
+	return nil
+}
+
+func (h *Handler) quoteBooking(ctx context.Context, facility *models.Facility, court *models.Court, tier string, startTime, endTime time.Time) (*models.PriceBreakdown, error) {
+	rules, err := h.pricingRepo.FindByFacility(ctx, facility.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pricing.Quote(rules, facility, court.ID, tier, startTime, endTime), nil
+}
@@ -41,6 +41,13 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	user, err := h.userRepo.FindByStudentID(c.Request.Context(), claims.StudentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	audit(c, "profile.update", "user", user.ID.Hex())
+
 	filter := bson.M{"student_id": claims.StudentID}
 	update := bson.M{
 		"$set": bson.M{
@@ -50,12 +57,17 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 		},
 	}
 
-	err := h.userRepo.UpdateOne(c.Request.Context(), filter, update)
+	err = h.userRepo.UpdateOne(c.Request.Context(), filter, update)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 		return
 	}
 
+	updated := *user
+	updated.Name = req.Name
+	updated.Email = req.Email
+	auditDiff(c, user, &updated)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Profile updated successfully"})
 }
 
@@ -86,6 +98,7 @@ func (h *Handler) UploadProfilePicture(c *gin.Context) {
 			"profile_picture": fullURL,
 			"updated_at":      time.Now(),
 		},
+		"$push": bson.M{"profile_pictures": filename},
 	}
 
 	if err := h.userRepo.UpdateOne(c.Request.Context(), filter, update); err != nil {
@@ -93,5 +106,8 @@ func (h *Handler) UploadProfilePicture(c *gin.Context) {
 		return
 	}
 
+	audit(c, "profile.picture", "user", claims.Subject)
+	auditMeta(c, "profilePicture", fullURL)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Profile picture uploaded successfully", "profilePicture": fullURL})
 }
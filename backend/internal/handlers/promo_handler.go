@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/promo"
+	"courtopia-reserve/backend/internal/repository"
+)
+
+func (h *Handler) ListPromoCodes(c *gin.Context) {
+	codes, err := h.promoRepo.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promo codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+func (h *Handler) CreatePromoCode(c *gin.Context) {
+	var req models.PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	code := &models.PromoCode{ID: primitive.NewObjectID(), IsActive: true}
+	if !h.applyPromoCode(c, code, &req) {
+		return
+	}
+
+	err := h.promoRepo.Create(c.Request.Context(), code)
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A promo code with this code already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create promo code"})
+		return
+	}
+
+	audit(c, "promo_code.create", "promo_code", code.ID.Hex())
+	auditDiff(c, nil, code)
+
+	c.JSON(http.StatusCreated, code)
+}
+
+// UpdatePromoCode replaces a code's settings. Its usage count is kept, and
+// codes are retired by setting isActive to false rather than deleted so
+// redemptions keep pointing at them.
+func (h *Handler) UpdatePromoCode(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promo code ID"})
+		return
+	}
+	audit(c, "promo_code.update", "promo_code", id.Hex())
+
+	code, err := h.promoRepo.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promo code not found"})
+		return
+	}
+
+	var req models.PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	before := *code
+	if !h.applyPromoCode(c, code, &req) {
+		return
+	}
+
+	err = h.promoRepo.Update(c.Request.Context(), code)
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A promo code with this code already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update promo code"})
+		return
+	}
+	auditDiff(c, &before, code)
+
+	c.JSON(http.StatusOK, code)
+}
+
+func (h *Handler) applyPromoCode(c *gin.Context, code *models.PromoCode, req *models.PromoCodeRequest) bool {
+	code.Code = promo.Normalize(req.Code)
+	code.Description = strings.TrimSpace(req.Description)
+	code.PercentOff = req.PercentOff
+	code.AmountOff = req.AmountOff
+	code.MaxDiscount = req.MaxDiscount
+	code.MaxUses = req.MaxUses
+	code.MaxUsesPerUser = req.MaxUsesPerUser
+	code.FirstBookingOnly = req.FirstBookingOnly
+	code.Tiers = req.Tiers
+	code.Weekdays = req.Weekdays
+	code.StartTime = req.StartTime
+	code.EndTime = req.EndTime
+	code.MinAmount = req.MinAmount
+	if req.IsActive != nil {
+		code.IsActive = *req.IsActive
+	}
+
+	var err error
+	if code.ValidFrom, err = h.parseLocalDate(req.ValidFrom, false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid validFrom date, use YYYY-MM-DD"})
+		return false
+	}
+	if code.ValidUntil, err = h.parseLocalDate(req.ValidUntil, true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid validUntil date, use YYYY-MM-DD"})
+		return false
+	}
+
+	code.FacilityIDs = nil
+	for _, idHex := range req.FacilityIDs {
+		id, err := primitive.ObjectIDFromHex(idHex)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid facility ID"})
+			return false
+		}
+		if _, err := h.facilityRepo.FindByID(c.Request.Context(), id); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Facility not found"})
+			return false
+		}
+		code.FacilityIDs = append(code.FacilityIDs, id)
+	}
+
+	if err := promo.Validate(code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// parseLocalDate reads an optional YYYY-MM-DD date as the start of that day
+// in the default facility timezone, or as the start of the next day when
+// endOfDay is set so the date is inclusive.
+func (h *Handler) parseLocalDate(s string, endOfDay bool) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(h.cfg.FacilityTimezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	d, err := time.ParseInLocation("2006-01-02", s, loc)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		d = d.AddDate(0, 0, 1)
+	}
+	return &d, nil
+}
+
+// applyPromo checks that the promo code applies to a booking starting at
+// startTime and discounts the quote. It does not claim a use of the code.
+func (h *Handler) applyPromo(c *gin.Context, raw string, user *models.User, facility *models.Facility, startTime time.Time, quote *models.PriceBreakdown) (*models.PromoCode, bool) {
+	ctx := c.Request.Context()
+
+	code, err := h.promoRepo.FindByCode(ctx, promo.Normalize(raw))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown promo code"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check promo code"})
+		return nil, false
+	}
+
+	b, err := h.promoBooking(ctx, code, user, facility, startTime, quote)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check promo code"})
+		return nil, false
+	}
+	if err := promo.Check(code, quote, b, time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	promo.Apply(code, quote)
+	return code, true
+}
+
+func (h *Handler) promoBooking(ctx context.Context, code *models.PromoCode, user *models.User, facility *models.Facility, startTime time.Time, quote *models.PriceBreakdown) (promo.Booking, error) {
+	b := promo.Booking{Tier: quote.Tier, FacilityID: facility.ID, Start: startTime}
+
+	var err error
+	if code.FirstBookingOnly {
+		if b.PriorBookings, err = h.bookingRepo.CountPriorBookings(ctx, user.ID); err != nil {
+			return b, err
+		}
+	}
+	if code.MaxUsesPerUser > 0 {
+		if b.Redemptions, err = h.promoRepo.CountRedemptions(ctx, code.ID, user.ID); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+// redeemPromo claims a use of the code for the booking, reporting the error
+// to the client if it cannot.
+func (h *Handler) redeemPromo(c *gin.Context, code *models.PromoCode, booking *models.Booking) bool {
+	err := h.promoRepo.Redeem(c.Request.Context(), code, &models.PromoRedemption{
+		UserID:    booking.UserID,
+		StudentID: booking.StudentID,
+		BookingID: booking.ID,
+		Discount:  booking.PriceBreakdown.Discount,
+	})
+	if errors.Is(err, repository.ErrPromoUsedUp) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Promo code has been used up"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeem promo code"})
+		return false
+	}
+	return true
+}
+
+// releasePromo gives back the promo code use of a booking that was not made
+// or was cancelled.
+func (h *Handler) releasePromo(ctx context.Context, booking *models.Booking) {
+	if booking.PriceBreakdown == nil || booking.PriceBreakdown.PromoCode == "" {
+		return
+	}
+	if err := h.promoRepo.Release(ctx, booking.ID); err != nil {
+		log.Printf("Error releasing promo code for booking %s: %v", booking.ID.Hex(), err)
+	}
+}
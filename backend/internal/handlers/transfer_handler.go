@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// OfferBookingTransfer offers the caller's booking to another student, who
+// has until the offer lapses or the booking starts to accept it.
+func (h *Handler) OfferBookingTransfer(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	var req models.TransferBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.StudentID = strings.TrimSpace(req.StudentID)
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if booking.StudentID != userClaims.StudentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the booker can transfer a booking"})
+		return
+	}
+	if booking.Status != "active" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only confirmed bookings can be transferred"})
+		return
+	}
+	if !h.bookingOpen(c, booking) {
+		return
+	}
+	if req.StudentID == booking.StudentID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You already hold this booking"})
+		return
+	}
+
+	recipient, err := h.userRepo.FindByStudentID(c.Request.Context(), req.StudentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+
+	facility, err := h.facilityRepo.FindByID(c.Request.Context(), booking.FacilityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch facility"})
+		return
+	}
+
+	now := time.Now()
+	transfer := &models.BookingTransfer{
+		ToUserID:    recipient.ID,
+		ToStudentID: recipient.StudentID,
+		ToName:      recipient.Name,
+		OfferedAt:   now,
+		ExpiresAt:   now.Add(h.cfg.BookingTransferTTL),
+	}
+	if start := facilityInstant(facility, booking.StartTime); start.Before(transfer.ExpiresAt) {
+		transfer.ExpiresAt = start
+	}
+
+	offered, err := h.bookingRepo.OfferTransfer(c.Request.Context(), booking.ID, booking.StudentID, transfer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to offer booking"})
+		return
+	}
+	if !offered {
+		c.JSON(http.StatusConflict, gin.H{"error": "Booking can no longer be transferred"})
+		return
+	}
+
+	audit(c, "booking.transfer.offer", "booking", booking.ID.Hex())
+	auditMeta(c, "to", recipient.StudentID)
+
+	body := fmt.Sprintf("%s would like to give you their booking for %s.\n\nAccept it from your bookings before %s or the offer lapses.",
+		userClaims.StudentID, bookingSlot(booking), transfer.ExpiresAt.In(facilityLocation(facility)).Format("2006-01-02 15:04"))
+	h.notifyStudent(c.Request.Context(), recipient.StudentID, "booking_transfer_offer", "A booking has been offered to you", body, &booking.ID)
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *Handler) WithdrawBookingTransfer(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if booking.StudentID != userClaims.StudentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the booker can withdraw a transfer"})
+		return
+	}
+	if booking.Transfer == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No transfer offer for this booking"})
+		return
+	}
+
+	if _, err := h.bookingRepo.ClearTransfer(c.Request.Context(), booking.ID, booking.Transfer.ToStudentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw offer"})
+		return
+	}
+	audit(c, "booking.transfer.withdraw", "booking", booking.ID.Hex())
+	auditMeta(c, "to", booking.Transfer.ToStudentID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer offer withdrawn"})
+}
+
+// GetTransferOffers lists the bookings currently offered to the caller.
+func (h *Handler) GetTransferOffers(c *gin.Context) {
+	userClaims := c.MustGet("user").(*utils.Claims)
+
+	bookings, err := h.bookingRepo.FindTransferOffers(c.Request.Context(), userClaims.StudentID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transfer offers"})
+		return
+	}
+
+	response := []models.BookingResponse{}
+	for _, booking := range bookings {
+		response = append(response, models.BookingResponse{
+			ID:             booking.ID.Hex(),
+			CourtNumber:    booking.CourtNumber,
+			BookingDate:    booking.BookingDate.Format("2006-01-02"),
+			StartTime:      booking.StartTime.Format("15:04"),
+			EndTime:        booking.EndTime.Format("15:04"),
+			Status:         booking.Status,
+			CreatedAt:      booking.CreatedAt,
+			OwnerStudentID: booking.StudentID,
+			Participants:   booking.Participants,
+			Transfer:       booking.Transfer,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AcceptBookingTransfer takes over a booking offered to the caller. The
+// caller must be allowed to book: a booking ban or a full quota stops the
+// transfer just as it would a new booking.
+func (h *Handler) AcceptBookingTransfer(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if booking.Transfer == nil || booking.Transfer.ToStudentID != user.StudentID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No transfer offer for this booking"})
+		return
+	}
+	now := time.Now()
+	if !booking.Transfer.ExpiresAt.After(now) {
+		c.JSON(http.StatusGone, gin.H{"error": "This transfer offer has lapsed"})
+		return
+	}
+	if !h.bookingOpen(c, booking) {
+		return
+	}
+
+	if user.BookingBanUntil != nil && user.BookingBanUntil.After(now) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":    "You cannot take bookings for a while after repeated late cancellations",
+			"banUntil": user.BookingBanUntil,
+		})
+		return
+	}
+	if !h.checkBookingQuota(c, user) {
+		return
+	}
+
+	accepted, err := h.bookingRepo.AcceptTransfer(c.Request.Context(), booking, user, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer booking"})
+		return
+	}
+	if !accepted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Booking can no longer be transferred"})
+		return
+	}
+
+	transferred := *booking
+	transferred.UserID = user.ID
+	transferred.StudentID = user.StudentID
+	transferred.UserEmail = user.Email
+	transferred.Transfer = nil
+	audit(c, "booking.transfer.accept", "booking", booking.ID.Hex())
+	auditDiff(c, booking, &transferred)
+	auditMeta(c, "from", booking.StudentID)
+
+	h.notifyStudent(c.Request.Context(), booking.StudentID, "booking_transfer_accepted", "Your booking was transferred",
+		fmt.Sprintf("%s has accepted your booking for %s. It is now theirs.", user.Name, bookingSlot(booking)), &booking.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "The booking is now yours"})
+}
+
+func (h *Handler) DeclineBookingTransfer(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	booking, ok := h.findParticipantBooking(c)
+	if !ok {
+		return
+	}
+	if booking.Transfer == nil || booking.Transfer.ToStudentID != user.StudentID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No transfer offer for this booking"})
+		return
+	}
+
+	declined, err := h.bookingRepo.ClearTransfer(c.Request.Context(), booking.ID, user.StudentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline offer"})
+		return
+	}
+	if !declined {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No transfer offer for this booking"})
+		return
+	}
+	audit(c, "booking.transfer.decline", "booking", booking.ID.Hex())
+
+	h.notifyStudent(c.Request.Context(), booking.StudentID, "booking_transfer_declined", "Your booking transfer was declined",
+		fmt.Sprintf("%s has declined your booking for %s. It is still yours.", user.Name, bookingSlot(booking)), &booking.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer offer declined"})
+}
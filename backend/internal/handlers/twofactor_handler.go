@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+const (
+	mfaChallengePurpose = "mfa_challenge"
+	recoveryCodeCount   = 10
+
+	// A fresh challenge can be had for the password at any time, so failed
+	// codes are counted per user rather than per challenge.
+	maxTwoFactorAttempts = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+func (h *Handler) VerifyTwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Code == "" && req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	audit(c, "auth.2fa_verify", "user", "")
+
+	claims, err := utils.ValidateToken(req.ChallengeToken, h.keys)
+	if err != nil || claims.Purpose != mfaChallengePurpose {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+
+	user, err := h.userRepo.FindByID(c.Request.Context(), userID)
+	if err != nil || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+	audit(c, "auth.2fa_verify", "user", user.ID.Hex())
+	auditActor(c, user)
+	if req.RecoveryCode != "" {
+		auditMeta(c, "method", "recovery_code")
+	}
+
+	now := time.Now()
+	if user.TOTPLockedUntil != nil && user.TOTPLockedUntil.After(now) {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       "Too many failed verification attempts, try again later",
+			"lockedUntil": user.TOTPLockedUntil,
+		})
+		return
+	}
+
+	if req.RecoveryCode != "" {
+		if !h.consumeRecoveryCode(c, user, req.RecoveryCode) {
+			h.recordTwoFactorFailure(c, user, now)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid recovery code"})
+			return
+		}
+	} else if !h.checkTOTP(c, user, user.TOTPSecret, req.Code) {
+		h.recordTwoFactorFailure(c, user, now)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid verification code"})
+		return
+	}
+
+	if user.TOTPFailedAttempts > 0 || user.TOTPLockedUntil != nil {
+		if err := h.userRepo.ClearTOTPFailures(c.Request.Context(), user.ID); err != nil {
+			log.Printf("Failed to clear two-factor failures for %s: %v", user.StudentID, err)
+		}
+	}
+
+	if !h.checkLoginAllowed(c, user) {
+		return
+	}
+
+	response, err := h.tokenResponse(user, utils.WithMFA())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) GetTwoFactorStatus(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                user.TOTPEnabled,
+		"required":               isAdminRole(user.Role) && h.cfg.AdminRequire2FA,
+		"recoveryCodesRemaining": len(user.RecoveryCodes),
+	})
+}
+
+func (h *Handler) SetupTwoFactor(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	audit(c, "profile.2fa_setup", "user", user.ID.Hex())
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server error"})
+		return
+	}
+
+	update := bson.M{"$set": bson.M{
+		"totp_pending_secret": secret,
+		"updated_at":          time.Now(),
+	}}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":          secret,
+		"provisioningUri": utils.TOTPProvisioningURI(h.cfg.TOTPIssuer, user.StudentID, secret),
+	})
+}
+
+func (h *Handler) EnableTwoFactor(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	audit(c, "profile.2fa_enable", "user", user.ID.Hex())
+
+	if user.TOTPPendingSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor setup has not been started"})
+		return
+	}
+
+	if !h.checkTOTP(c, user, user.TOTPPendingSecret, req.Code) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid verification code"})
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server error"})
+		return
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"totp_enabled":   true,
+			"totp_secret":    user.TOTPPendingSecret,
+			"recovery_codes": hashes,
+			"updated_at":     time.Now(),
+		},
+		"$unset": bson.M{"totp_pending_secret": ""},
+	}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": codes,
+	})
+}
+
+func (h *Handler) DisableTwoFactor(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	audit(c, "profile.2fa_disable", "user", user.ID.Hex())
+
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	if isAdminRole(user.Role) && h.cfg.AdminRequire2FA {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for admin accounts"})
+		return
+	}
+
+	if !h.checkTOTP(c, user, user.TOTPSecret, req.Code) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid verification code"})
+		return
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"totp_enabled": false,
+			"updated_at":   time.Now(),
+		},
+		"$unset": bson.M{
+			"totp_secret":    "",
+			"totp_last_step": "",
+			"recovery_codes": "",
+		},
+	}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	audit(c, "profile.2fa_recovery_codes", "user", user.ID.Hex())
+
+	if !user.TOTPEnabled || !h.checkTOTP(c, user, user.TOTPSecret, req.Code) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid verification code"})
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server error"})
+		return
+	}
+
+	update := bson.M{"$set": bson.M{
+		"recovery_codes": hashes,
+		"updated_at":     time.Now(),
+	}}
+	if err := h.userRepo.UpdateOne(c.Request.Context(), bson.M{"_id": user.ID}, update); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// checkTOTP validates a code and records its time step so the same code
+// cannot be replayed within its validity window.
+func (h *Handler) checkTOTP(c *gin.Context, user *models.User, secret, code string) bool {
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return false
+	}
+
+	recorded, err := h.userRepo.RecordTOTPStep(c.Request.Context(), user.ID, step)
+	if err != nil || !recorded {
+		return false
+	}
+
+	user.TOTPLastStep = step
+	return true
+}
+
+// consumeRecoveryCode spends a matching recovery code. The code is removed
+// atomically, so two requests racing with the same code cannot both pass.
+func (h *Handler) consumeRecoveryCode(c *gin.Context, user *models.User, code string) bool {
+	code = strings.ToLower(strings.TrimSpace(code))
+
+	for i, hash := range user.RecoveryCodes {
+		if !utils.CheckPasswordHash(code, hash) {
+			continue
+		}
+
+		consumed, err := h.userRepo.ConsumeRecoveryCode(c.Request.Context(), user.ID, hash)
+		if err != nil || !consumed {
+			return false
+		}
+
+		user.RecoveryCodes = append(append([]string{}, user.RecoveryCodes[:i]...), user.RecoveryCodes[i+1:]...)
+		return true
+	}
+
+	return false
+}
+
+func (h *Handler) recordTwoFactorFailure(c *gin.Context, user *models.User, now time.Time) {
+	locked, err := h.userRepo.RecordTOTPFailure(c.Request.Context(), user.ID, maxTwoFactorAttempts, now.Add(twoFactorLockout))
+	if err != nil {
+		log.Printf("Failed to record two-factor failure for %s: %v", user.StudentID, err)
+		return
+	}
+	if locked {
+		auditMeta(c, "locked", "true")
+	}
+}
+
+func (h *Handler) currentUser(c *gin.Context) (*models.User, bool) {
+	claims := c.MustGet("user").(*utils.Claims)
+
+	user, err := h.userRepo.FindByStudentID(c.Request.Context(), claims.StudentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	}
+
+	return user, true
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i], err = utils.HashPassword(code)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return codes, hashes, nil
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/ledger"
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+	"courtopia-reserve/backend/internal/repository"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// Top-up limits in satang.
+const (
+	minWalletTopUp = 10000
+	maxWalletTopUp = 5000000
+)
+
+func (h *Handler) GetWallet(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.walletRepo.FindByUser(c.Request.Context(), user, pricing.Currency)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+func (h *Handler) GetWalletStatement(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	h.walletStatement(c, user)
+}
+
+// CreateWalletTopUp opens a PromptPay charge that credits the wallet once
+// the webhook confirms it.
+func (h *Handler) CreateWalletTopUp(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.WalletTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if req.Amount < minWalletTopUp || req.Amount > maxWalletTopUp {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Top-up amount must be between " +
+			pricing.FormatAmount(minWalletTopUp) + " and " + pricing.FormatAmount(maxWalletTopUp) + " THB"})
+		return
+	}
+
+	payment := &models.Payment{
+		ID:        primitive.NewObjectID(),
+		Purpose:   models.PaymentForWalletTopUp,
+		UserID:    user.ID,
+		StudentID: user.StudentID,
+		Amount:    req.Amount,
+		Currency:  pricing.Currency,
+		ExpiresAt: time.Now().Add(h.cfg.PaymentHold),
+	}
+	err := h.openCharge(c.Request.Context(), payment, "Wallet top-up")
+	if errors.Is(err, errPaymentsDisabled) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Online payments are not available"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating top-up charge: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to start payment"})
+		return
+	}
+
+	if err := h.paymentRepo.Create(c.Request.Context(), payment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start payment"})
+		return
+	}
+
+	audit(c, "wallet.topup", "payment", payment.ID.Hex())
+
+	c.JSON(http.StatusCreated, payment)
+}
+
+func (h *Handler) GetUserWalletStatement(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok {
+		return
+	}
+
+	h.walletStatement(c, user)
+}
+
+// AdjustUserWallet credits or debits a wallet by hand. Debits cannot take
+// the balance below zero.
+func (h *Handler) AdjustUserWallet(c *gin.Context) {
+	user, ok := h.findUserParam(c)
+	if !ok {
+		return
+	}
+	audit(c, "wallet.adjust", "user", user.ID.Hex())
+
+	var req models.WalletAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A non-zero amount and a memo are required"})
+		return
+	}
+	memo := strings.TrimSpace(req.Memo)
+	if memo == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A non-zero amount and a memo are required"})
+		return
+	}
+
+	txn := ledger.Adjustment(user, req.Amount, memo)
+	txn.CreatedBy = c.MustGet("user").(*utils.Claims).StudentID
+
+	err := repository.RunInTransaction(c.Request.Context(), h.db, func(ctx context.Context) error {
+		return h.walletRepo.Post(ctx, txn)
+	})
+	if errors.Is(err, repository.ErrInsufficientFunds) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Adjustment would overdraw the wallet"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust wallet"})
+		return
+	}
+
+	auditMeta(c, "amount", strconv.FormatInt(req.Amount, 10))
+	auditMeta(c, "memo", memo)
+
+	c.JSON(http.StatusCreated, txn)
+}
+
+// GetWalletReconciliation checks that every ledger transaction balances and
+// that each stored wallet balance matches the sum of its transactions.
+func (h *Handler) GetWalletReconciliation(c *gin.Context) {
+	from, to, ok := parseLedgerRange(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	report := &models.ReconciliationReport{
+		From:       from,
+		To:         to,
+		Currency:   pricing.Currency,
+		Mismatches: []*models.WalletMismatch{},
+	}
+
+	var err error
+	if report.Accounts, err = h.walletRepo.AccountTotals(ctx, from, to); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to total ledger accounts"})
+		return
+	}
+	if report.Kinds, err = h.walletRepo.KindTotals(ctx, from, to); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to total ledger transactions"})
+		return
+	}
+	if report.UnbalancedTransactions, err = h.walletRepo.CountUnbalanced(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check ledger transactions"})
+		return
+	}
+
+	balances, err := h.walletRepo.LedgerBalances(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to total wallet transactions"})
+		return
+	}
+	wallets, err := h.walletRepo.FindAll(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallets"})
+		return
+	}
+
+	for _, balance := range balances {
+		report.LedgerBalanceTotal += balance
+	}
+
+	report.WalletCount = len(wallets)
+	for _, wallet := range wallets {
+		report.WalletBalanceTotal += wallet.Balance
+		if wallet.Balance != balances[wallet.UserID] {
+			report.Mismatches = append(report.Mismatches, &models.WalletMismatch{
+				UserID:        wallet.UserID,
+				StudentID:     wallet.StudentID,
+				WalletBalance: wallet.Balance,
+				LedgerBalance: balances[wallet.UserID],
+			})
+		}
+		delete(balances, wallet.UserID)
+	}
+	// Ledger transactions for a user without a wallet document.
+	for userID, balance := range balances {
+		report.Mismatches = append(report.Mismatches, &models.WalletMismatch{UserID: userID, LedgerBalance: balance})
+	}
+
+	report.Balanced = report.UnbalancedTransactions == 0 && len(report.Mismatches) == 0
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) walletStatement(c *gin.Context, user *models.User) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	from, to, ok := parseLedgerRange(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	transactions, total, err := h.walletRepo.Statement(ctx, repository.LedgerFilter{
+		UserID: user.ID,
+		From:   from,
+		To:     to,
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch statement"})
+		return
+	}
+
+	statement := &models.WalletStatement{
+		StudentID:    user.StudentID,
+		Currency:     pricing.Currency,
+		Transactions: transactions,
+		Total:        total,
+		Page:         page,
+		Limit:        limit,
+	}
+	if from != nil {
+		if statement.OpeningBalance, err = h.walletRepo.BalanceBefore(ctx, user.ID, *from); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch statement"})
+			return
+		}
+	}
+	if to != nil {
+		statement.ClosingBalance, err = h.walletRepo.BalanceBefore(ctx, user.ID, *to)
+	} else {
+		var wallet *models.Wallet
+		wallet, err = h.walletRepo.FindByUser(ctx, user, pricing.Currency)
+		if wallet != nil {
+			statement.ClosingBalance = wallet.Balance
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch statement"})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// parseLedgerRange reads the optional from and to dates. To is inclusive.
+func parseLedgerRange(c *gin.Context) (*time.Time, *time.Time, bool) {
+	var from, to *time.Time
+	if s := c.Query("from"); s != "" {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, use YYYY-MM-DD"})
+			return nil, nil, false
+		}
+		from = &d
+	}
+	if s := c.Query("to"); s != "" {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, use YYYY-MM-DD"})
+			return nil, nil, false
+		}
+		end := d.AddDate(0, 0, 1)
+		to = &end
+	}
+	return from, to, true
+}
+
+// payFromWallet creates a booking and debits its price from the wallet in
+// one transaction, so neither happens without the other.
+func (h *Handler) payFromWallet(ctx context.Context, booking *models.Booking) error {
+	now := time.Now()
+	booking.PaymentMethod = models.PaymentMethodWallet
+	booking.PaidAt = &now
+
+	return repository.RunInTransaction(ctx, h.db, func(ctx context.Context) error {
+		if err := h.bookingRepo.Create(ctx, booking); err != nil {
+			return err
+		}
+
+		txn := ledger.BookingDebit(booking)
+		txn.CreatedBy = booking.CreatedBy
+		return h.walletRepo.Post(ctx, txn)
+	})
+}
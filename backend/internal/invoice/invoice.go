@@ -0,0 +1,242 @@
+// Package invoice builds invoices and receipts for paid bookings and wallet
+// top-ups and renders them as bilingual Thai and English PDFs.
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+	"courtopia-reserve/backend/pkg/utils"
+)
+
+// Issuer is the organisation named on invoices and receipts.
+type Issuer struct {
+	Name    string
+	NameTH  string
+	TaxID   string
+	Address string
+}
+
+var prefixes = map[string]string{
+	models.InvoiceKindInvoice: "INV",
+	models.InvoiceKindReceipt: "RCT",
+}
+
+var titles = map[string][2]string{
+	models.InvoiceKindInvoice: {"ใบแจ้งหนี้", "INVOICE"},
+	models.InvoiceKindReceipt: {"ใบเสร็จรับเงิน", "RECEIPT"},
+}
+
+// Kinds lists the documents issued for every payment.
+var Kinds = []string{models.InvoiceKindInvoice, models.InvoiceKindReceipt}
+
+// Number formats the seq-th document of a kind issued in a year, for
+// example RCT-2026-000042.
+func Number(kind string, year int, seq int64) string {
+	return fmt.Sprintf("%s-%d-%06d", prefixes[kind], year, seq)
+}
+
+// ForBooking describes a paid booking. Kind, number and issue time are set
+// by the caller.
+func ForBooking(booking *models.Booking, user *models.User, facility *models.Facility) *models.Invoice {
+	inv := newInvoice(user)
+	inv.BookingID = &booking.ID
+	inv.PaymentID = booking.PaymentID
+	inv.PaymentMethod = booking.PaymentMethod
+	if booking.PaidAt != nil {
+		inv.PaidAt = *booking.PaidAt
+	}
+
+	slot := booking.StartTime.Format("15:04") + "-" + booking.EndTime.Format("15:04")
+	courtFee := &models.InvoiceLine{
+		Description: fmt.Sprintf("Court booking: %s court %d, %s %s",
+			facility.Name, booking.CourtNumber, booking.BookingDate.Format("2 Jan 2006"), slot),
+		DescriptionTH: fmt.Sprintf("ค่าจองสนาม: %s สนาม %d วันที่ %s เวลา %s",
+			facility.Name, booking.CourtNumber, thaiDate(booking.BookingDate), slot),
+		Amount: booking.Price,
+	}
+	inv.Lines = append(inv.Lines, courtFee)
+
+	if quote := booking.PriceBreakdown; quote != nil {
+		courtFee.Amount = quote.Subtotal
+		for _, line := range quote.Lines {
+			// Rule lines are already in the court fee and promo lines are
+			// shown as the discount.
+			if line.RuleID == nil && line.Amount > 0 {
+				inv.Lines = append(inv.Lines, &models.InvoiceLine{
+					Description:   line.Label,
+					DescriptionTH: "ค่าบริการเพิ่มวันหยุดสุดสัปดาห์",
+					Amount:        line.Amount,
+				})
+			}
+		}
+		inv.Discount = quote.Discount
+	}
+
+	for _, line := range inv.Lines {
+		inv.Subtotal += line.Amount
+	}
+	inv.Total = booking.Price
+	return inv
+}
+
+// ForTopUp describes a paid wallet top-up.
+func ForTopUp(payment *models.Payment, user *models.User) *models.Invoice {
+	inv := newInvoice(user)
+	inv.PaymentID = &payment.ID
+	inv.PaymentMethod = models.PaymentMethodPromptPay
+	if payment.PaidAt != nil {
+		inv.PaidAt = *payment.PaidAt
+	}
+	inv.Lines = []*models.InvoiceLine{{
+		Description:   "Wallet top-up",
+		DescriptionTH: "เติมเงินเข้ากระเป๋าเงิน",
+		Amount:        payment.Amount,
+	}}
+	inv.Subtotal = payment.Amount
+	inv.Total = payment.Amount
+	return inv
+}
+
+func newInvoice(user *models.User) *models.Invoice {
+	return &models.Invoice{
+		UserID:        user.ID,
+		StudentID:     user.StudentID,
+		CustomerName:  user.Name,
+		CustomerEmail: user.Email,
+		Currency:      pricing.Currency,
+	}
+}
+
+// Render draws the document on an A4 page. Thai text is only drawn when
+// the font has Thai glyphs; otherwise the document is in English only.
+// Times are shown in loc.
+func Render(inv *models.Invoice, issuer Issuer, font *utils.TrueTypeFont, loc *time.Location) ([]byte, error) {
+	doc := utils.NewPDFDocument(font)
+	doc.AddPage()
+
+	label := func(th, en string) string {
+		if th != "" && doc.CanRender(th) {
+			return th + " / " + en
+		}
+		return en
+	}
+
+	const left, right = 50.0, utils.PDFPageWidth - 50
+	y := utils.PDFPageHeight - 60
+
+	if issuer.NameTH != "" && doc.CanRender(issuer.NameTH) {
+		doc.Text(left, y, 14, issuer.NameTH)
+		y -= 18
+	}
+	doc.Text(left, y, 14, issuer.Name)
+	if issuer.Address != "" {
+		y -= 15
+		doc.Text(left, y, 9, fit(doc, issuer.Address, 9, 300))
+	}
+	y -= 15
+	doc.Text(left, y, 9, label("เลขประจำตัวผู้เสียภาษี", "Tax ID")+": "+issuer.TaxID)
+
+	title := titles[inv.Kind]
+	top := utils.PDFPageHeight - 60
+	doc.TextRight(right, top, 16, label(title[0], title[1]))
+	doc.TextRight(right, top-20, 10, label("เลขที่", "No.")+" "+inv.Number)
+	doc.TextRight(right, top-35, 10, label("วันที่", "Date")+" "+formatDate(doc, inv.IssuedAt.In(loc)))
+
+	y -= 30
+	doc.Line(left, y, right, y, 0.5)
+	y -= 20
+	doc.Text(left, y, 10, label("ลูกค้า", "Customer")+": "+fit(doc, inv.CustomerName, 10, 380))
+	y -= 15
+	doc.Text(left, y, 10, label("รหัสนักศึกษา", "Student ID")+": "+inv.StudentID)
+	if inv.CustomerEmail != "" {
+		y -= 15
+		doc.Text(left, y, 10, label("อีเมล", "Email")+": "+inv.CustomerEmail)
+	}
+
+	y -= 30
+	doc.FillRect(left, y-6, right-left, 20, 0.9)
+	doc.Text(left+5, y, 10, label("รายการ", "Description"))
+	doc.TextRight(right-5, y, 10, label("จำนวนเงิน", "Amount")+" ("+inv.Currency+")")
+
+	for _, line := range inv.Lines {
+		y -= 22
+		doc.Text(left+5, y, 10, fit(doc, line.Description, 10, 380))
+		doc.TextRight(right-5, y, 10, pricing.FormatAmount(line.Amount))
+		if line.DescriptionTH != "" && doc.CanRender(line.DescriptionTH) {
+			y -= 14
+			doc.Text(left+5, y, 9, fit(doc, line.DescriptionTH, 9, 380))
+		}
+	}
+
+	y -= 14
+	doc.Line(left, y, right, y, 0.5)
+	totals := [][3]string{{"รวม", "Subtotal", pricing.FormatAmount(inv.Subtotal)}}
+	if inv.Discount > 0 {
+		totals = append(totals, [3]string{"ส่วนลด", "Discount", pricing.FormatAmount(-inv.Discount)})
+	}
+	totals = append(totals, [3]string{"ยอดสุทธิ", "Total", pricing.FormatAmount(inv.Total)})
+	for _, t := range totals {
+		y -= 18
+		doc.TextRight(right-120, y, 10, label(t[0], t[1]))
+		doc.TextRight(right-5, y, 10, t[2])
+	}
+
+	y -= 40
+	doc.Text(left, y, 10, label("ชำระโดย", "Paid by")+": "+paymentMethod(doc, inv.PaymentMethod))
+	y -= 15
+	doc.Text(left, y, 10, label("วันที่ชำระ", "Paid on")+": "+formatDate(doc, inv.PaidAt.In(loc))+" "+inv.PaidAt.In(loc).Format("15:04"))
+	if inv.Kind == models.InvoiceKindInvoice {
+		y -= 15
+		doc.Text(left, y, 10, label("สถานะ", "Status")+": "+label("ชำระแล้ว", "Paid in full"))
+	}
+
+	doc.Text(left, 50, 8, label("เอกสารนี้ออกโดยระบบอิเล็กทรอนิกส์", "This document was issued electronically and is valid without a signature."))
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func paymentMethod(doc *utils.PDFDocument, method string) string {
+	switch method {
+	case models.PaymentMethodWallet:
+		if doc.CanRender("กระเป๋าเงิน") {
+			return "กระเป๋าเงิน / Wallet"
+		}
+		return "Wallet"
+	case models.PaymentMethodPromptPay:
+		return "PromptPay"
+	}
+	return method
+}
+
+// formatDate writes both the Thai Buddhist-era date and the English date
+// when Thai can be rendered.
+func formatDate(doc *utils.PDFDocument, t time.Time) string {
+	if doc.CanRender("กขค") {
+		return thaiDate(t) + " (" + t.Format("2 Jan 2006") + ")"
+	}
+	return t.Format("2 Jan 2006")
+}
+
+func thaiDate(t time.Time) string {
+	return fmt.Sprintf("%d/%d/%d", t.Day(), t.Month(), t.Year()+543)
+}
+
+// fit shortens s with an ellipsis so it is at most width points wide.
+func fit(doc *utils.PDFDocument, s string, size, width float64) string {
+	if doc.TextWidth(s, size) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && doc.TextWidth(string(r)+"...", size) > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "..."
+}
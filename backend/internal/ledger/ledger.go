@@ -0,0 +1,113 @@
+// Package ledger builds the double-entry transactions behind user wallets.
+// Every transaction debits and credits the same total, so the accounts
+// always sum to zero and can be reconciled against the stored balances.
+package ledger
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+)
+
+// Ledger accounts. Wallet balances are liabilities owed to users, cash is
+// money received through top-ups, revenue is court fees earned and payouts
+// are balances owed back to people who closed their account.
+const (
+	AccountWallet      = "wallet"
+	AccountCash        = "cash"
+	AccountRevenue     = "revenue:bookings"
+	AccountAdjustments = "adjustments"
+	AccountPayouts     = "payouts"
+)
+
+var ErrUnbalanced = errors.New("ledger transaction does not balance")
+
+// TopUp credits a wallet with money received through a payment.
+func TopUp(payment *models.Payment) *models.LedgerTransaction {
+	txn := newTransaction(models.LedgerTopUp, payment.UserID, payment.StudentID, payment.Amount, AccountCash, AccountWallet)
+	txn.PaymentID = &payment.ID
+	return txn
+}
+
+// BookingDebit pays for a booking from the wallet.
+func BookingDebit(booking *models.Booking) *models.LedgerTransaction {
+	txn := newTransaction(models.LedgerBookingDebit, booking.UserID, booking.StudentID, -booking.Price, AccountWallet, AccountRevenue)
+	txn.BookingID = &booking.ID
+	return txn
+}
+
+// RefundCredit returns part or all of a booking's price to the wallet.
+func RefundCredit(booking *models.Booking, amount int64) *models.LedgerTransaction {
+	// Refunds go back to whoever paid, even if the booking was transferred.
+	userID, studentID := booking.UserID, booking.StudentID
+	if booking.PaidByUserID != nil {
+		userID, studentID = *booking.PaidByUserID, booking.PaidByStudentID
+	}
+	txn := newTransaction(models.LedgerRefundCredit, userID, studentID, amount, AccountRevenue, AccountWallet)
+	txn.BookingID = &booking.ID
+	return txn
+}
+
+// Adjustment corrects a wallet by a signed amount, for example to undo a
+// mistaken charge or to grant goodwill credit.
+func Adjustment(user *models.User, amount int64, memo string) *models.LedgerTransaction {
+	var txn *models.LedgerTransaction
+	if amount >= 0 {
+		txn = newTransaction(models.LedgerAdjustment, user.ID, user.StudentID, amount, AccountAdjustments, AccountWallet)
+	} else {
+		txn = newTransaction(models.LedgerAdjustment, user.ID, user.StudentID, amount, AccountWallet, AccountAdjustments)
+	}
+	txn.Memo = memo
+	return txn
+}
+
+// Payout empties a wallet into the payouts account when its owner closes
+// their account, leaving staff to pay the amount back by hand.
+func Payout(user *models.User, amount int64) *models.LedgerTransaction {
+	return newTransaction(models.LedgerPayout, user.ID, user.StudentID, -amount, AccountWallet, AccountPayouts)
+}
+
+// Validate checks that a transaction is balanced and that its wallet
+// entries add up to its amount.
+func Validate(txn *models.LedgerTransaction) error {
+	var debits, credits, wallet int64
+	for _, entry := range txn.Entries {
+		if entry.Debit < 0 || entry.Credit < 0 {
+			return ErrUnbalanced
+		}
+		debits += entry.Debit
+		credits += entry.Credit
+		if entry.Account == AccountWallet {
+			wallet += entry.Credit - entry.Debit
+		}
+	}
+	if debits == 0 || debits != credits || wallet != txn.Amount {
+		return ErrUnbalanced
+	}
+	return nil
+}
+
+// newTransaction moves the absolute amount from the debit account to the
+// credit account.
+func newTransaction(kind string, userID primitive.ObjectID, studentID string, amount int64, debit, credit string) *models.LedgerTransaction {
+	abs := amount
+	if abs < 0 {
+		abs = -abs
+	}
+
+	return &models.LedgerTransaction{
+		ID:        primitive.NewObjectID(),
+		Kind:      kind,
+		UserID:    userID,
+		StudentID: studentID,
+		Amount:    amount,
+		Currency:  pricing.Currency,
+		Entries: []models.LedgerEntry{
+			{Account: debit, Debit: abs},
+			{Account: credit, Credit: abs},
+		},
+	}
+}
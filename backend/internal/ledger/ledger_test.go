@@ -0,0 +1,99 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+func TestTransactionsBalance(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID(), StudentID: "6512345678"}
+	payer := primitive.NewObjectID()
+	booking := &models.Booking{ID: primitive.NewObjectID(), UserID: user.ID, StudentID: user.StudentID, Price: 30000}
+	transferred := &models.Booking{ID: primitive.NewObjectID(), UserID: user.ID, StudentID: user.StudentID, Price: 30000,
+		PaidByUserID: &payer, PaidByStudentID: "6500000001"}
+	payment := &models.Payment{ID: primitive.NewObjectID(), UserID: user.ID, StudentID: user.StudentID, Amount: 50000}
+
+	tests := []struct {
+		name       string
+		txn        *models.LedgerTransaction
+		wantAmount int64
+		wantUser   primitive.ObjectID
+		wantDebit  string
+		wantCredit string
+		wantWallet int64
+	}{
+		{name: "top-up", txn: TopUp(payment), wantAmount: 50000, wantUser: user.ID, wantDebit: AccountCash, wantCredit: AccountWallet, wantWallet: 50000},
+		{name: "booking debit", txn: BookingDebit(booking), wantAmount: -30000, wantUser: user.ID, wantDebit: AccountWallet, wantCredit: AccountRevenue, wantWallet: -30000},
+		{name: "refund", txn: RefundCredit(booking, 15000), wantAmount: 15000, wantUser: user.ID, wantDebit: AccountRevenue, wantCredit: AccountWallet, wantWallet: 15000},
+		{name: "refund of transferred booking", txn: RefundCredit(transferred, 30000), wantAmount: 30000, wantUser: payer, wantDebit: AccountRevenue, wantCredit: AccountWallet, wantWallet: 30000},
+		{name: "credit adjustment", txn: Adjustment(user, 2500, "goodwill"), wantAmount: 2500, wantUser: user.ID, wantDebit: AccountAdjustments, wantCredit: AccountWallet, wantWallet: 2500},
+		{name: "debit adjustment", txn: Adjustment(user, -2500, "mistaken top-up"), wantAmount: -2500, wantUser: user.ID, wantDebit: AccountWallet, wantCredit: AccountAdjustments, wantWallet: -2500},
+		{name: "payout", txn: Payout(user, 12000), wantAmount: -12000, wantUser: user.ID, wantDebit: AccountWallet, wantCredit: AccountPayouts, wantWallet: -12000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.txn); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if tt.txn.Amount != tt.wantAmount {
+				t.Errorf("Amount = %d, want %d", tt.txn.Amount, tt.wantAmount)
+			}
+			if tt.txn.UserID != tt.wantUser {
+				t.Errorf("UserID = %s, want %s", tt.txn.UserID.Hex(), tt.wantUser.Hex())
+			}
+
+			var wallet int64
+			for _, entry := range tt.txn.Entries {
+				if entry.Debit > 0 && entry.Account != tt.wantDebit {
+					t.Errorf("debited %s, want %s", entry.Account, tt.wantDebit)
+				}
+				if entry.Credit > 0 && entry.Account != tt.wantCredit {
+					t.Errorf("credited %s, want %s", entry.Account, tt.wantCredit)
+				}
+				if entry.Account == AccountWallet {
+					wallet += entry.Credit - entry.Debit
+				}
+			}
+			if wallet != tt.wantWallet {
+				t.Errorf("wallet moved by %d, want %d", wallet, tt.wantWallet)
+			}
+		})
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		entries []models.LedgerEntry
+	}{
+		{name: "no entries", amount: 0},
+		{name: "zero amounts", amount: 0, entries: []models.LedgerEntry{{Account: AccountCash}, {Account: AccountWallet}}},
+		{name: "debits exceed credits", amount: 100, entries: []models.LedgerEntry{
+			{Account: AccountCash, Debit: 200},
+			{Account: AccountWallet, Credit: 100},
+		}},
+		{name: "negative entry", amount: -100, entries: []models.LedgerEntry{
+			{Account: AccountCash, Debit: -100},
+			{Account: AccountWallet, Credit: -100},
+		}},
+		{name: "wallet does not match amount", amount: 50, entries: []models.LedgerEntry{
+			{Account: AccountCash, Debit: 100},
+			{Account: AccountWallet, Credit: 100},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := &models.LedgerTransaction{Amount: tt.amount, Entries: tt.entries}
+			if err := Validate(txn); !errors.Is(err, ErrUnbalanced) {
+				t.Errorf("Validate error = %v, want ErrUnbalanced", err)
+			}
+		})
+	}
+}
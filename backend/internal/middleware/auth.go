@@ -9,7 +9,7 @@ import (
 	"courtopia-reserve/backend/pkg/utils"
 )
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+func AuthMiddleware(keys *utils.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -25,8 +25,8 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := utils.ValidateToken(bearerToken[1], jwtSecret)
-		if err != nil {
+		claims, err := utils.ValidateToken(bearerToken[1], keys)
+		if err != nil || claims.Purpose != "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
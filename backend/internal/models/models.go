@@ -14,22 +14,69 @@ type User struct {
 	Email     string             `bson:"email,omitempty" json:"email,omitempty"` 
 	Role      string             `bson:"role" json:"role"`                       
 	ProfilePicture string        `bson:"profile_picture,omitempty" json:"profilePicture,omitempty"` 
+	// ProfilePictures names every file the user has uploaded, so they can
+	// all be removed when the account is deleted.
+	ProfilePictures []string     `bson:"profile_pictures,omitempty" json:"-"`
+	OIDCIssuer     string        `bson:"oidc_issuer,omitempty" json:"-"`
+	OIDCSubject    string        `bson:"oidc_subject,omitempty" json:"-"`
+	TOTPEnabled       bool       `bson:"totp_enabled" json:"totpEnabled"`
+	TOTPSecret        string     `bson:"totp_secret,omitempty" json:"-"`
+	TOTPPendingSecret string     `bson:"totp_pending_secret,omitempty" json:"-"`
+	TOTPLastStep      int64      `bson:"totp_last_step,omitempty" json:"-"`
+	RecoveryCodes     []string   `bson:"recovery_codes,omitempty" json:"-"`
+	TOTPFailedAttempts int       `bson:"totp_failed_attempts,omitempty" json:"-"`
+	TOTPLockedUntil   *time.Time `bson:"totp_locked_until,omitempty" json:"-"`
+	Suspended         bool       `bson:"suspended" json:"suspended"`
+	SuspendedAt       *time.Time `bson:"suspended_at,omitempty" json:"suspendedAt,omitempty"`
+	SuspendReason     string     `bson:"suspend_reason,omitempty" json:"suspendReason,omitempty"`
+	MustResetPassword bool       `bson:"must_reset_password" json:"mustResetPassword"`
+	PricingTier       string     `bson:"pricing_tier,omitempty" json:"pricingTier,omitempty"`
+	CancelStrikes     []time.Time `bson:"cancel_strikes,omitempty" json:"cancelStrikes,omitempty"`
+	BookingBanUntil   *time.Time `bson:"booking_ban_until,omitempty" json:"bookingBanUntil,omitempty"`
+	Membership        *Membership `bson:"membership,omitempty" json:"membership,omitempty"`
+	PasswordResetHash string     `bson:"password_reset_hash,omitempty" json:"-"`
+	PasswordResetExp  *time.Time `bson:"password_reset_expires,omitempty" json:"-"`
+	TokensValidAfter  *time.Time `bson:"tokens_valid_after,omitempty" json:"-"`
 	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
 	UpdatedAt time.Time          `bson:"updated_at" json:"updatedAt"`
 }
 
+type Facility struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string               `bson:"name" json:"name"`
+	Address     string               `bson:"address,omitempty" json:"address,omitempty"`
+	Timezone    string               `bson:"timezone" json:"timezone"`
+	OpeningTime string               `bson:"opening_time" json:"openingTime"`
+	ClosingTime string               `bson:"closing_time" json:"closingTime"`
+	AdminIDs    []primitive.ObjectID `bson:"admin_ids" json:"adminIds"`
+	IsDefault   bool                 `bson:"is_default" json:"isDefault"`
+	// WeekendSurchargePct is added on top of the hourly rates on Saturdays
+	// and Sundays.
+	WeekendSurchargePct int       `bson:"weekend_surcharge_pct" json:"weekendSurchargePct"`
+	CreatedAt           time.Time `bson:"created_at" json:"createdAt"`
+	UpdatedAt           time.Time `bson:"updated_at" json:"updatedAt"`
+}
+
 type Court struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	FacilityID  primitive.ObjectID `bson:"facility_id" json:"facilityId"`
 	CourtNumber int                `bson:"court_number" json:"courtNumber"` 
 	Name        string             `bson:"name" json:"name"`
 	IsActive    bool               `bson:"is_active" json:"isActive"`                    
 	Location    string             `bson:"location,omitempty" json:"location,omitempty"` 
+	Attributes  map[string]string  `bson:"attributes,omitempty" json:"attributes,omitempty"`
+	IsRetired   bool               `bson:"is_retired" json:"isRetired"`
+	Capacity    int                `bson:"capacity,omitempty" json:"capacity,omitempty"`
+	RetiredAt   *time.Time         `bson:"retired_at,omitempty" json:"retiredAt,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at,omitempty" json:"createdAt,omitempty"`
+	UpdatedAt   time.Time          `bson:"updated_at,omitempty" json:"updatedAt,omitempty"`
 }
 
 type Booking struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
 	UserID      primitive.ObjectID `bson:"user_id" json:"userId"`
 	StudentID   string             `bson:"student_id" json:"studentId"`
+	FacilityID  primitive.ObjectID `bson:"facility_id" json:"facilityId"`
 	CourtID     primitive.ObjectID `bson:"court_id" json:"courtId"`
 	CourtNumber int                `bson:"court_number" json:"courtNumber"`
 	BookingDate time.Time          `bson:"booking_date" json:"bookingDate"` 
@@ -40,8 +87,31 @@ type Booking struct {
 	UpdatedAt   time.Time          `bson:"updated_at" json:"updatedAt"`
 	NotificationSent bool `bson:"notification_sent"`
 	UserEmail        string             `bson:"user_email" json:"userEmail"`
+	GuestName        string             `bson:"guest_name,omitempty" json:"guestName,omitempty"`
+	CreatedBy        string             `bson:"created_by,omitempty" json:"createdBy,omitempty"`
+	Channel          string             `bson:"channel,omitempty" json:"channel,omitempty"`
+	Price            int64              `bson:"price" json:"price"`
+	PriceBreakdown   *PriceBreakdown    `bson:"price_breakdown,omitempty" json:"priceBreakdown,omitempty"`
+	PaymentID        *primitive.ObjectID `bson:"payment_id,omitempty" json:"paymentId,omitempty"`
+	HoldExpiresAt    *time.Time         `bson:"hold_expires_at,omitempty" json:"holdExpiresAt,omitempty"`
+	PaidAt           *time.Time         `bson:"paid_at,omitempty" json:"paidAt,omitempty"`
+	PaymentMethod    string             `bson:"payment_method,omitempty" json:"paymentMethod,omitempty"`
+	Cancellation     *CancellationOutcome `bson:"cancellation,omitempty" json:"cancellation,omitempty"`
+	Participants     []*Participant     `bson:"participants,omitempty" json:"participants,omitempty"`
+	OpenGame         *OpenGame          `bson:"open_game,omitempty" json:"openGame,omitempty"`
+	Transfer         *BookingTransfer   `bson:"transfer,omitempty" json:"transfer,omitempty"`
+	PaidByUserID     *primitive.ObjectID `bson:"paid_by_user_id,omitempty" json:"-"`
+	PaidByStudentID  string             `bson:"paid_by_student_id,omitempty" json:"paidByStudentId,omitempty"`
+	Equipment        []*BookingEquipment `bson:"equipment,omitempty" json:"equipment,omitempty"`
 }
 
+// Booking channels. Bookings made before channels were recorded have none
+// and count as self-service.
+const (
+	ChannelSelfService = "self_service"
+	ChannelDesk        = "desk"
+)
+
 type RegisterRequest struct {
 	StudentID string `json:"studentId" binding:"required"`
 	Password  string `json:"password" binding:"required"`
@@ -55,17 +125,32 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token     string `json:"token"`
-	StudentID string `json:"studentId"`
-	Name      string `json:"name"`
-	Role      string `json:"role"`
+	Token          string `json:"token,omitempty"`
+	StudentID      string `json:"studentId"`
+	Name           string `json:"name"`
+	Role           string `json:"role"`
+	MFARequired    bool   `json:"mfaRequired,omitempty"`
+	ChallengeToken string `json:"challengeToken,omitempty"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code,omitempty"`
+	RecoveryCode   string `json:"recoveryCode,omitempty"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
 }
 
 type BookingRequest struct {
+	FacilityID  string `json:"facilityId,omitempty"`
 	CourtNumber int    `json:"courtNumber" binding:"required"`
 	BookingDate string `json:"bookingDate" binding:"required"` 
 	StartTime   string `json:"startTime" binding:"required"`   
 	EndTime     string `json:"endTime" binding:"required"`     
+	PaymentMethod string `json:"paymentMethod,omitempty"`
+	PromoCode     string `json:"promoCode,omitempty"`
 }
 
 type BookingResponse struct {
@@ -76,23 +161,871 @@ type BookingResponse struct {
 	EndTime     string    `json:"endTime"`     
 	Status      string    `json:"status"`
 	CreatedAt   time.Time `json:"createdAt"`
+	Price       int64           `json:"price"`
+	PriceBreakdown *PriceBreakdown `json:"priceBreakdown,omitempty"`
+	HoldExpiresAt  *time.Time      `json:"holdExpiresAt,omitempty"`
+	PaymentMethod  string          `json:"paymentMethod,omitempty"`
+	Payment        *Payment        `json:"payment,omitempty"`
+	Invoices       []*Invoice      `json:"invoices,omitempty"`
+	Role           string          `json:"role,omitempty"`
+	OwnerStudentID string          `json:"ownerStudentId,omitempty"`
+	Participants   []*Participant  `json:"participants,omitempty"`
+	OpenGame       *OpenGame       `json:"openGame,omitempty"`
+	Transfer       *BookingTransfer `json:"transfer,omitempty"`
+	Equipment      []*BookingEquipment `json:"equipment,omitempty"`
 }
 
 type AvailabilityRequest struct {
+	FacilityID  string `json:"facilityId,omitempty"`
 	CourtNumber int    `json:"courtNumber,omitempty"`         
 	BookingDate string `json:"bookingDate" binding:"required"` 
 	StartTime   string `json:"startTime" binding:"required"`   
 	EndTime     string `json:"endTime" binding:"required"`    
+	PromoCode   string `json:"promoCode,omitempty"`
 }
 
 type CourtAvailability struct {
-	CourtNumber int  `json:"courtNumber"`
-	IsAvailable bool `json:"isAvailable"`
+	CourtID     primitive.ObjectID `json:"courtId"`
+	CourtNumber int                `json:"courtNumber"`
+	IsAvailable bool               `json:"isAvailable"`
+	Quote       *PriceBreakdown    `json:"quote,omitempty"`
 }
 
 type AvailabilityResponse struct {
+	FacilityID  primitive.ObjectID   `json:"facilityId"`
 	BookingDate string               `json:"bookingDate"`
 	StartTime   string               `json:"startTime"`
 	EndTime     string               `json:"endTime"`
 	Courts      []*CourtAvailability `json:"courts"` 
 }
+
+type APIToken struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"-"`
+	StudentID  string             `bson:"student_id" json:"-"`
+	Name       string             `bson:"name" json:"name"`
+	Prefix     string             `bson:"prefix" json:"prefix"`
+	TokenHash  string             `bson:"token_hash" json:"-"`
+	Scopes     []string           `bson:"scopes" json:"scopes"`
+	ExpiresAt  time.Time          `bson:"expires_at" json:"expiresAt"`
+	LastUsedAt *time.Time         `bson:"last_used_at,omitempty" json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty" json:"revokedAt,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expiresInDays,omitempty"`
+}
+
+type CreateAPITokenResponse struct {
+	Token    string    `json:"token"`
+	APIToken *APIToken `json:"apiToken"`
+}
+
+type Notification struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"-"`
+	StudentID string              `bson:"student_id" json:"-"`
+	BookingID *primitive.ObjectID `bson:"booking_id,omitempty" json:"bookingId,omitempty"`
+	Type      string              `bson:"type" json:"type"`
+	Channel   string              `bson:"channel" json:"channel"`
+	Recipient string              `bson:"recipient" json:"recipient"`
+	Subject   string              `bson:"subject" json:"subject"`
+	Body      string              `bson:"body" json:"body"`
+	Status    string              `bson:"status" json:"status"`
+	Error     string              `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"createdAt"`
+}
+
+type DeleteAccountRequest struct {
+	StudentID string `json:"studentId" binding:"required"`
+	Password  string `json:"password,omitempty"`
+}
+
+type AccountExport struct {
+	ExportedAt    time.Time       `json:"exportedAt"`
+	Profile       *User           `json:"profile"`
+	Bookings      []*Booking      `json:"bookings"`
+	Notifications []*Notification `json:"notifications"`
+	APITokens     []*APIToken     `json:"apiTokens"`
+}
+
+type SigningKey struct {
+	ID         string     `bson:"_id" json:"kid"`
+	Algorithm  string     `bson:"algorithm" json:"algorithm"`
+	PrivateKey string     `bson:"private_key" json:"-"`
+	CreatedAt  time.Time  `bson:"created_at" json:"createdAt"`
+	// ActivatesAt is set on a key published ahead of rotation; it only
+	// starts signing once this time has passed.
+	ActivatesAt *time.Time `bson:"activates_at,omitempty" json:"activatesAt,omitempty"`
+	RetiredAt   *time.Time `bson:"retired_at,omitempty" json:"retiredAt,omitempty"`
+	ExpiresAt   *time.Time `bson:"expires_at,omitempty" json:"expiresAt,omitempty"`
+}
+
+type CreateCourtRequest struct {
+	FacilityID  string            `json:"facilityId,omitempty"`
+	CourtNumber int               `json:"courtNumber" binding:"required,min=1"`
+	Name        string            `json:"name" binding:"required"`
+	Location    string            `json:"location,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	IsActive    *bool             `json:"isActive,omitempty"`
+	Capacity    int               `json:"capacity,omitempty" binding:"omitempty,min=1,max=50"`
+}
+
+type UpdateCourtRequest struct {
+	CourtNumber *int              `json:"courtNumber,omitempty" binding:"omitempty,min=1"`
+	Name        *string           `json:"name,omitempty"`
+	Location    *string           `json:"location,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	Capacity    *int              `json:"capacity,omitempty" binding:"omitempty,min=1,max=50"`
+}
+
+type UserSearchResponse struct {
+	Users []*User `json:"users"`
+	Total int64   `json:"total"`
+	Page  int     `json:"page"`
+	Limit int     `json:"limit"`
+}
+
+type BookingListResponse struct {
+	Bookings []*Booking `json:"bookings"`
+	Total    int64      `json:"total"`
+	Page     int        `json:"page"`
+	Limit    int        `json:"limit"`
+}
+
+type DeskBookingRequest struct {
+	FacilityID  string `json:"facilityId,omitempty"`
+	StudentID   string `json:"studentId,omitempty"`
+	GuestName   string `json:"guestName,omitempty"`
+	CourtNumber int    `json:"courtNumber" binding:"required"`
+	BookingDate string `json:"bookingDate" binding:"required"`
+	StartTime   string `json:"startTime" binding:"required"`
+	EndTime     string `json:"endTime" binding:"required"`
+}
+
+type BookingFilterRequest struct {
+	FacilityID  string `json:"facilityId,omitempty"`
+	CourtNumber int    `json:"courtNumber,omitempty"`
+	StudentID   string `json:"studentId,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Channel     string `json:"channel,omitempty"`
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+}
+
+type BulkBookingRequest struct {
+	Action            string               `json:"action" binding:"required"`
+	Filter            BookingFilterRequest `json:"filter"`
+	TargetCourtNumber int                  `json:"targetCourtNumber,omitempty"`
+	Reason            string               `json:"reason"`
+	DryRun            bool                 `json:"dryRun"`
+}
+
+type BulkBookingSkip struct {
+	BookingID primitive.ObjectID `json:"bookingId"`
+	Reason    string             `json:"reason"`
+}
+
+type BulkBookingResponse struct {
+	Action   string             `json:"action"`
+	DryRun   bool               `json:"dryRun"`
+	Matched  int                `json:"matched"`
+	Affected []*Booking         `json:"affected"`
+	Skipped  []*BulkBookingSkip `json:"skipped"`
+}
+
+type UserBookingStats struct {
+	Total        int64            `bson:"total" json:"total"`
+	ByStatus     map[string]int64 `bson:"-" json:"byStatus"`
+	BookedHours  float64          `bson:"booked_hours" json:"bookedHours"`
+	FirstBooking *time.Time       `bson:"first_booking,omitempty" json:"firstBooking,omitempty"`
+	LastBooking  *time.Time       `bson:"last_booking,omitempty" json:"lastBooking,omitempty"`
+}
+
+type AdminUserResponse struct {
+	User  *User             `json:"user"`
+	Stats *UserBookingStats `json:"stats"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=8"`
+}
+
+type UtilizationEntry struct {
+	FacilityID     primitive.ObjectID `json:"facilityId"`
+	CourtID        primitive.ObjectID `json:"courtId"`
+	CourtNumber    int                `json:"courtNumber"`
+	Period         string             `json:"period"`
+	BookedMinutes  float64            `json:"bookedMinutes"`
+	OpenMinutes    int                `json:"openMinutes"`
+	UtilizationPct float64            `json:"utilizationPct"`
+}
+
+type HeatmapCell struct {
+	Weekday  int   `bson:"weekday" json:"weekday"`
+	Hour     int   `bson:"hour" json:"hour"`
+	Bookings int64 `bson:"bookings" json:"bookings"`
+}
+
+type AnalyticsSummary struct {
+	From             string           `json:"from"`
+	To               string           `json:"to"`
+	TotalBookings    int64            `json:"totalBookings"`
+	ByStatus         map[string]int64 `json:"byStatus"`
+	ByChannel        map[string]int64 `json:"byChannel"`
+	UniqueUsers      int64            `json:"uniqueUsers"`
+	BookedHours      float64          `json:"bookedHours"`
+	CancellationRate float64          `json:"cancellationRate"`
+	NoShowRate       float64          `json:"noShowRate"`
+}
+
+type TopBooker struct {
+	StudentID   string  `bson:"_id" json:"studentId"`
+	Name        string  `bson:"name" json:"name"`
+	Bookings    int64   `bson:"bookings" json:"bookings"`
+	BookedHours float64 `bson:"booked_hours" json:"bookedHours"`
+}
+
+type AuditChange struct {
+	Field  string      `bson:"field" json:"field"`
+	Before interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After  interface{} `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+type AuditLog struct {
+	ID                    primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Timestamp             time.Time           `bson:"timestamp" json:"timestamp"`
+	ActorID               *primitive.ObjectID `bson:"actor_id,omitempty" json:"actorId,omitempty"`
+	ActorStudentID        string              `bson:"actor_student_id,omitempty" json:"actorStudentId,omitempty"`
+	ActorRole             string              `bson:"actor_role,omitempty" json:"actorRole,omitempty"`
+	ViaAPIToken           bool                `bson:"via_api_token,omitempty" json:"viaApiToken,omitempty"`
+	ImpersonatorID        *primitive.ObjectID `bson:"impersonator_id,omitempty" json:"impersonatorId,omitempty"`
+	ImpersonatorStudentID string              `bson:"impersonator_student_id,omitempty" json:"impersonatorStudentId,omitempty"`
+	Action                string              `bson:"action" json:"action"`
+	Method                string              `bson:"method" json:"method"`
+	Path                  string              `bson:"path" json:"path"`
+	Status                int                 `bson:"status" json:"status"`
+	TargetType            string              `bson:"target_type,omitempty" json:"targetType,omitempty"`
+	TargetID              string              `bson:"target_id,omitempty" json:"targetId,omitempty"`
+	Changes               []*AuditChange      `bson:"changes,omitempty" json:"changes,omitempty"`
+	Metadata              map[string]string   `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	IP                    string              `bson:"ip" json:"ip"`
+	UserAgent             string              `bson:"user_agent,omitempty" json:"userAgent,omitempty"`
+	RequestID             string              `bson:"request_id" json:"requestId"`
+}
+
+type AuditLogResponse struct {
+	Entries []*AuditLog `json:"entries"`
+	Total   int64       `json:"total"`
+	Page    int         `json:"page"`
+	Limit   int         `json:"limit"`
+}
+
+type Announcement struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Title        string              `bson:"title" json:"title"`
+	Body         string              `bson:"body" json:"body"`
+	Severity     string              `bson:"severity" json:"severity"`
+	CourtID      *primitive.ObjectID `bson:"court_id,omitempty" json:"courtId,omitempty"`
+	VisibleFrom  time.Time           `bson:"visible_from" json:"visibleFrom"`
+	VisibleUntil *time.Time          `bson:"visible_until,omitempty" json:"visibleUntil,omitempty"`
+	CreatedBy    string              `bson:"created_by" json:"createdBy"`
+	CreatedAt    time.Time           `bson:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time           `bson:"updated_at" json:"updatedAt"`
+}
+
+type CreateAnnouncementRequest struct {
+	Title        string     `json:"title" binding:"required"`
+	Body         string     `json:"body" binding:"required"`
+	Severity     string     `json:"severity,omitempty"`
+	CourtID      string     `json:"courtId,omitempty"`
+	VisibleFrom  *time.Time `json:"visibleFrom,omitempty"`
+	VisibleUntil *time.Time `json:"visibleUntil,omitempty"`
+}
+
+type UpdateAnnouncementRequest struct {
+	Title        *string    `json:"title,omitempty"`
+	Body         *string    `json:"body,omitempty"`
+	Severity     *string    `json:"severity,omitempty"`
+	CourtID      *string    `json:"courtId,omitempty"`
+	VisibleFrom  *time.Time `json:"visibleFrom,omitempty"`
+	VisibleUntil *time.Time `json:"visibleUntil,omitempty"`
+}
+
+type CourtDetailResponse struct {
+	*Court
+	Notices []*Announcement `json:"notices"`
+}
+
+type ImpersonateRequest struct {
+	Reason           string `json:"reason" binding:"required"`
+	AllowDestructive bool   `json:"allowDestructive"`
+	Minutes          int    `json:"minutes,omitempty"`
+}
+
+type ImpersonateResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	StudentID        string    `json:"studentId"`
+	Name             string    `json:"name"`
+	Role             string    `json:"role"`
+	ImpersonatedBy   string    `json:"impersonatedBy"`
+	AllowDestructive bool      `json:"allowDestructive"`
+}
+
+type CreateFacilityRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Address     string `json:"address,omitempty"`
+	Timezone    string `json:"timezone,omitempty"`
+	OpeningTime string `json:"openingTime,omitempty"`
+	ClosingTime string `json:"closingTime,omitempty"`
+
+	WeekendSurchargePct int `json:"weekendSurchargePct,omitempty" binding:"min=0,max=100"`
+}
+
+type UpdateFacilityRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Address     *string `json:"address,omitempty"`
+	Timezone    *string `json:"timezone,omitempty"`
+	OpeningTime *string `json:"openingTime,omitempty"`
+	ClosingTime *string `json:"closingTime,omitempty"`
+
+	WeekendSurchargePct *int `json:"weekendSurchargePct,omitempty" binding:"omitempty,min=0,max=100"`
+}
+
+type FacilityAdminsRequest struct {
+	StudentIDs []string `json:"studentIds"`
+}
+
+// PricingRule sets the hourly rates for a time band at a facility, or at
+// one of its courts. Amounts are in satang (1/100 baht).
+type PricingRule struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	FacilityID    primitive.ObjectID  `bson:"facility_id" json:"facilityId"`
+	CourtID       *primitive.ObjectID `bson:"court_id,omitempty" json:"courtId,omitempty"`
+	Name          string              `bson:"name" json:"name"`
+	Weekdays      []int               `bson:"weekdays,omitempty" json:"weekdays,omitempty"`
+	StartTime     string              `bson:"start_time" json:"startTime"`
+	EndTime       string              `bson:"end_time" json:"endTime"`
+	MemberRate    int64               `bson:"member_rate" json:"memberRate"`
+	NonMemberRate int64               `bson:"non_member_rate" json:"nonMemberRate"`
+	Priority      int                 `bson:"priority" json:"priority"`
+	CreatedAt     time.Time           `bson:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time           `bson:"updated_at" json:"updatedAt"`
+}
+
+type PricingRuleRequest struct {
+	FacilityID    string `json:"facilityId,omitempty"`
+	CourtID       string `json:"courtId,omitempty"`
+	Name          string `json:"name" binding:"required"`
+	Weekdays      []int  `json:"weekdays,omitempty"`
+	StartTime     string `json:"startTime" binding:"required"`
+	EndTime       string `json:"endTime" binding:"required"`
+	MemberRate    int64  `json:"memberRate" binding:"min=0"`
+	NonMemberRate int64  `json:"nonMemberRate" binding:"min=0"`
+	Priority      int    `json:"priority"`
+}
+
+type PriceLine struct {
+	Label       string              `bson:"label" json:"label"`
+	RuleID      *primitive.ObjectID `bson:"rule_id,omitempty" json:"ruleId,omitempty"`
+	StartTime   string              `bson:"start_time,omitempty" json:"startTime,omitempty"`
+	EndTime     string              `bson:"end_time,omitempty" json:"endTime,omitempty"`
+	Minutes     int                 `bson:"minutes,omitempty" json:"minutes,omitempty"`
+	RatePerHour int64               `bson:"rate_per_hour,omitempty" json:"ratePerHour,omitempty"`
+	Amount      int64               `bson:"amount" json:"amount"`
+}
+
+type PriceBreakdown struct {
+	Currency  string       `bson:"currency" json:"currency"`
+	Tier      string       `bson:"tier" json:"tier"`
+	Lines     []*PriceLine `bson:"lines" json:"lines"`
+	Subtotal  int64        `bson:"subtotal" json:"subtotal"`
+	Discount  int64        `bson:"discount,omitempty" json:"discount,omitempty"`
+	PromoCode string       `bson:"promo_code,omitempty" json:"promoCode,omitempty"`
+	Total     int64        `bson:"total" json:"total"`
+}
+
+type UpdatePricingTierRequest struct {
+	PricingTier string `json:"pricingTier" binding:"required"`
+}
+
+// Payment statuses. A payment that arrives after its hold expired cannot
+// reinstate the booking and is flagged for a refund instead.
+const (
+	PaymentPending   = "pending"
+	PaymentPaid      = "paid"
+	PaymentFailed    = "failed"
+	PaymentExpired   = "expired"
+	PaymentCancelled = "cancelled"
+	PaymentRefundDue = "refund_due"
+)
+
+// Payment methods for priced bookings. Bookings without one were paid by
+// PromptPay.
+const (
+	PaymentMethodPromptPay = "promptpay"
+	PaymentMethodWallet    = "wallet"
+)
+
+// Payment purposes. Payments without one are for a booking.
+const (
+	PaymentForBooking     = "booking"
+	PaymentForWalletTopUp = "wallet_topup"
+)
+
+type Payment struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Purpose      string             `bson:"purpose,omitempty" json:"purpose,omitempty"`
+	BookingID    primitive.ObjectID `bson:"booking_id,omitempty" json:"bookingId,omitempty"`
+	UserID       primitive.ObjectID `bson:"user_id" json:"userId"`
+	StudentID    string             `bson:"student_id" json:"studentId"`
+	Amount       int64              `bson:"amount" json:"amount"`
+	Currency     string             `bson:"currency" json:"currency"`
+	Provider     string             `bson:"provider" json:"provider"`
+	ProviderRef  string             `bson:"provider_ref" json:"providerRef"`
+	RefundAmount int64              `bson:"refund_amount,omitempty" json:"refundAmount,omitempty"`
+	QRPayload    string             `bson:"qr_payload" json:"qrPayload"`
+	Status       string             `bson:"status" json:"status"`
+	ExpiresAt    time.Time          `bson:"expires_at" json:"expiresAt"`
+	PaidAt       *time.Time         `bson:"paid_at,omitempty" json:"paidAt,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+// Ledger transaction kinds.
+const (
+	LedgerTopUp        = "topup"
+	LedgerBookingDebit = "booking_debit"
+	LedgerRefundCredit = "refund_credit"
+	LedgerAdjustment   = "adjustment"
+	LedgerPayout       = "payout"
+)
+
+type Wallet struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"userId"`
+	StudentID string             `bson:"student_id" json:"studentId"`
+	Balance   int64              `bson:"balance" json:"balance"`
+	Currency  string             `bson:"currency" json:"currency"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+// LedgerEntry is one side of a ledger transaction. The "wallet" account is
+// the control account for all wallets; the transaction's user identifies
+// the wallet.
+type LedgerEntry struct {
+	Account string `bson:"account" json:"account"`
+	Debit   int64  `bson:"debit" json:"debit"`
+	Credit  int64  `bson:"credit" json:"credit"`
+}
+
+// LedgerTransaction is an immutable, balanced set of ledger entries. Amount
+// is the signed change to the user's wallet.
+type LedgerTransaction struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	Kind         string              `bson:"kind" json:"kind"`
+	UserID       primitive.ObjectID  `bson:"user_id" json:"userId"`
+	StudentID    string              `bson:"student_id" json:"studentId"`
+	Amount       int64               `bson:"amount" json:"amount"`
+	BalanceAfter int64               `bson:"balance_after" json:"balanceAfter"`
+	Currency     string              `bson:"currency" json:"currency"`
+	Entries      []LedgerEntry       `bson:"entries" json:"entries"`
+	BookingID    *primitive.ObjectID `bson:"booking_id,omitempty" json:"bookingId,omitempty"`
+	PaymentID    *primitive.ObjectID `bson:"payment_id,omitempty" json:"paymentId,omitempty"`
+	Memo         string              `bson:"memo,omitempty" json:"memo,omitempty"`
+	CreatedBy    string              `bson:"created_by" json:"createdBy"`
+	CreatedAt    time.Time           `bson:"created_at" json:"createdAt"`
+}
+
+type WalletTopUpRequest struct {
+	Amount int64 `json:"amount" binding:"required"`
+}
+
+type WalletAdjustmentRequest struct {
+	Amount int64  `json:"amount" binding:"required"`
+	Memo   string `json:"memo" binding:"required"`
+}
+
+type WalletStatement struct {
+	StudentID      string               `json:"studentId"`
+	Currency       string               `json:"currency"`
+	OpeningBalance int64                `json:"openingBalance"`
+	ClosingBalance int64                `json:"closingBalance"`
+	Transactions   []*LedgerTransaction `json:"transactions"`
+	Total          int64                `json:"total"`
+	Page           int                  `json:"page"`
+	Limit          int                  `json:"limit"`
+}
+
+type LedgerAccountTotal struct {
+	Account string `bson:"_id" json:"account"`
+	Debit   int64  `bson:"debit" json:"debit"`
+	Credit  int64  `bson:"credit" json:"credit"`
+}
+
+type LedgerKindTotal struct {
+	Kind   string `bson:"_id" json:"kind"`
+	Count  int64  `bson:"count" json:"count"`
+	Amount int64  `bson:"amount" json:"amount"`
+}
+
+type WalletMismatch struct {
+	UserID        primitive.ObjectID `json:"userId"`
+	StudentID     string             `json:"studentId"`
+	WalletBalance int64              `json:"walletBalance"`
+	LedgerBalance int64              `json:"ledgerBalance"`
+}
+
+// ReconciliationReport checks the ledger against itself and against the
+// stored wallet balances. Totals cover the period; balances are current.
+type ReconciliationReport struct {
+	From                   *time.Time            `json:"from,omitempty"`
+	To                     *time.Time            `json:"to,omitempty"`
+	Currency               string                `json:"currency"`
+	Accounts               []*LedgerAccountTotal `json:"accounts"`
+	Kinds                  []*LedgerKindTotal    `json:"kinds"`
+	UnbalancedTransactions int64                 `json:"unbalancedTransactions"`
+	WalletCount            int                   `json:"walletCount"`
+	WalletBalanceTotal     int64                 `json:"walletBalanceTotal"`
+	LedgerBalanceTotal     int64                 `json:"ledgerBalanceTotal"`
+	Mismatches             []*WalletMismatch     `json:"mismatches"`
+	Balanced               bool                  `json:"balanced"`
+}
+
+// Cancellation windows, from earliest to latest. Exempt cancellations are
+// made by staff on a student's behalf and carry no penalty.
+const (
+	CancelWindowFree    = "free"
+	CancelWindowPartial = "partial"
+	CancelWindowLate    = "late"
+	CancelExempt        = "exempt"
+)
+
+// CancellationOutcome is what the cancellation policy decides for a booking.
+// It is shown as a preview before cancelling and stored on the booking.
+type CancellationOutcome struct {
+	Window       string     `bson:"window" json:"window"`
+	RefundPct    int        `bson:"refund_pct" json:"refundPct"`
+	RefundAmount int64      `bson:"refund_amount" json:"refundAmount"`
+	Fee          int64      `bson:"fee" json:"fee"`
+	Strike       bool       `bson:"strike" json:"strike"`
+	Strikes      int        `bson:"strikes,omitempty" json:"strikes,omitempty"`
+	BanUntil     *time.Time `bson:"ban_until,omitempty" json:"banUntil,omitempty"`
+	CancelledBy  string     `bson:"cancelled_by,omitempty" json:"cancelledBy,omitempty"`
+	CancelledAt  *time.Time `bson:"cancelled_at,omitempty" json:"cancelledAt,omitempty"`
+}
+
+type CancellationPreview struct {
+	BookingID            primitive.ObjectID   `json:"bookingId"`
+	Price                int64                `json:"price"`
+	Cancellation         *CancellationOutcome `json:"cancellation"`
+	RequiresConfirmation bool                 `json:"requiresConfirmation"`
+}
+
+// MembershipPlan is a membership the student union sells, such as a
+// semester pass. Members get the plan's pricing tier and booking quota.
+type MembershipPlan struct {
+	ID                  primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name                string             `bson:"name" json:"name"`
+	Description         string             `bson:"description,omitempty" json:"description,omitempty"`
+	PricingTier         string             `bson:"pricing_tier" json:"pricingTier"`
+	DurationDays        int                `bson:"duration_days" json:"durationDays"`
+	MaxUpcomingBookings int                `bson:"max_upcoming_bookings,omitempty" json:"maxUpcomingBookings,omitempty"`
+	Price               int64              `bson:"price" json:"price"`
+	IsActive            bool               `bson:"is_active" json:"isActive"`
+	CreatedAt           time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt           time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+// Membership is a user's copy of a plan, so later plan changes do not
+// affect memberships already granted.
+type Membership struct {
+	PlanID              primitive.ObjectID `bson:"plan_id" json:"planId"`
+	PlanName            string             `bson:"plan_name" json:"planName"`
+	PricingTier         string             `bson:"pricing_tier" json:"pricingTier"`
+	MaxUpcomingBookings int                `bson:"max_upcoming_bookings,omitempty" json:"maxUpcomingBookings,omitempty"`
+	StartsAt            time.Time          `bson:"starts_at" json:"startsAt"`
+	EndsAt              time.Time          `bson:"ends_at" json:"endsAt"`
+	GrantedBy           string             `bson:"granted_by" json:"grantedBy"`
+	ReminderSentAt      *time.Time         `bson:"reminder_sent_at,omitempty" json:"reminderSentAt,omitempty"`
+}
+
+type MembershipPlanRequest struct {
+	Name                string `json:"name" binding:"required"`
+	Description         string `json:"description"`
+	PricingTier         string `json:"pricingTier" binding:"required"`
+	DurationDays        int    `json:"durationDays" binding:"required"`
+	MaxUpcomingBookings int    `json:"maxUpcomingBookings"`
+	Price               int64  `json:"price"`
+	IsActive            *bool  `json:"isActive,omitempty"`
+}
+
+// GrantMembershipRequest dates are YYYY-MM-DD. The start defaults to today
+// and the end to the plan's duration after the start.
+type GrantMembershipRequest struct {
+	PlanID    string `json:"planId" binding:"required"`
+	StartDate string `json:"startDate,omitempty"`
+	EndDate   string `json:"endDate,omitempty"`
+}
+
+// PromoCode discounts a booking by a percentage or a fixed amount. Every
+// eligibility rule that is set must hold for the code to apply.
+type PromoCode struct {
+	ID               primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	Code             string               `bson:"code" json:"code"`
+	Description      string               `bson:"description,omitempty" json:"description,omitempty"`
+	PercentOff       int                  `bson:"percent_off,omitempty" json:"percentOff,omitempty"`
+	AmountOff        int64                `bson:"amount_off,omitempty" json:"amountOff,omitempty"`
+	MaxDiscount      int64                `bson:"max_discount,omitempty" json:"maxDiscount,omitempty"`
+	ValidFrom        *time.Time           `bson:"valid_from,omitempty" json:"validFrom,omitempty"`
+	ValidUntil       *time.Time           `bson:"valid_until,omitempty" json:"validUntil,omitempty"`
+	MaxUses          int                  `bson:"max_uses,omitempty" json:"maxUses,omitempty"`
+	MaxUsesPerUser   int                  `bson:"max_uses_per_user,omitempty" json:"maxUsesPerUser,omitempty"`
+	UsedCount        int                  `bson:"used_count" json:"usedCount"`
+	FirstBookingOnly bool                 `bson:"first_booking_only,omitempty" json:"firstBookingOnly,omitempty"`
+	Tiers            []string             `bson:"tiers,omitempty" json:"tiers,omitempty"`
+	FacilityIDs      []primitive.ObjectID `bson:"facility_ids,omitempty" json:"facilityIds,omitempty"`
+	Weekdays         []int                `bson:"weekdays,omitempty" json:"weekdays,omitempty"`
+	StartTime        string               `bson:"start_time,omitempty" json:"startTime,omitempty"`
+	EndTime          string               `bson:"end_time,omitempty" json:"endTime,omitempty"`
+	MinAmount        int64                `bson:"min_amount,omitempty" json:"minAmount,omitempty"`
+	IsActive         bool                 `bson:"is_active" json:"isActive"`
+	CreatedAt        time.Time            `bson:"created_at" json:"createdAt"`
+	UpdatedAt        time.Time            `bson:"updated_at" json:"updatedAt"`
+}
+
+// PromoCodeRequest dates are YYYY-MM-DD and inclusive.
+type PromoCodeRequest struct {
+	Code             string   `json:"code" binding:"required"`
+	Description      string   `json:"description"`
+	PercentOff       int      `json:"percentOff"`
+	AmountOff        int64    `json:"amountOff"`
+	MaxDiscount      int64    `json:"maxDiscount"`
+	ValidFrom        string   `json:"validFrom,omitempty"`
+	ValidUntil       string   `json:"validUntil,omitempty"`
+	MaxUses          int      `json:"maxUses"`
+	MaxUsesPerUser   int      `json:"maxUsesPerUser"`
+	FirstBookingOnly bool     `json:"firstBookingOnly"`
+	Tiers            []string `json:"tiers,omitempty"`
+	FacilityIDs      []string `json:"facilityIds,omitempty"`
+	Weekdays         []int    `json:"weekdays,omitempty"`
+	StartTime        string   `json:"startTime,omitempty"`
+	EndTime          string   `json:"endTime,omitempty"`
+	MinAmount        int64    `json:"minAmount"`
+	IsActive         *bool    `json:"isActive,omitempty"`
+}
+
+type PromoRedemption struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	PromoID   primitive.ObjectID `bson:"promo_id" json:"promoId"`
+	Code      string             `bson:"code" json:"code"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"userId"`
+	StudentID string             `bson:"student_id" json:"studentId"`
+	BookingID primitive.ObjectID `bson:"booking_id" json:"bookingId"`
+	Discount  int64              `bson:"discount" json:"discount"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// Invoice document kinds. Each kind is numbered in its own sequence that
+// restarts every year.
+const (
+	InvoiceKindInvoice = "invoice"
+	InvoiceKindReceipt = "receipt"
+)
+
+// Invoice is an issued invoice or receipt for a paid booking or wallet
+// top-up. Issued documents are never changed; the rendered PDF is stored
+// with them.
+type Invoice struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	Kind          string              `bson:"kind" json:"kind"`
+	Number        string              `bson:"number" json:"number"`
+	UserID        primitive.ObjectID  `bson:"user_id" json:"userId"`
+	StudentID     string              `bson:"student_id" json:"studentId"`
+	CustomerName  string              `bson:"customer_name" json:"customerName"`
+	CustomerEmail string              `bson:"customer_email,omitempty" json:"customerEmail,omitempty"`
+	BookingID     *primitive.ObjectID `bson:"booking_id,omitempty" json:"bookingId,omitempty"`
+	PaymentID     *primitive.ObjectID `bson:"payment_id,omitempty" json:"paymentId,omitempty"`
+	Lines         []*InvoiceLine      `bson:"lines" json:"lines"`
+	Subtotal      int64               `bson:"subtotal" json:"subtotal"`
+	Discount      int64               `bson:"discount,omitempty" json:"discount,omitempty"`
+	Total         int64               `bson:"total" json:"total"`
+	Currency      string              `bson:"currency" json:"currency"`
+	PaymentMethod string              `bson:"payment_method" json:"paymentMethod"`
+	PaidAt        time.Time           `bson:"paid_at" json:"paidAt"`
+	IssuedAt      time.Time           `bson:"issued_at" json:"issuedAt"`
+	PDF           []byte              `bson:"pdf,omitempty" json:"-"`
+}
+
+type InvoiceLine struct {
+	Description   string `bson:"description" json:"description"`
+	DescriptionTH string `bson:"description_th,omitempty" json:"descriptionTh,omitempty"`
+	Amount        int64  `bson:"amount" json:"amount"`
+}
+
+// Participant statuses. Invited students hold a place until they decline;
+// guests are accepted when they are added. Students asking to join an open
+// game that needs the host's approval hold a place while they wait.
+const (
+	ParticipantInvited   = "invited"
+	ParticipantRequested = "requested"
+	ParticipantAccepted  = "accepted"
+	ParticipantDeclined  = "declined"
+)
+
+// Participant is someone other than the booker who plays on a booking,
+// either a registered student or a named guest without an account.
+type Participant struct {
+	ID          primitive.ObjectID  `bson:"_id" json:"id"`
+	UserID      *primitive.ObjectID `bson:"user_id,omitempty" json:"userId,omitempty"`
+	StudentID   string              `bson:"student_id,omitempty" json:"studentId,omitempty"`
+	Name        string              `bson:"name" json:"name"`
+	Guest       bool                `bson:"guest,omitempty" json:"guest,omitempty"`
+	ViaOpenGame bool                `bson:"via_open_game,omitempty" json:"viaOpenGame,omitempty"`
+	Status      string              `bson:"status" json:"status"`
+	AddedBy     string              `bson:"added_by" json:"addedBy"`
+	AddedAt     time.Time           `bson:"added_at" json:"addedAt"`
+	RespondedAt *time.Time          `bson:"responded_at,omitempty" json:"respondedAt,omitempty"`
+}
+
+// AddParticipantRequest adds either a registered student or a guest.
+type AddParticipantRequest struct {
+	StudentID string `json:"studentId,omitempty"`
+	GuestName string `json:"guestName,omitempty"`
+}
+
+// Skill levels an open game can be advertised for.
+const (
+	SkillAny          = "any"
+	SkillBeginner     = "beginner"
+	SkillIntermediate = "intermediate"
+	SkillAdvanced     = "advanced"
+)
+
+// OpenGame advertises a booking on the matchmaking board. Spots counts the
+// places still open to other students and goes down as they join.
+type OpenGame struct {
+	SkillLevel      string    `bson:"skill_level" json:"skillLevel"`
+	Spots           int       `bson:"spots" json:"spots"`
+	RequireApproval bool      `bson:"require_approval" json:"requireApproval"`
+	Note            string    `bson:"note,omitempty" json:"note,omitempty"`
+	OpenedAt        time.Time `bson:"opened_at" json:"openedAt"`
+}
+
+type OpenGameRequest struct {
+	SkillLevel      string `json:"skillLevel" binding:"required,oneof=any beginner intermediate advanced"`
+	Spots           int    `json:"spots" binding:"required,min=1"`
+	RequireApproval bool   `json:"requireApproval"`
+	Note            string `json:"note,omitempty" binding:"max=200"`
+}
+
+// OpenGameResponse is a game on the board. The host is only identified by
+// name.
+type OpenGameResponse struct {
+	BookingID       string    `json:"bookingId"`
+	FacilityID      string    `json:"facilityId"`
+	CourtNumber     int       `json:"courtNumber"`
+	BookingDate     string    `json:"bookingDate"`
+	StartTime       string    `json:"startTime"`
+	EndTime         string    `json:"endTime"`
+	HostName        string    `json:"hostName"`
+	SkillLevel      string    `json:"skillLevel"`
+	SpotsLeft       int       `json:"spotsLeft"`
+	Players         int       `json:"players"`
+	RequireApproval bool      `json:"requireApproval"`
+	Note            string    `json:"note,omitempty"`
+	OpenedAt        time.Time `json:"openedAt"`
+}
+
+// BookingTransfer is a pending offer of a booking to another student.
+type BookingTransfer struct {
+	ToUserID    primitive.ObjectID `bson:"to_user_id" json:"-"`
+	ToStudentID string             `bson:"to_student_id" json:"toStudentId"`
+	ToName      string             `bson:"to_name" json:"toName"`
+	OfferedAt   time.Time          `bson:"offered_at" json:"offeredAt"`
+	ExpiresAt   time.Time          `bson:"expires_at" json:"expiresAt"`
+}
+
+type TransferBookingRequest struct {
+	StudentID string `json:"studentId" binding:"required"`
+}
+
+// Equipment kinds. Rentals come back after the booking; sold items leave
+// stock when they are handed over.
+const (
+	EquipmentRental = "rental"
+	EquipmentSale   = "sale"
+)
+
+// Statuses of equipment reserved on a booking.
+const (
+	EquipmentReserved   = "reserved"
+	EquipmentCheckedOut = "checked_out"
+	EquipmentReturned   = "returned"
+)
+
+// Equipment is an item the front desk lends or sells. Quantity is the stock
+// the facility owns, or has left for items that are sold.
+type Equipment struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	FacilityID primitive.ObjectID `bson:"facility_id" json:"facilityId"`
+	Name       string             `bson:"name" json:"name"`
+	Kind       string             `bson:"kind" json:"kind"`
+	Quantity   int                `bson:"quantity" json:"quantity"`
+	Price      int64              `bson:"price" json:"price"`
+	IsActive   bool               `bson:"is_active" json:"isActive"`
+	Revision   int64              `bson:"revision" json:"-"`
+	CreatedAt  time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+type EquipmentRequest struct {
+	FacilityID string `json:"facilityId,omitempty"`
+	Name       string `json:"name" binding:"required,max=100"`
+	Kind       string `json:"kind" binding:"required,oneof=rental sale"`
+	Quantity   int    `json:"quantity" binding:"min=0"`
+	Price      int64  `json:"price" binding:"min=0"`
+	IsActive   *bool  `json:"isActive,omitempty"`
+}
+
+// EquipmentAvailability is an item with how many are free for a slot.
+type EquipmentAvailability struct {
+	*Equipment
+	Available int `json:"available"`
+}
+
+// BookingEquipment is equipment reserved on a booking. It is paid for at
+// the front desk when it is checked out.
+type BookingEquipment struct {
+	EquipmentID  primitive.ObjectID `bson:"equipment_id" json:"equipmentId"`
+	Name         string             `bson:"name" json:"name"`
+	Kind         string             `bson:"kind" json:"kind"`
+	Quantity     int                `bson:"quantity" json:"quantity"`
+	UnitPrice    int64              `bson:"unit_price" json:"unitPrice"`
+	Amount       int64              `bson:"amount" json:"amount"`
+	Status       string             `bson:"status" json:"status"`
+	ReservedAt   time.Time          `bson:"reserved_at" json:"reservedAt"`
+	CheckedOutAt *time.Time         `bson:"checked_out_at,omitempty" json:"checkedOutAt,omitempty"`
+	CheckedOutBy string             `bson:"checked_out_by,omitempty" json:"checkedOutBy,omitempty"`
+	ReturnedAt   *time.Time         `bson:"returned_at,omitempty" json:"returnedAt,omitempty"`
+	ReturnedBy   string             `bson:"returned_by,omitempty" json:"returnedBy,omitempty"`
+}
+
+type ReserveEquipmentRequest struct {
+	EquipmentID string `json:"equipmentId" binding:"required"`
+	Quantity    int    `json:"quantity" binding:"min=0,max=50"`
+}
@@ -0,0 +1,84 @@
+package payments
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testPromptPayID = "0812345678"
+
+func TestFakeProviderCreateCharge(t *testing.T) {
+	p := NewFakeProvider(testPromptPayID, "secret")
+
+	charge, err := p.CreateCharge(context.Background(), ChargeRequest{Reference: "ref", Amount: 15000, Currency: "THB"})
+	if err != nil {
+		t.Fatalf("CreateCharge: %v", err)
+	}
+	if !strings.HasPrefix(charge.ProviderRef, "fake_") {
+		t.Errorf("ProviderRef = %q, want a fake_ prefix", charge.ProviderRef)
+	}
+	if charge.QRPayload == "" {
+		t.Error("QRPayload is empty")
+	}
+
+	other, err := p.CreateCharge(context.Background(), ChargeRequest{Reference: "ref", Amount: 15000, Currency: "THB"})
+	if err != nil {
+		t.Fatalf("CreateCharge: %v", err)
+	}
+	if other.ProviderRef == charge.ProviderRef {
+		t.Error("two charges got the same ProviderRef")
+	}
+}
+
+func TestFakeProviderParseWebhook(t *testing.T) {
+	p := NewFakeProvider(testPromptPayID, "secret")
+	body := `{"providerRef":"fake_abc","reference":"64b000000000000000000000","status":"paid","amount":15000}`
+
+	r := httptest.NewRequest("POST", "/api/payments/webhook", strings.NewReader(body))
+	r.Header.Set(FakeSignatureHeader, hex.EncodeToString(p.Sign([]byte(body))))
+
+	event, err := p.ParseWebhook(r)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	want := WebhookEvent{ProviderRef: "fake_abc", Reference: "64b000000000000000000000", Status: StatusPaid, Amount: 15000}
+	if *event != want {
+		t.Errorf("event = %+v, want %+v", *event, want)
+	}
+}
+
+func TestFakeProviderRejectsBadSignatures(t *testing.T) {
+	body := `{"providerRef":"fake_abc","status":"paid","amount":15000}`
+	signed := hex.EncodeToString(NewFakeProvider(testPromptPayID, "secret").Sign([]byte(body)))
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      string
+		signature string
+	}{
+		{name: "missing signature", secret: "secret", body: body},
+		{name: "not hex", secret: "secret", body: body, signature: "zz"},
+		{name: "wrong secret", secret: "other", body: body, signature: signed},
+		{name: "tampered body", secret: "secret", body: strings.Replace(body, "15000", "1", 1), signature: signed},
+		{name: "empty secret", secret: "", body: body, signature: hex.EncodeToString(NewFakeProvider(testPromptPayID, "").Sign([]byte(body)))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewFakeProvider(testPromptPayID, tt.secret)
+			r := httptest.NewRequest("POST", "/api/payments/webhook", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				r.Header.Set(FakeSignatureHeader, tt.signature)
+			}
+
+			if _, err := p.ParseWebhook(r); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("ParseWebhook error = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
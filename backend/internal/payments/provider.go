@@ -0,0 +1,47 @@
+// Package payments abstracts the payment provider that collects PromptPay
+// transfers and reports them back through a webhook.
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Webhook event statuses.
+const (
+	StatusPaid   = "paid"
+	StatusFailed = "failed"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+type ChargeRequest struct {
+	// Reference is our payment ID, echoed back in webhook events.
+	Reference   string
+	Amount      int64
+	Currency    string
+	Description string
+	ExpiresAt   time.Time
+}
+
+type Charge struct {
+	ProviderRef string
+	QRPayload   string
+}
+
+type WebhookEvent struct {
+	ProviderRef string `json:"providerRef"`
+	Reference   string `json:"reference"`
+	Status      string `json:"status"`
+	Amount      int64  `json:"amount"`
+}
+
+// Provider creates PromptPay charges and authenticates the webhook calls
+// that confirm them.
+type Provider interface {
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	ParseWebhook(r *http.Request) (*WebhookEvent, error)
+}
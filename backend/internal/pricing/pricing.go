@@ -0,0 +1,217 @@
+// Package pricing computes court fees from a facility's pricing rules.
+// Amounts are in satang (1/100 baht) so they add up exactly.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+const Currency = "THB"
+
+const (
+	TierMember    = "member"
+	TierNonMember = "non_member"
+)
+
+var Tiers = map[string]bool{
+	TierMember:    true,
+	TierNonMember: true,
+}
+
+// TierFor returns the pricing tier of a registered user. A current
+// membership sets the tier; otherwise accounts default to the member tier.
+// Walk-in guests without an account pay non-member rates.
+func TierFor(user *models.User) string {
+	if user == nil {
+		return TierNonMember
+	}
+	if membership := ActiveMembership(user, time.Now()); membership != nil {
+		return membership.PricingTier
+	}
+	if user.PricingTier == "" {
+		return TierMember
+	}
+	return user.PricingTier
+}
+
+// ActiveMembership returns the user's membership if it covers t.
+func ActiveMembership(user *models.User, t time.Time) *models.Membership {
+	m := user.Membership
+	if m == nil || t.Before(m.StartsAt) || !t.Before(m.EndsAt) {
+		return nil
+	}
+	return m
+}
+
+// Quote prices a booking on a court. The slot is split wherever a rule
+// starts or ends, and each part is charged at the rate of the best rule
+// covering it: court rules beat facility-wide rules, then higher priority
+// wins. Parts no rule covers are free.
+func Quote(rules []*models.PricingRule, facility *models.Facility, courtID primitive.ObjectID, tier string, start, end time.Time) *models.PriceBreakdown {
+	quote := &models.PriceBreakdown{
+		Currency: Currency,
+		Tier:     tier,
+		Lines:    []*models.PriceLine{},
+	}
+
+	applicable := make([]*models.PricingRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.CourtID != nil && *rule.CourtID != courtID {
+			continue
+		}
+		if !appliesOn(rule, start.Weekday()) {
+			continue
+		}
+		applicable = append(applicable, rule)
+	}
+	sort.SliceStable(applicable, func(i, j int) bool {
+		a, b := applicable[i], applicable[j]
+		if (a.CourtID != nil) != (b.CourtID != nil) {
+			return a.CourtID != nil
+		}
+		return a.Priority > b.Priority
+	})
+
+	from, to := minuteOfDay(start), minuteOfDay(end)
+	cuts := []int{from, to}
+	for _, rule := range applicable {
+		for _, m := range []int{parseMinute(rule.StartTime), parseMinute(rule.EndTime)} {
+			if m > from && m < to {
+				cuts = append(cuts, m)
+			}
+		}
+	}
+	sort.Ints(cuts)
+
+	var line *models.PriceLine
+	var lineRule *models.PricingRule
+	for i := 0; i+1 < len(cuts); i++ {
+		segStart, segEnd := cuts[i], cuts[i+1]
+		if segStart == segEnd {
+			continue
+		}
+
+		rule := ruleAt(applicable, segStart)
+		if rule == nil {
+			line, lineRule = nil, nil
+			continue
+		}
+
+		if line == nil || lineRule != rule {
+			ruleID := rule.ID
+			line = &models.PriceLine{
+				Label:       rule.Name,
+				RuleID:      &ruleID,
+				StartTime:   formatMinute(segStart),
+				RatePerHour: rate(rule, tier),
+			}
+			lineRule = rule
+			quote.Lines = append(quote.Lines, line)
+		}
+		line.EndTime = formatMinute(segEnd)
+		line.Minutes += segEnd - segStart
+	}
+
+	for _, l := range quote.Lines {
+		// Rounded to the nearest satang.
+		l.Amount = (l.RatePerHour*int64(l.Minutes) + 30) / 60
+		quote.Subtotal += l.Amount
+	}
+	quote.Total = quote.Subtotal
+
+	if isWeekend(start.Weekday()) && facility.WeekendSurchargePct > 0 && quote.Subtotal > 0 {
+		surcharge := (quote.Subtotal*int64(facility.WeekendSurchargePct) + 50) / 100
+		quote.Lines = append(quote.Lines, &models.PriceLine{
+			Label:  fmt.Sprintf("Weekend surcharge %d%%", facility.WeekendSurchargePct),
+			Amount: surcharge,
+		})
+		quote.Total += surcharge
+	}
+
+	return quote
+}
+
+// ValidateRule checks a rule's time band, weekdays and rates.
+func ValidateRule(rule *models.PricingRule) error {
+	from, errFrom := time.Parse("15:04", rule.StartTime)
+	to, errTo := time.Parse("15:04", rule.EndTime)
+	if errFrom != nil || errTo != nil {
+		return errors.New("Invalid time band, use HH:MM")
+	}
+	if !to.After(from) {
+		return errors.New("End time must be after start time")
+	}
+	for _, day := range rule.Weekdays {
+		if day < 0 || day > 6 {
+			return errors.New("Weekdays must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+	if rule.MemberRate < 0 || rule.NonMemberRate < 0 {
+		return errors.New("Rates must not be negative")
+	}
+	return nil
+}
+
+// FormatAmount renders satang as baht, e.g. 12050 as "120.50".
+func FormatAmount(satang int64) string {
+	sign := ""
+	if satang < 0 {
+		sign, satang = "-", -satang
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, satang/100, satang%100)
+}
+
+func ruleAt(rules []*models.PricingRule, minute int) *models.PricingRule {
+	for _, rule := range rules {
+		if parseMinute(rule.StartTime) <= minute && minute < parseMinute(rule.EndTime) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func appliesOn(rule *models.PricingRule, day time.Weekday) bool {
+	if len(rule.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range rule.Weekdays {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+func rate(rule *models.PricingRule, tier string) int64 {
+	if tier == TierNonMember {
+		return rule.NonMemberRate
+	}
+	return rule.MemberRate
+}
+
+func isWeekend(day time.Weekday) bool {
+	return day == time.Saturday || day == time.Sunday
+}
+
+func minuteOfDay(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+func parseMinute(hhmm string) int {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return 0
+	}
+	return minuteOfDay(t)
+}
+
+func formatMinute(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
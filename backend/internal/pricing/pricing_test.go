@@ -0,0 +1,206 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+// Monday and Saturday of the same week.
+var (
+	monday   = time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	saturday = time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+)
+
+func at(day time.Time, hhmm string) time.Time {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		panic(err)
+	}
+	return day.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute)
+}
+
+func TestQuote(t *testing.T) {
+	courtA, courtB := primitive.NewObjectID(), primitive.NewObjectID()
+	base := &models.PricingRule{ID: primitive.NewObjectID(), Name: "Standard", StartTime: "08:00", EndTime: "22:00", MemberRate: 20000, NonMemberRate: 30000}
+	peak := &models.PricingRule{ID: primitive.NewObjectID(), Name: "Peak", StartTime: "17:00", EndTime: "22:00", MemberRate: 30000, NonMemberRate: 45000, Priority: 10}
+	weekend := &models.PricingRule{ID: primitive.NewObjectID(), Name: "Weekend", Weekdays: []int{0, 6}, StartTime: "08:00", EndTime: "22:00", MemberRate: 25000, NonMemberRate: 35000, Priority: 20}
+	showCourt := &models.PricingRule{ID: primitive.NewObjectID(), CourtID: &courtA, Name: "Show court", StartTime: "08:00", EndTime: "22:00", MemberRate: 40000, NonMemberRate: 50000}
+	rules := []*models.PricingRule{base, peak, weekend, showCourt}
+
+	type line struct {
+		label  string
+		from   string
+		to     string
+		amount int64
+	}
+	tests := []struct {
+		name      string
+		rules     []*models.PricingRule
+		surcharge int
+		court     primitive.ObjectID
+		tier      string
+		start     time.Time
+		end       time.Time
+		wantLines []line
+		wantTotal int64
+	}{
+		{
+			name: "off-peak member", rules: rules, court: courtB, tier: TierMember,
+			start: at(monday, "10:00"), end: at(monday, "11:00"),
+			wantLines: []line{{"Standard", "10:00", "11:00", 20000}}, wantTotal: 20000,
+		},
+		{
+			name: "split at the start of peak", rules: rules, court: courtB, tier: TierMember,
+			start: at(monday, "16:00"), end: at(monday, "18:00"),
+			wantLines: []line{{"Standard", "16:00", "17:00", 20000}, {"Peak", "17:00", "18:00", 30000}}, wantTotal: 50000,
+		},
+		{
+			name: "non-member rates", rules: rules, court: courtB, tier: TierNonMember,
+			start: at(monday, "16:30"), end: at(monday, "17:30"),
+			wantLines: []line{{"Standard", "16:30", "17:00", 15000}, {"Peak", "17:00", "17:30", 22500}}, wantTotal: 37500,
+		},
+		{
+			name: "court rule beats a higher-priority facility rule", rules: rules, court: courtA, tier: TierMember,
+			start: at(monday, "17:00"), end: at(monday, "18:00"),
+			wantLines: []line{{"Show court", "17:00", "18:00", 40000}}, wantTotal: 40000,
+		},
+		{
+			name: "uncovered time is free", rules: rules, court: courtB, tier: TierMember,
+			start: at(monday, "07:00"), end: at(monday, "09:00"),
+			wantLines: []line{{"Standard", "08:00", "09:00", 20000}}, wantTotal: 20000,
+		},
+		{
+			name: "weekend rule skipped on weekdays", rules: rules, court: courtB, tier: TierMember,
+			start: at(monday, "09:00"), end: at(monday, "10:00"),
+			wantLines: []line{{"Standard", "09:00", "10:00", 20000}}, wantTotal: 20000,
+		},
+		{
+			name: "weekend surcharge", rules: rules, surcharge: 15, court: courtB, tier: TierMember,
+			start: at(saturday, "10:00"), end: at(saturday, "11:00"),
+			wantLines: []line{{"Weekend", "10:00", "11:00", 25000}, {"Weekend surcharge 15%", "", "", 3750}}, wantTotal: 28750,
+		},
+		{
+			name: "no surcharge on weekdays", rules: rules, surcharge: 15, court: courtB, tier: TierMember,
+			start: at(monday, "10:00"), end: at(monday, "11:00"),
+			wantLines: []line{{"Standard", "10:00", "11:00", 20000}}, wantTotal: 20000,
+		},
+		{
+			name: "line rounds half up to the satang", court: courtB, tier: TierMember,
+			rules: []*models.PricingRule{{Name: "Odd", StartTime: "08:00", EndTime: "22:00", MemberRate: 10}},
+			start: at(monday, "10:00"), end: at(monday, "10:03"),
+			wantLines: []line{{"Odd", "10:00", "10:03", 1}}, wantTotal: 1,
+		},
+		{
+			name: "line rounds down below half", court: courtB, tier: TierMember,
+			rules: []*models.PricingRule{{Name: "Odd", StartTime: "08:00", EndTime: "22:00", MemberRate: 20000}},
+			start: at(monday, "10:00"), end: at(monday, "10:07"),
+			wantLines: []line{{"Odd", "10:00", "10:07", 2333}}, wantTotal: 2333,
+		},
+		{
+			name: "surcharge rounds half up", surcharge: 10, court: courtB, tier: TierMember,
+			rules: []*models.PricingRule{{Name: "Odd", StartTime: "08:00", EndTime: "22:00", MemberRate: 335}},
+			start: at(saturday, "10:00"), end: at(saturday, "11:00"),
+			wantLines: []line{{"Odd", "10:00", "11:00", 335}, {"Weekend surcharge 10%", "", "", 34}}, wantTotal: 369,
+		},
+		{
+			name: "no rules", court: courtB, tier: TierMember,
+			start: at(saturday, "10:00"), end: at(saturday, "11:00"), surcharge: 15,
+			wantTotal: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facility := &models.Facility{WeekendSurchargePct: tt.surcharge}
+			quote := Quote(tt.rules, facility, tt.court, tt.tier, tt.start, tt.end)
+
+			if len(quote.Lines) != len(tt.wantLines) {
+				t.Fatalf("got %d lines, want %d: %+v", len(quote.Lines), len(tt.wantLines), quote.Lines)
+			}
+			for i, want := range tt.wantLines {
+				got := quote.Lines[i]
+				if got.Label != want.label || got.StartTime != want.from || got.EndTime != want.to || got.Amount != want.amount {
+					t.Errorf("line %d = %s %s-%s %d, want %s %s-%s %d", i,
+						got.Label, got.StartTime, got.EndTime, got.Amount, want.label, want.from, want.to, want.amount)
+				}
+			}
+			if quote.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d", quote.Total, tt.wantTotal)
+			}
+			if quote.Currency != Currency || quote.Tier != tt.tier {
+				t.Errorf("quote is in %s for %s, want %s for %s", quote.Currency, quote.Tier, Currency, tt.tier)
+			}
+		})
+	}
+}
+
+func TestTierFor(t *testing.T) {
+	now := time.Now()
+	current := &models.Membership{PricingTier: TierMember, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+	lapsed := &models.Membership{PricingTier: TierMember, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name string
+		user *models.User
+		want string
+	}{
+		{name: "walk-in guest", want: TierNonMember},
+		{name: "account default", user: &models.User{}, want: TierMember},
+		{name: "account tier", user: &models.User{PricingTier: TierNonMember}, want: TierNonMember},
+		{name: "current membership", user: &models.User{PricingTier: TierNonMember, Membership: current}, want: TierMember},
+		{name: "lapsed membership", user: &models.User{PricingTier: TierNonMember, Membership: lapsed}, want: TierNonMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TierFor(tt.user); got != tt.want {
+				t.Errorf("TierFor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    models.PricingRule
+		wantErr bool
+	}{
+		{name: "valid", rule: models.PricingRule{StartTime: "08:00", EndTime: "22:00", Weekdays: []int{0, 6}, MemberRate: 100}},
+		{name: "bad time", rule: models.PricingRule{StartTime: "8am", EndTime: "22:00"}, wantErr: true},
+		{name: "empty band", rule: models.PricingRule{StartTime: "10:00", EndTime: "10:00"}, wantErr: true},
+		{name: "reversed band", rule: models.PricingRule{StartTime: "22:00", EndTime: "08:00"}, wantErr: true},
+		{name: "bad weekday", rule: models.PricingRule{StartTime: "08:00", EndTime: "22:00", Weekdays: []int{7}}, wantErr: true},
+		{name: "negative rate", rule: models.PricingRule{StartTime: "08:00", EndTime: "22:00", NonMemberRate: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRule(&tt.rule); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRule error = %v, want an error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		satang int64
+		want   string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{12050, "120.50"},
+		{-12050, "-120.50"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatAmount(tt.satang); got != tt.want {
+			t.Errorf("FormatAmount(%d) = %q, want %q", tt.satang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Package promo checks promo code eligibility and applies discounts to
+// price quotes.
+package promo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+)
+
+// Booking describes the booking a code is being used for.
+type Booking struct {
+	Tier       string
+	FacilityID primitive.ObjectID
+	// Start is the booking's wall-clock start at the facility.
+	Start time.Time
+	// PriorBookings counts the user's earlier bookings that were not
+	// cancelled or left unpaid.
+	PriorBookings int64
+	// Redemptions counts the user's earlier uses of this code.
+	Redemptions int64
+}
+
+// Normalize makes codes case-insensitive.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Check returns a user-facing reason why the code does not apply, or nil.
+func Check(code *models.PromoCode, quote *models.PriceBreakdown, b Booking, now time.Time) error {
+	switch {
+	case !code.IsActive:
+		return errors.New("Promo code is not active")
+	case code.ValidFrom != nil && now.Before(*code.ValidFrom):
+		return errors.New("Promo code is not valid yet")
+	case code.ValidUntil != nil && !now.Before(*code.ValidUntil):
+		return errors.New("Promo code has expired")
+	case code.MaxUses > 0 && code.UsedCount >= code.MaxUses:
+		return errors.New("Promo code has been used up")
+	case code.MaxUsesPerUser > 0 && b.Redemptions >= int64(code.MaxUsesPerUser):
+		return errors.New("You have already used this promo code")
+	case code.FirstBookingOnly && b.PriorBookings > 0:
+		return errors.New("Promo code is only valid for your first booking")
+	case len(code.Tiers) > 0 && !contains(code.Tiers, b.Tier):
+		return errors.New("Promo code is not valid for your pricing tier")
+	case len(code.FacilityIDs) > 0 && !containsID(code.FacilityIDs, b.FacilityID):
+		return errors.New("Promo code is not valid at this facility")
+	case len(code.Weekdays) > 0 && !containsDay(code.Weekdays, b.Start.Weekday()):
+		return errors.New("Promo code is not valid on this day")
+	case code.StartTime != "" && !withinBand(code.StartTime, code.EndTime, b.Start):
+		return fmt.Errorf("Promo code is only valid for bookings starting between %s and %s", code.StartTime, code.EndTime)
+	case quote.Total <= 0:
+		return errors.New("Promo code cannot be used on a free booking")
+	case code.MinAmount > 0 && quote.Total < code.MinAmount:
+		return fmt.Errorf("Promo code needs a booking of at least %s THB", pricing.FormatAmount(code.MinAmount))
+	}
+	return nil
+}
+
+// Apply discounts the quote and returns the discount. The discount is
+// capped at MaxDiscount and never exceeds the total.
+func Apply(code *models.PromoCode, quote *models.PriceBreakdown) int64 {
+	var discount int64
+	if code.PercentOff > 0 {
+		discount = (quote.Total*int64(code.PercentOff) + 50) / 100
+	} else {
+		discount = code.AmountOff
+	}
+	if code.MaxDiscount > 0 && discount > code.MaxDiscount {
+		discount = code.MaxDiscount
+	}
+	if discount > quote.Total {
+		discount = quote.Total
+	}
+
+	quote.Lines = append(quote.Lines, &models.PriceLine{
+		Label:  "Promo " + code.Code,
+		Amount: -discount,
+	})
+	quote.Discount = discount
+	quote.PromoCode = code.Code
+	quote.Total -= discount
+	return discount
+}
+
+// Validate checks a code's discount and eligibility rules.
+func Validate(code *models.PromoCode) error {
+	if code.Code == "" || strings.ContainsAny(code.Code, " \t") {
+		return errors.New("Code must be a single word")
+	}
+	if (code.PercentOff > 0) == (code.AmountOff > 0) {
+		return errors.New("Set either percentOff or amountOff")
+	}
+	if code.PercentOff < 0 || code.PercentOff > 100 || code.AmountOff < 0 || code.MaxDiscount < 0 || code.MinAmount < 0 {
+		return errors.New("Discount amounts must be positive and percentOff at most 100")
+	}
+	if code.MaxUses < 0 || code.MaxUsesPerUser < 0 {
+		return errors.New("Usage limits must not be negative")
+	}
+	if code.ValidFrom != nil && code.ValidUntil != nil && !code.ValidUntil.After(*code.ValidFrom) {
+		return errors.New("validUntil must be after validFrom")
+	}
+	for _, tier := range code.Tiers {
+		if !pricing.Tiers[tier] {
+			return errors.New("Tiers must be member or non_member")
+		}
+	}
+	for _, day := range code.Weekdays {
+		if day < 0 || day > 6 {
+			return errors.New("Weekdays must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+	if code.StartTime != "" || code.EndTime != "" {
+		from, errFrom := time.Parse("15:04", code.StartTime)
+		to, errTo := time.Parse("15:04", code.EndTime)
+		if errFrom != nil || errTo != nil || !to.After(from) {
+			return errors.New("Invalid time band, use HH:MM with the end after the start")
+		}
+	}
+	return nil
+}
+
+func withinBand(start, end string, t time.Time) bool {
+	from, _ := time.Parse("15:04", start)
+	to, _ := time.Parse("15:04", end)
+	minute := t.Hour()*60 + t.Minute()
+	return from.Hour()*60+from.Minute() <= minute && minute < to.Hour()*60+to.Minute()
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDay(days []int, day time.Weekday) bool {
+	for _, d := range days {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package promo
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"courtopia-reserve/backend/internal/models"
+	"courtopia-reserve/backend/internal/pricing"
+)
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	earlier, later := now.Add(-time.Hour), now.Add(time.Hour)
+	facility, other := primitive.NewObjectID(), primitive.NewObjectID()
+	// A Monday evening booking at the facility, by a member with no
+	// earlier bookings or uses of the code.
+	booking := Booking{Tier: pricing.TierMember, FacilityID: facility, Start: time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name    string
+		edit    func(*models.PromoCode, *Booking)
+		total   int64
+		wantErr bool
+	}{
+		{name: "eligible", total: 30000},
+		{name: "inactive", edit: func(c *models.PromoCode, _ *Booking) { c.IsActive = false }, total: 30000, wantErr: true},
+		{name: "not valid yet", edit: func(c *models.PromoCode, _ *Booking) { c.ValidFrom = &later }, total: 30000, wantErr: true},
+		{name: "valid from now", edit: func(c *models.PromoCode, _ *Booking) { c.ValidFrom = &now }, total: 30000},
+		{name: "expired", edit: func(c *models.PromoCode, _ *Booking) { c.ValidUntil = &earlier }, total: 30000, wantErr: true},
+		{name: "expires now", edit: func(c *models.PromoCode, _ *Booking) { c.ValidUntil = &now }, total: 30000, wantErr: true},
+		{name: "used up", edit: func(c *models.PromoCode, _ *Booking) { c.MaxUses, c.UsedCount = 10, 10 }, total: 30000, wantErr: true},
+		{name: "uses left", edit: func(c *models.PromoCode, _ *Booking) { c.MaxUses, c.UsedCount = 10, 9 }, total: 30000},
+		{name: "used by this user", edit: func(c *models.PromoCode, b *Booking) { c.MaxUsesPerUser, b.Redemptions = 1, 1 }, total: 30000, wantErr: true},
+		{name: "not the first booking", edit: func(c *models.PromoCode, b *Booking) { c.FirstBookingOnly, b.PriorBookings = true, 1 }, total: 30000, wantErr: true},
+		{name: "first booking", edit: func(c *models.PromoCode, _ *Booking) { c.FirstBookingOnly = true }, total: 30000},
+		{name: "other tier", edit: func(c *models.PromoCode, _ *Booking) { c.Tiers = []string{pricing.TierNonMember} }, total: 30000, wantErr: true},
+		{name: "other facility", edit: func(c *models.PromoCode, _ *Booking) { c.FacilityIDs = []primitive.ObjectID{other} }, total: 30000, wantErr: true},
+		{name: "this facility", edit: func(c *models.PromoCode, _ *Booking) { c.FacilityIDs = []primitive.ObjectID{other, facility} }, total: 30000},
+		{name: "weekends only", edit: func(c *models.PromoCode, _ *Booking) { c.Weekdays = []int{0, 6} }, total: 30000, wantErr: true},
+		{name: "mornings only", edit: func(c *models.PromoCode, _ *Booking) { c.StartTime, c.EndTime = "08:00", "12:00" }, total: 30000, wantErr: true},
+		{name: "band end is exclusive", edit: func(c *models.PromoCode, _ *Booking) { c.StartTime, c.EndTime = "12:00", "18:00" }, total: 30000, wantErr: true},
+		{name: "band start is inclusive", edit: func(c *models.PromoCode, _ *Booking) { c.StartTime, c.EndTime = "18:00", "22:00" }, total: 30000},
+		{name: "free booking", total: 0, wantErr: true},
+		{name: "below minimum", edit: func(c *models.PromoCode, _ *Booking) { c.MinAmount = 30001 }, total: 30000, wantErr: true},
+		{name: "at minimum", edit: func(c *models.PromoCode, _ *Booking) { c.MinAmount = 30000 }, total: 30000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := &models.PromoCode{Code: "SAVE10", PercentOff: 10, IsActive: true}
+			b := booking
+			if tt.edit != nil {
+				tt.edit(code, &b)
+			}
+
+			err := Check(code, &models.PriceBreakdown{Total: tt.total}, b, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check error = %v, want an error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name         string
+		code         models.PromoCode
+		total        int64
+		wantDiscount int64
+	}{
+		{name: "percent off", code: models.PromoCode{Code: "P10", PercentOff: 10}, total: 30000, wantDiscount: 3000},
+		{name: "percent rounds half up", code: models.PromoCode{Code: "P10", PercentOff: 10}, total: 335, wantDiscount: 34},
+		{name: "percent rounds down below half", code: models.PromoCode{Code: "P10", PercentOff: 10}, total: 334, wantDiscount: 33},
+		{name: "percent capped", code: models.PromoCode{Code: "P50", PercentOff: 50, MaxDiscount: 10000}, total: 30000, wantDiscount: 10000},
+		{name: "amount off", code: models.PromoCode{Code: "A50", AmountOff: 5000}, total: 30000, wantDiscount: 5000},
+		{name: "amount off above total", code: models.PromoCode{Code: "A50", AmountOff: 5000}, total: 3000, wantDiscount: 3000},
+		{name: "full discount", code: models.PromoCode{Code: "FREE", PercentOff: 100}, total: 30000, wantDiscount: 30000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := &models.PriceBreakdown{Subtotal: tt.total, Total: tt.total}
+
+			discount := Apply(&tt.code, quote)
+			if discount != tt.wantDiscount {
+				t.Errorf("discount = %d, want %d", discount, tt.wantDiscount)
+			}
+			if quote.Total != tt.total-tt.wantDiscount || quote.Discount != tt.wantDiscount || quote.PromoCode != tt.code.Code {
+				t.Errorf("quote = %+v, want total %d after a %d discount with %s", quote, tt.total-tt.wantDiscount, tt.wantDiscount, tt.code.Code)
+			}
+			if len(quote.Lines) != 1 || quote.Lines[0].Amount != -tt.wantDiscount {
+				t.Errorf("lines = %+v, want one discount line of %d", quote.Lines, -tt.wantDiscount)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    models.PromoCode
+		wantErr bool
+	}{
+		{name: "percent off", code: models.PromoCode{Code: "SAVE10", PercentOff: 10}},
+		{name: "amount off with limits", code: models.PromoCode{Code: "SAVE50", AmountOff: 5000, MaxUses: 100, MaxUsesPerUser: 1, Tiers: []string{pricing.TierMember}, Weekdays: []int{1, 2}, StartTime: "08:00", EndTime: "12:00"}},
+		{name: "empty code", code: models.PromoCode{PercentOff: 10}, wantErr: true},
+		{name: "code with a space", code: models.PromoCode{Code: "SAVE 10", PercentOff: 10}, wantErr: true},
+		{name: "no discount", code: models.PromoCode{Code: "NONE"}, wantErr: true},
+		{name: "both discounts", code: models.PromoCode{Code: "BOTH", PercentOff: 10, AmountOff: 5000}, wantErr: true},
+		{name: "over 100 percent", code: models.PromoCode{Code: "MORE", PercentOff: 101}, wantErr: true},
+		{name: "negative cap", code: models.PromoCode{Code: "CAP", PercentOff: 10, MaxDiscount: -1}, wantErr: true},
+		{name: "negative uses", code: models.PromoCode{Code: "USES", PercentOff: 10, MaxUses: -1}, wantErr: true},
+		{name: "unknown tier", code: models.PromoCode{Code: "TIER", PercentOff: 10, Tiers: []string{"gold"}}, wantErr: true},
+		{name: "bad weekday", code: models.PromoCode{Code: "DAY", PercentOff: 10, Weekdays: []int{7}}, wantErr: true},
+		{name: "start without end", code: models.PromoCode{Code: "BAND", PercentOff: 10, StartTime: "08:00"}, wantErr: true},
+		{name: "reversed band", code: models.PromoCode{Code: "BAND", PercentOff: 10, StartTime: "12:00", EndTime: "08:00"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(&tt.code); (err != nil) != tt.wantErr {
+				t.Errorf("Validate error = %v, want an error: %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	code := models.PromoCode{Code: "DATES", PercentOff: 10, ValidFrom: &from, ValidUntil: &from}
+	if err := Validate(&code); err == nil {
+		t.Error("Validate accepted a code that expires when it starts")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("  save10 "); got != "SAVE10" {
+		t.Errorf("Normalize = %q, want %q", got, "SAVE10")
+	}
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+// Bookings that occupied a court, as opposed to cancelled ones.
+var occupyingStatuses = []string{"active", "completed", "no_show"}
+
+var bookedMinutesExpr = bson.M{
+	"$divide": []interface{}{bson.M{"$subtract": []interface{}{"$end_time", "$start_time"}}, 60000},
+}
+
+type AnalyticsRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAnalyticsRepository(db *mongo.Database) *AnalyticsRepository {
+	return &AnalyticsRepository{
+		collection: db.Collection("bookings"),
+	}
+}
+
+type CourtUsage struct {
+	CourtID       primitive.ObjectID `bson:"court_id"`
+	CourtNumber   int                `bson:"court_number"`
+	Period        string             `bson:"period"`
+	BookedMinutes float64            `bson:"booked_minutes"`
+}
+
+func dateRangeMatch(from, to time.Time) bson.M {
+	return bson.M{"booking_date": bson.M{"$gte": from, "$lte": to}}
+}
+
+// CourtUsage sums booked minutes per court per period. Periods are
+// calendar days ("2006-01-02") or ISO weeks ("2006-W01").
+func (r *AnalyticsRepository) CourtUsage(ctx context.Context, from, to time.Time, weekly bool) ([]*CourtUsage, error) {
+	format := "%Y-%m-%d"
+	if weekly {
+		format = "%G-W%V"
+	}
+
+	match := dateRangeMatch(from, to)
+	match["status"] = bson.M{"$in": occupyingStatuses}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"court_id": "$court_id",
+				"period":   bson.M{"$dateToString": bson.M{"format": format, "date": "$booking_date"}},
+			},
+			"court_number":   bson.M{"$last": "$court_number"},
+			"booked_minutes": bson.M{"$sum": bookedMinutesExpr},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":            0,
+			"court_id":       "$_id.court_id",
+			"period":         "$_id.period",
+			"court_number":   1,
+			"booked_minutes": 1,
+		}}},
+	}
+
+	var usage []*CourtUsage
+	if err := r.aggregate(ctx, pipeline, &usage); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// Heatmap counts, for each ISO weekday (1 = Monday) and hour, how many
+// bookings occupied the court during that hour.
+func (r *AnalyticsRepository) Heatmap(ctx context.Context, from, to time.Time) ([]*models.HeatmapCell, error) {
+	match := dateRangeMatch(from, to)
+	match["status"] = bson.M{"$in": occupyingStatuses}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.M{
+			"weekday": bson.M{"$isoDayOfWeek": "$start_time"},
+			"hour": bson.M{"$range": []interface{}{
+				bson.M{"$hour": "$start_time"},
+				bson.M{"$add": []interface{}{
+					bson.M{"$hour": bson.M{"$subtract": []interface{}{"$end_time", 1}}},
+					1,
+				}},
+			}},
+		}}},
+		{{Key: "$unwind", Value: "$hour"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"weekday": "$weekday", "hour": "$hour"},
+			"bookings": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":      0,
+			"weekday":  "$_id.weekday",
+			"hour":     "$_id.hour",
+			"bookings": 1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "weekday", Value: 1}, {Key: "hour", Value: 1}}}},
+	}
+
+	cells := []*models.HeatmapCell{}
+	if err := r.aggregate(ctx, pipeline, &cells); err != nil {
+		return nil, err
+	}
+
+	return cells, nil
+}
+
+func (r *AnalyticsRepository) Summary(ctx context.Context, from, to time.Time) (*models.AnalyticsSummary, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: dateRangeMatch(from, to)}},
+		{{Key: "$facet", Value: bson.M{
+			"statuses": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":     "$status",
+					"count":   bson.M{"$sum": 1},
+					"minutes": bson.M{"$sum": bookedMinutesExpr},
+				}},
+			},
+			"channels": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$ifNull": []interface{}{"$channel", models.ChannelSelfService}},
+					"count": bson.M{"$sum": 1},
+				}},
+			},
+			"users": bson.A{
+				bson.M{"$match": bson.M{
+					"status":     bson.M{"$in": occupyingStatuses},
+					"student_id": bson.M{"$ne": ""},
+				}},
+				bson.M{"$group": bson.M{"_id": "$student_id"}},
+				bson.M{"$count": "count"},
+			},
+		}}},
+	}
+
+	var result []struct {
+		Statuses []struct {
+			Status  string  `bson:"_id"`
+			Count   int64   `bson:"count"`
+			Minutes float64 `bson:"minutes"`
+		} `bson:"statuses"`
+		Channels []struct {
+			Channel string `bson:"_id"`
+			Count   int64  `bson:"count"`
+		} `bson:"channels"`
+		Users []struct {
+			Count int64 `bson:"count"`
+		} `bson:"users"`
+	}
+	if err := r.aggregate(ctx, pipeline, &result); err != nil {
+		return nil, err
+	}
+
+	summary := &models.AnalyticsSummary{ByStatus: map[string]int64{}, ByChannel: map[string]int64{}}
+	if len(result) == 0 {
+		return summary, nil
+	}
+
+	for _, s := range result[0].Statuses {
+		summary.TotalBookings += s.Count
+		summary.ByStatus[s.Status] = s.Count
+		if s.Status != "cancelled" {
+			summary.BookedHours += s.Minutes / 60
+		}
+	}
+	for _, ch := range result[0].Channels {
+		summary.ByChannel[ch.Channel] = ch.Count
+	}
+	if len(result[0].Users) > 0 {
+		summary.UniqueUsers = result[0].Users[0].Count
+	}
+
+	if summary.TotalBookings > 0 {
+		summary.CancellationRate = float64(summary.ByStatus["cancelled"]) / float64(summary.TotalBookings)
+	}
+	if attended := summary.ByStatus["completed"] + summary.ByStatus["no_show"]; attended > 0 {
+		summary.NoShowRate = float64(summary.ByStatus["no_show"]) / float64(attended)
+	}
+
+	return summary, nil
+}
+
+func (r *AnalyticsRepository) TopBookers(ctx context.Context, from, to time.Time, limit int) ([]*models.TopBooker, error) {
+	match := dateRangeMatch(from, to)
+	match["status"] = bson.M{"$in": occupyingStatuses}
+	match["student_id"] = bson.M{"$ne": ""}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$student_id",
+			"bookings": bson.M{"$sum": 1},
+			"minutes":  bson.M{"$sum": bookedMinutesExpr},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "bookings", Value: -1}, {Key: "minutes", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "users",
+			"localField":   "_id",
+			"foreignField": "student_id",
+			"as":           "user",
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"bookings":     1,
+			"booked_hours": bson.M{"$divide": []interface{}{"$minutes", 60}},
+			"name":         bson.M{"$ifNull": []interface{}{bson.M{"$arrayElemAt": []interface{}{"$user.name", 0}}, ""}},
+		}}},
+	}
+
+	bookers := []*models.TopBooker{}
+	if err := r.aggregate(ctx, pipeline, &bookers); err != nil {
+		return nil, err
+	}
+
+	return bookers, nil
+}
+
+func (r *AnalyticsRepository) aggregate(ctx context.Context, pipeline mongo.Pipeline, out interface{}) error {
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, out)
+}
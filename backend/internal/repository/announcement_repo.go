@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+type AnnouncementRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAnnouncementRepository(db *mongo.Database) *AnnouncementRepository {
+	return &AnnouncementRepository{
+		collection: db.Collection("announcements"),
+	}
+}
+
+func (r *AnnouncementRepository) Create(ctx context.Context, announcement *models.Announcement) error {
+	announcement.CreatedAt = time.Now()
+	announcement.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, announcement)
+	return err
+}
+
+func (r *AnnouncementRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Announcement, error) {
+	var announcement models.Announcement
+
+	filter := bson.M{"_id": id}
+	err := r.collection.FindOne(ctx, filter).Decode(&announcement)
+	if err != nil {
+		return nil, err
+	}
+
+	return &announcement, nil
+}
+
+func (r *AnnouncementRepository) Update(ctx context.Context, announcement *models.Announcement) error {
+	announcement.UpdatedAt = time.Now()
+
+	filter := bson.M{"_id": announcement.ID}
+	update := bson.M{"$set": announcement}
+
+	// Clearing the court scope or the end of the window removes the field.
+	unset := bson.M{}
+	if announcement.CourtID == nil {
+		unset["court_id"] = ""
+	}
+	if announcement.VisibleUntil == nil {
+		unset["visible_until"] = ""
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// FindAll returns every announcement, including scheduled and expired
+// ones, newest first.
+func (r *AnnouncementRepository) FindAll(ctx context.Context) ([]*models.Announcement, error) {
+	return r.find(ctx, bson.M{})
+}
+
+// FindActive returns announcements visible at the given time. When courtID
+// is set, only facility-wide notices and notices for that court match.
+func (r *AnnouncementRepository) FindActive(ctx context.Context, now time.Time, courtID *primitive.ObjectID) ([]*models.Announcement, error) {
+	filter := bson.M{
+		"visible_from": bson.M{"$lte": now},
+		"$or": []bson.M{
+			{"visible_until": bson.M{"$exists": false}},
+			{"visible_until": bson.M{"$gt": now}},
+		},
+	}
+	if courtID != nil {
+		filter["court_id"] = bson.M{"$in": []interface{}{nil, *courtID}}
+	}
+
+	return r.find(ctx, filter)
+}
+
+func (r *AnnouncementRepository) find(ctx context.Context, filter bson.M) ([]*models.Announcement, error) {
+	announcements := []*models.Announcement{}
+
+	opts := options.Find().SetSort(bson.D{{Key: "visible_from", Value: -1}, {Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	err = cursor.All(ctx, &announcements)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcements, nil
+}
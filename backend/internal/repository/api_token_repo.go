@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+type APITokenRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAPITokenRepository(db *mongo.Database) *APITokenRepository {
+	return &APITokenRepository{
+		collection: db.Collection("api_tokens"),
+	}
+}
+
+func (r *APITokenRepository) Create(ctx context.Context, token *models.APIToken) error {
+	token.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *APITokenRepository) FindByHash(ctx context.Context, hash string) (*models.APIToken, error) {
+	var token models.APIToken
+
+	filter := bson.M{"token_hash": hash}
+	err := r.collection.FindOne(ctx, filter).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *APITokenRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]*models.APIToken, error) {
+	tokens := []*models.APIToken{}
+
+	filter := bson.M{"user_id": userID}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	err = cursor.All(ctx, &tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (r *APITokenRepository) Revoke(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"_id":        id,
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (r *APITokenRepository) TouchLastUsed(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"last_used_at": time.Now()}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *APITokenRepository) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	filter := bson.M{"user_id": userID}
+	_, err := r.collection.DeleteMany(ctx, filter)
+	return err
+}
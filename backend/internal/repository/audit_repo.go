@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+const auditTTLIndex = "timestamp_ttl"
+
+// AuditLogRepository is append-only: entries are never updated and only
+// disappear through the retention TTL index.
+type AuditLogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAuditLogRepository(db *mongo.Database) *AuditLogRepository {
+	return &AuditLogRepository{
+		collection: db.Collection("audit_logs"),
+	}
+}
+
+type AuditLogFilter struct {
+	Actor        string
+	Impersonator string
+	Action       string
+	TargetType   string
+	TargetID     string
+	From         *time.Time
+	To           *time.Time
+	Page         int
+	Limit        int
+}
+
+func (r *AuditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+// Find returns matching entries newest first. Action matches as a prefix so
+// "court." returns every court action.
+func (r *AuditLogRepository) Find(ctx context.Context, f AuditLogFilter) ([]*models.AuditLog, int64, error) {
+	filter := bson.M{}
+	if f.Actor != "" {
+		filter["actor_student_id"] = f.Actor
+	}
+	if f.Impersonator != "" {
+		filter["impersonator_student_id"] = f.Impersonator
+	}
+	if f.Action != "" {
+		filter["action"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.Action)}
+	}
+	if f.TargetType != "" {
+		filter["target_type"] = f.TargetType
+	}
+	if f.TargetID != "" {
+		filter["target_id"] = f.TargetID
+	}
+	if f.From != nil || f.To != nil {
+		timestamp := bson.M{}
+		if f.From != nil {
+			timestamp["$gte"] = *f.From
+		}
+		if f.To != nil {
+			timestamp["$lt"] = *f.To
+		}
+		filter["timestamp"] = timestamp
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"timestamp": -1}).
+		SetSkip(int64((f.Page - 1) * f.Limit)).
+		SetLimit(int64(f.Limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []*models.AuditLog{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// EnsureIndexes creates the query indexes and keeps the TTL index in line
+// with the configured retention. A retention of zero removes the TTL index.
+func (r *AuditLogRepository) EnsureIndexes(ctx context.Context, retention time.Duration) error {
+	indexes := r.collection.Indexes()
+
+	_, err := indexes.CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "actor_student_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	if retention <= 0 {
+		_, err := indexes.DropOne(ctx, auditTTLIndex)
+		if err != nil && !isCommandError(err, 26, 27) {
+			return err
+		}
+		return nil
+	}
+
+	seconds := int32(retention.Seconds())
+	_, err = indexes.CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "timestamp", Value: 1}},
+		Options: options.Index().SetName(auditTTLIndex).SetExpireAfterSeconds(seconds),
+	})
+	if isCommandError(err, 85) {
+		// The index exists with a different retention; change it in place.
+		return r.collection.Database().RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: r.collection.Name()},
+			{Key: "index", Value: bson.M{"name": auditTTLIndex, "expireAfterSeconds": seconds}},
+		}).Err()
+	}
+	return err
+}
+
+// isCommandError reports whether err is a server error with one of the
+// given codes.
+func isCommandError(err error, codes ...int32) bool {
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	for _, code := range codes {
+		if cmdErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,10 @@ import (
 	"courtopia-reserve/backend/internal/models"
 )
 
+// Bookings that hold their court slot. Pending-payment holds only count
+// until they expire.
+var slotHoldingStatuses = []string{"active", "pending_payment"}
+
 type BookingRepository struct {
 	collection *mongo.Collection
 }
@@ -26,7 +30,9 @@ func NewBookingRepository(db *mongo.Database) *BookingRepository {
 func (r *BookingRepository) Create(ctx context.Context, booking *models.Booking) error {
 	booking.CreatedAt = time.Now()
 	booking.UpdatedAt = time.Now()
-	booking.Status = "active"
+	if booking.Status == "" {
+		booking.Status = "active"
+	}
 
 	_, err := r.collection.InsertOne(ctx, booking)
 	return err
@@ -123,17 +129,40 @@ func (r *BookingRepository) CancelBooking(ctx context.Context, id primitive.Obje
 	return err
 }
 
-func (r *BookingRepository) IsCourtAvailable(ctx context.Context, courtNumber int, bookingDate time.Time, startTime time.Time, endTime time.Time) (bool, error) {
+// CancelWithOutcome cancels a booking that still holds its slot and records
+// the cancellation policy outcome. It reports false if the booking was
+// already cancelled, expired or finished.
+func (r *BookingRepository) CancelWithOutcome(ctx context.Context, id primitive.ObjectID, outcome *models.CancellationOutcome) (bool, error) {
+	filter := bson.M{"_id": id, "status": bson.M{"$in": slotHoldingStatuses}}
+	update := bson.M{"$set": bson.M{
+		"status":       "cancelled",
+		"cancellation": outcome,
+		"updated_at":   time.Now(),
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *BookingRepository) IsCourtAvailable(ctx context.Context, courtID primitive.ObjectID, bookingDate time.Time, startTime time.Time, endTime time.Time) (bool, error) {
 	startOfDay := time.Date(bookingDate.Year(), bookingDate.Month(), bookingDate.Day(), 0, 0, 0, 0, bookingDate.Location())
 	endOfDay := time.Date(bookingDate.Year(), bookingDate.Month(), bookingDate.Day(), 23, 59, 59, 999999999, bookingDate.Location())
 
 	filter := bson.M{
-		"court_number": courtNumber,
+		"court_id": courtID,
 		"booking_date": bson.M{
 			"$gte": startOfDay,
 			"$lte": endOfDay,
 		},
-		"status": "active",
+		"status": bson.M{"$in": slotHoldingStatuses},
+		"$nor": []bson.M{{
+			"status":          "pending_payment",
+			"hold_expires_at": bson.M{"$lte": time.Now()},
+		}},
 		"$or": []bson.M{
 			{
 				"start_time": bson.M{"$lte": startTime},
@@ -158,8 +187,8 @@ func (r *BookingRepository) IsCourtAvailable(ctx context.Context, courtNumber in
 	return count == 0, nil
 }
 
-func (r *BookingRepository) GetAvailableCourts(ctx context.Context, bookingDate time.Time, startTime time.Time, endTime time.Time, courtRepo *CourtRepository) ([]*models.CourtAvailability, error) {
-	courts, err := courtRepo.FindActiveCourts(ctx)
+func (r *BookingRepository) GetAvailableCourts(ctx context.Context, facilityID primitive.ObjectID, bookingDate time.Time, startTime time.Time, endTime time.Time, courtRepo *CourtRepository) ([]*models.CourtAvailability, error) {
+	courts, err := courtRepo.FindActiveCourts(ctx, facilityID)
 	if err != nil {
 		return nil, err
 	}
@@ -167,12 +196,13 @@ func (r *BookingRepository) GetAvailableCourts(ctx context.Context, bookingDate
 	var availabilities []*models.CourtAvailability
 
 	for _, court := range courts {
-		isAvailable, err := r.IsCourtAvailable(ctx, court.CourtNumber, bookingDate, startTime, endTime)
+		isAvailable, err := r.IsCourtAvailable(ctx, court.ID, bookingDate, startTime, endTime)
 		if err != nil {
 			return nil, err
 		}
 
 		availabilities = append(availabilities, &models.CourtAvailability{
+			CourtID:     court.ID,
 			CourtNumber: court.CourtNumber,
 			IsAvailable: isAvailable,
 		})
@@ -248,3 +278,790 @@ func (r *BookingRepository) UpdateBooking(ctx context.Context, booking *models.B
 
 	return nil
 }
+
+// AnonymizeByStudentID strips the denormalised personal fields from a
+// user's bookings while keeping the rows for court usage history.
+func (r *BookingRepository) AnonymizeByStudentID(ctx context.Context, studentID string, placeholder string) error {
+	filter := bson.M{"student_id": studentID}
+	update := bson.M{"$set": bson.M{
+		"user_id":    primitive.NilObjectID,
+		"student_id": placeholder,
+		"user_email": "",
+		"updated_at": time.Now(),
+	}}
+
+	if _, err := r.collection.UpdateMany(ctx, filter, update); err != nil {
+		return err
+	}
+
+	// Bookings the user paid for and then handed over forget the payer.
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"paid_by_student_id": studentID},
+		bson.M{
+			"$set":   bson.M{"paid_by_student_id": placeholder, "updated_at": time.Now()},
+			"$unset": bson.M{"paid_by_user_id": ""},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Bookings the user played on keep a nameless place.
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"participants.student_id": studentID},
+		bson.M{
+			"$set": bson.M{
+				"participants.$[p].student_id": placeholder,
+				"participants.$[p].name":       "",
+				"updated_at":                   time.Now(),
+			},
+			"$unset": bson.M{"participants.$[p].user_id": ""},
+		},
+		options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"p.student_id": studentID}}}),
+	)
+	return err
+}
+
+// FindFutureByCourtID returns the slot-holding bookings on a court that end
+// after the given wall-clock time, earliest first.
+func (r *BookingRepository) FindFutureByCourtID(ctx context.Context, courtID primitive.ObjectID, after time.Time) ([]*models.Booking, error) {
+	bookings := []*models.Booking{}
+
+	filter := bson.M{
+		"court_id": courtID,
+		"status":   bson.M{"$in": slotHoldingStatuses},
+		"end_time": bson.M{"$gt": after},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	err = cursor.All(ctx, &bookings)
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+func (r *BookingRepository) MoveToCourt(ctx context.Context, id primitive.ObjectID, court *models.Court) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{
+		"facility_id":  court.FacilityID,
+		"court_id":     court.ID,
+		"court_number": court.CourtNumber,
+		"updated_at":   time.Now(),
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// UpdateCourtNumber keeps the denormalised court number on active bookings
+// in step when a court is renumbered. Past bookings keep the number they
+// were made under.
+func (r *BookingRepository) UpdateCourtNumber(ctx context.Context, courtID primitive.ObjectID, courtNumber int) error {
+	filter := bson.M{"court_id": courtID, "status": bson.M{"$in": slotHoldingStatuses}}
+	update := bson.M{"$set": bson.M{
+		"court_number": courtNumber,
+		"updated_at":   time.Now(),
+	}}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// ConfirmPayment turns a pending-payment hold into an active booking. It
+// reports false when the booking is no longer pending or its hold ran out,
+// since the slot may already have been booked by someone else.
+func (r *BookingRepository) ConfirmPayment(ctx context.Context, id primitive.ObjectID, paidAt time.Time) (bool, error) {
+	filter := bson.M{"_id": id, "status": "pending_payment", "hold_expires_at": bson.M{"$gt": paidAt}}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     "active",
+			"paid_at":    paidAt,
+			"updated_at": time.Now(),
+		},
+		"$unset": bson.M{"hold_expires_at": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// ExpireHolds releases pending-payment holds that ran out and returns the
+// IDs of the bookings it expired. Expired holds are marked as notified so
+// they never get a reminder.
+func (r *BookingRepository) ExpireHolds(ctx context.Context, now time.Time) ([]primitive.ObjectID, error) {
+	filter := bson.M{"status": "pending_payment", "hold_expires_at": bson.M{"$lte": now}}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var found []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	err = cursor.All(ctx, &found)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(found))
+	for i, b := range found {
+		ids[i] = b.ID
+	}
+	// Re-check the hold in case a payment confirmed it in the meantime.
+	filter["_id"] = bson.M{"$in": ids}
+	update := bson.M{"$set": bson.M{
+		"status":            "expired",
+		"notification_sent": true,
+		"updated_at":        now,
+	}}
+
+	if _, err := r.collection.UpdateMany(ctx, filter, update); err != nil {
+		return nil, err
+	}
+
+	cursor, err = r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "status": "expired"}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	found = nil
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	expired := make([]primitive.ObjectID, len(found))
+	for i, b := range found {
+		expired[i] = b.ID
+	}
+	return expired, nil
+}
+
+// CountUpcoming counts a student's bookings that still hold a slot and have
+// not ended, which is what the booking quota limits. Booking times are
+// wall-clock times, so now gives each facility's current wall-clock time.
+func (r *BookingRepository) CountUpcoming(ctx context.Context, studentID string, now map[primitive.ObjectID]time.Time) (int64, error) {
+	if len(now) == 0 {
+		return 0, nil
+	}
+
+	notEnded := make([]bson.M, 0, len(now))
+	for facilityID, wallClock := range now {
+		notEnded = append(notEnded, bson.M{"facility_id": facilityID, "end_time": bson.M{"$gt": wallClock}})
+	}
+
+	return r.collection.CountDocuments(ctx, bson.M{
+		"student_id": studentID,
+		"status":     bson.M{"$in": slotHoldingStatuses},
+		"$or":        notEnded,
+	})
+}
+
+// CountPriorBookings counts a user's bookings that were not cancelled or
+// left to expire.
+func (r *BookingRepository) CountPriorBookings(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"user_id": userID,
+		"status":  bson.M{"$in": []string{"active", "completed", "no_show", "pending_payment"}},
+	})
+}
+
+// participantsHolding are the participant statuses that take up a place.
+var participantsHolding = []string{models.ParticipantInvited, models.ParticipantRequested, models.ParticipantAccepted}
+
+// FindByParticipant returns the bookings a student has been invited to or
+// plays on, newest first.
+func (r *BookingRepository) FindByParticipant(ctx context.Context, studentID string) ([]*models.Booking, error) {
+	filter := bson.M{"participants": bson.M{"$elemMatch": bson.M{
+		"student_id": studentID,
+		"status":     bson.M{"$in": participantsHolding},
+	}}}
+
+	opts := options.Find().SetSort(bson.D{
+		{Key: "booking_date", Value: -1},
+		{Key: "start_time", Value: -1},
+	})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	bookings := []*models.Booking{}
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// AddParticipant adds a participant to a booking that still holds its slot
+// as long as the booker and everyone invited or accepted stay within
+// capacity. A student who already has a place is not added twice. It
+// reports false when the participant was not added.
+func (r *BookingRepository) AddParticipant(ctx context.Context, bookingID primitive.ObjectID, p *models.Participant, capacity int) (bool, error) {
+	update := bson.M{
+		"$push": bson.M{"participants": p},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, participantFilter(bookingID, p, capacity), update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// JoinOpenGame adds a student who joins from the matchmaking board, taking
+// one of the open spots. The host cannot join their own game.
+func (r *BookingRepository) JoinOpenGame(ctx context.Context, bookingID primitive.ObjectID, p *models.Participant, capacity int) (bool, error) {
+	filter := participantFilter(bookingID, p, capacity)
+	filter["status"] = "active"
+	filter["student_id"] = bson.M{"$ne": p.StudentID}
+	filter["open_game.spots"] = bson.M{"$gt": 0}
+
+	update := bson.M{
+		"$push": bson.M{"participants": p},
+		"$inc":  bson.M{"open_game.spots": -1},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// ReleaseOpenSpot gives back the spot of a board participant who left or
+// was turned away, if the game is still on the board.
+func (r *BookingRepository) ReleaseOpenSpot(ctx context.Context, bookingID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": bookingID, "open_game": bson.M{"$exists": true}},
+		bson.M{"$inc": bson.M{"open_game.spots": 1}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	return err
+}
+
+// SetOpenGame puts a booking that still holds its slot on the matchmaking
+// board, or updates its listing.
+func (r *BookingRepository) SetOpenGame(ctx context.Context, bookingID primitive.ObjectID, game *models.OpenGame) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": bookingID, "status": bson.M{"$in": slotHoldingStatuses}},
+		bson.M{"$set": bson.M{"open_game": game, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// CloseOpenGame takes a booking off the board. Players who already joined
+// stay on it.
+func (r *BookingRepository) CloseOpenGame(ctx context.Context, bookingID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": bookingID},
+		bson.M{"$unset": bson.M{"open_game": ""}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	return err
+}
+
+// FindOpenGames returns the active bookings on a facility's board for a day
+// that start after the given wall-clock time and still have open spots,
+// earliest first. A skill level also matches games open to any level.
+func (r *BookingRepository) FindOpenGames(ctx context.Context, facilityID primitive.ObjectID, date, after time.Time, skill string) ([]*models.Booking, error) {
+	filter := bson.M{
+		"facility_id":     facilityID,
+		"booking_date":    date,
+		"start_time":      bson.M{"$gt": after},
+		"status":          "active",
+		"open_game.spots": bson.M{"$gt": 0},
+	}
+	if skill != "" {
+		filter["open_game.skill_level"] = bson.M{"$in": []string{skill, models.SkillAny}}
+	}
+
+	opts := options.Find().SetSort(bson.D{
+		{Key: "start_time", Value: 1},
+		{Key: "court_number", Value: 1},
+	})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	bookings := []*models.Booking{}
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// OfferTransfer records an offer of an active booking to another student,
+// replacing any earlier offer. It reports false when the booking is no
+// longer active or has changed hands.
+func (r *BookingRepository) OfferTransfer(ctx context.Context, bookingID primitive.ObjectID, ownerStudentID string, transfer *models.BookingTransfer) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": bookingID, "student_id": ownerStudentID, "status": "active"},
+		bson.M{"$set": bson.M{"transfer": transfer, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// ClearTransfer withdraws or declines the offer to a student.
+func (r *BookingRepository) ClearTransfer(ctx context.Context, bookingID primitive.ObjectID, toStudentID string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": bookingID, "transfer.to_student_id": toStudentID},
+		bson.M{"$unset": bson.M{"transfer": ""}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// AcceptTransfer hands an active booking over to the student it was offered
+// to, as long as the offer has not lapsed and the booking has not changed
+// hands since it was read. The recipient's own place on the booking, if
+// they had one, goes away. The first transfer of a paid booking records who
+// paid for it.
+func (r *BookingRepository) AcceptTransfer(ctx context.Context, booking *models.Booking, recipient *models.User, now time.Time) (bool, error) {
+	filter := bson.M{
+		"_id":                    booking.ID,
+		"student_id":             booking.StudentID,
+		"status":                 "active",
+		"transfer.to_student_id": recipient.StudentID,
+		"transfer.expires_at":    bson.M{"$gt": now},
+	}
+
+	set := bson.M{
+		"user_id":    recipient.ID,
+		"student_id": recipient.StudentID,
+		"user_email": recipient.Email,
+		"updated_at": now,
+	}
+	if booking.PaidAt != nil && booking.PaidByUserID == nil {
+		set["paid_by_user_id"] = booking.UserID
+		set["paid_by_student_id"] = booking.StudentID
+	}
+
+	update := bson.M{
+		"$set":   set,
+		"$unset": bson.M{"transfer": ""},
+		"$pull":  bson.M{"participants": bson.M{"student_id": recipient.StudentID}},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// FindTransferOffers returns the active bookings offered to a student whose
+// offers have not lapsed, earliest first.
+func (r *BookingRepository) FindTransferOffers(ctx context.Context, studentID string, now time.Time) ([]*models.Booking, error) {
+	filter := bson.M{
+		"status":                 "active",
+		"transfer.to_student_id": studentID,
+		"transfer.expires_at":    bson.M{"$gt": now},
+	}
+
+	opts := options.Find().SetSort(bson.D{
+		{Key: "booking_date", Value: 1},
+		{Key: "start_time", Value: 1},
+	})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	bookings := []*models.Booking{}
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// ReservedEquipment counts how many of an item other bookings have claimed
+// for a slot. Rentals are claimed by reservations on bookings that overlap
+// the slot and by anything checked out and not yet returned. Items for sale
+// are claimed by every reservation not yet handed over, whenever its
+// booking is.
+func (r *BookingRepository) ReservedEquipment(ctx context.Context, item *models.Equipment, exclude primitive.ObjectID, startTime, endTime time.Time) (int, error) {
+	reserved := bson.M{
+		"status":           bson.M{"$in": slotHoldingStatuses},
+		"equipment.status": models.EquipmentReserved,
+	}
+	claims := []bson.M{reserved}
+	if item.Kind == models.EquipmentRental {
+		reserved["start_time"] = bson.M{"$lt": endTime}
+		reserved["end_time"] = bson.M{"$gt": startTime}
+		claims = append(claims, bson.M{"equipment.status": models.EquipmentCheckedOut})
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": bson.M{"$ne": exclude}, "equipment.equipment_id": item.ID}}},
+		{{Key: "$unwind", Value: "$equipment"}},
+		{{Key: "$match", Value: bson.M{"equipment.equipment_id": item.ID, "$or": claims}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "quantity": bson.M{"$sum": "$equipment.quantity"}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var totals []struct {
+		Quantity int `bson:"quantity"`
+	}
+	if err := cursor.All(ctx, &totals); err != nil || len(totals) == 0 {
+		return 0, err
+	}
+
+	return totals[0].Quantity, nil
+}
+
+// SetEquipment replaces the equipment on a booking that still holds its
+// slot.
+func (r *BookingRepository) SetEquipment(ctx context.Context, bookingID primitive.ObjectID, equipment []*models.BookingEquipment) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": bookingID, "status": bson.M{"$in": slotHoldingStatuses}},
+		bson.M{"$set": bson.M{"equipment": equipment, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// CheckOutEquipment records that reserved equipment was handed over.
+func (r *BookingRepository) CheckOutEquipment(ctx context.Context, bookingID, equipmentID primitive.ObjectID, by string, at time.Time) (bool, error) {
+	return r.setEquipmentStatus(ctx, bookingID, equipmentID, models.EquipmentReserved, bson.M{
+		"equipment.$.status":         models.EquipmentCheckedOut,
+		"equipment.$.checked_out_at": at,
+		"equipment.$.checked_out_by": by,
+	})
+}
+
+// ReturnEquipment records that checked-out equipment came back.
+func (r *BookingRepository) ReturnEquipment(ctx context.Context, bookingID, equipmentID primitive.ObjectID, by string, at time.Time) (bool, error) {
+	return r.setEquipmentStatus(ctx, bookingID, equipmentID, models.EquipmentCheckedOut, bson.M{
+		"equipment.$.status":      models.EquipmentReturned,
+		"equipment.$.returned_at": at,
+		"equipment.$.returned_by": by,
+	})
+}
+
+func (r *BookingRepository) setEquipmentStatus(ctx context.Context, bookingID, equipmentID primitive.ObjectID, from string, set bson.M) (bool, error) {
+	filter := bson.M{
+		"_id":       bookingID,
+		"equipment": bson.M{"$elemMatch": bson.M{"equipment_id": equipmentID, "status": from}},
+	}
+	set["updated_at"] = time.Now()
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// participantFilter matches a booking that still holds its slot and has
+// room for one more player within capacity, counting the booker. A student
+// who already has a place matches no booking.
+func participantFilter(bookingID primitive.ObjectID, p *models.Participant, capacity int) bson.M {
+	filter := bson.M{
+		"_id":    bookingID,
+		"status": bson.M{"$in": slotHoldingStatuses},
+		"$expr": bson.M{"$lt": bson.A{
+			bson.M{"$size": bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$participants", bson.A{}}},
+				"cond":  bson.M{"$in": bson.A{"$$this.status", participantsHolding}},
+			}}},
+			capacity - 1,
+		}},
+	}
+	if p.StudentID != "" {
+		filter["participants"] = bson.M{"$not": bson.M{"$elemMatch": bson.M{
+			"student_id": p.StudentID,
+			"status":     bson.M{"$in": participantsHolding},
+		}}}
+	}
+	return filter
+}
+
+// SetParticipantStatus moves a student's participation from one status to
+// another and reports whether it was in the expected status.
+func (r *BookingRepository) SetParticipantStatus(ctx context.Context, bookingID primitive.ObjectID, studentID, from, to string) (bool, error) {
+	return r.setParticipantStatus(ctx, bookingID, bson.M{"student_id": studentID, "status": from}, to)
+}
+
+func (r *BookingRepository) SetParticipantStatusByID(ctx context.Context, bookingID, participantID primitive.ObjectID, from, to string) (bool, error) {
+	return r.setParticipantStatus(ctx, bookingID, bson.M{"_id": participantID, "status": from}, to)
+}
+
+func (r *BookingRepository) setParticipantStatus(ctx context.Context, bookingID primitive.ObjectID, match bson.M, to string) (bool, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id":          bookingID,
+		"participants": bson.M{"$elemMatch": match},
+	}
+	update := bson.M{"$set": bson.M{
+		"participants.$.status":       to,
+		"participants.$.responded_at": now,
+		"updated_at":                  now,
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *BookingRepository) RemoveParticipant(ctx context.Context, bookingID, participantID primitive.ObjectID) (bool, error) {
+	update := bson.M{
+		"$pull": bson.M{"participants": bson.M{"_id": participantID}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": bookingID, "participants._id": participantID}, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// AssignDefaultFacility moves bookings made before facilities existed into
+// the default facility, which owns every court they could have used.
+func (r *BookingRepository) AssignDefaultFacility(ctx context.Context, facilityID primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"facility_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"facility_id": facilityID}},
+	)
+	return err
+}
+
+func (r *BookingRepository) StatsByStudentID(ctx context.Context, studentID string) (*models.UserBookingStats, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"student_id": studentID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$status",
+			"count": bson.M{"$sum": 1},
+			"minutes": bson.M{"$sum": bson.M{
+				"$divide": []interface{}{bson.M{"$subtract": []interface{}{"$end_time", "$start_time"}}, 60000},
+			}},
+			"first": bson.M{"$min": "$start_time"},
+			"last":  bson.M{"$max": "$start_time"},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		Status  string    `bson:"_id"`
+		Count   int64     `bson:"count"`
+		Minutes float64   `bson:"minutes"`
+		First   time.Time `bson:"first"`
+		Last    time.Time `bson:"last"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	stats := &models.UserBookingStats{ByStatus: map[string]int64{}}
+	for _, g := range groups {
+		stats.Total += g.Count
+		stats.ByStatus[g.Status] = g.Count
+		if g.Status != "cancelled" {
+			stats.BookedHours += g.Minutes / 60
+		}
+		if stats.FirstBooking == nil || g.First.Before(*stats.FirstBooking) {
+			first := g.First
+			stats.FirstBooking = &first
+		}
+		if stats.LastBooking == nil || g.Last.After(*stats.LastBooking) {
+			last := g.Last
+			stats.LastBooking = &last
+		}
+	}
+
+	return stats, nil
+}
+
+type BookingFilter struct {
+	FacilityIDs []primitive.ObjectID
+	CourtNumber int
+	StudentID   string
+	Status      string
+	Channel     string
+	From        *time.Time
+	To          *time.Time
+}
+
+func (f BookingFilter) query() bson.M {
+	filter := bson.M{}
+	if f.FacilityIDs != nil {
+		filter["facility_id"] = bson.M{"$in": f.FacilityIDs}
+	}
+	if f.CourtNumber != 0 {
+		filter["court_number"] = f.CourtNumber
+	}
+	if f.StudentID != "" {
+		filter["student_id"] = f.StudentID
+	}
+	if f.Status != "" {
+		filter["status"] = f.Status
+	}
+	if f.Channel == models.ChannelSelfService {
+		filter["channel"] = bson.M{"$in": []interface{}{nil, models.ChannelSelfService}}
+	} else if f.Channel != "" {
+		filter["channel"] = f.Channel
+	}
+	if f.From != nil || f.To != nil {
+		dateRange := bson.M{}
+		if f.From != nil {
+			dateRange["$gte"] = *f.From
+		}
+		if f.To != nil {
+			dateRange["$lte"] = *f.To
+		}
+		filter["booking_date"] = dateRange
+	}
+	return filter
+}
+
+var bookingListSort = bson.D{
+	{Key: "booking_date", Value: -1},
+	{Key: "start_time", Value: -1},
+}
+
+func (r *BookingRepository) List(ctx context.Context, f BookingFilter, page, limit int) ([]*models.Booking, int64, error) {
+	filter := f.query()
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bookingListSort).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	bookings := []*models.Booking{}
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, 0, err
+	}
+
+	return bookings, total, nil
+}
+
+// Stream calls fn for every booking matching the filter, decoding one
+// document at a time from the cursor instead of loading the full result.
+func (r *BookingRepository) Stream(ctx context.Context, f BookingFilter, fn func(*models.Booking) error) error {
+	opts := options.Find().SetSort(bookingListSort).SetBatchSize(500)
+
+	cursor, err := r.collection.Find(ctx, f.query(), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var booking models.Booking
+		if err := cursor.Decode(&booking); err != nil {
+			return err
+		}
+		if err := fn(&booking); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// FindMatching returns up to limit bookings matching the filter in
+// chronological order.
+func (r *BookingRepository) FindMatching(ctx context.Context, f BookingFilter, limit int) ([]*models.Booking, error) {
+	bookings := []*models.Booking{}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "booking_date", Value: 1}, {Key: "start_time", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, f.query(), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	err = cursor.All(ctx, &bookings)
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// SetStatus moves the given bookings to status, but only those still in
+// one of the expected statuses so concurrent changes are not overwritten.
+func (r *BookingRepository) SetStatus(ctx context.Context, ids []primitive.ObjectID, expected []string, status string) (int64, error) {
+	filter := bson.M{
+		"_id":    bson.M{"$in": ids},
+		"status": bson.M{"$in": expected},
+	}
+	update := bson.M{"$set": bson.M{
+		"status":     status,
+		"updated_at": time.Now(),
+	}}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
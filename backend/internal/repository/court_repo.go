@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -24,8 +25,28 @@ func NewCourtRepository(db *mongo.Database) *CourtRepository {
 func (r *CourtRepository) FindAll(ctx context.Context) ([]*models.Court, error) {
 	var courts []*models.Court
 
+	filter := bson.M{"is_retired": bson.M{"$ne": true}}
 	opts := options.Find().SetSort(bson.M{"court_number": 1})
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	err = cursor.All(ctx, &courts)
+	if err != nil {
+		return nil, err
+	}
+
+	return courts, nil
+}
+
+func (r *CourtRepository) FindByFacility(ctx context.Context, facilityID primitive.ObjectID) ([]*models.Court, error) {
+	var courts []*models.Court
+
+	filter := bson.M{"facility_id": facilityID, "is_retired": bson.M{"$ne": true}}
+	opts := options.Find().SetSort(bson.M{"court_number": 1})
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -51,10 +72,10 @@ func (r *CourtRepository) FindByID(ctx context.Context, id primitive.ObjectID) (
 	return &court, nil
 }
 
-func (r *CourtRepository) FindByCourtNumber(ctx context.Context, courtNumber int) (*models.Court, error) {
+func (r *CourtRepository) FindByCourtNumber(ctx context.Context, facilityID primitive.ObjectID, courtNumber int) (*models.Court, error) {
 	var court models.Court
 
-	filter := bson.M{"court_number": courtNumber}
+	filter := bson.M{"facility_id": facilityID, "court_number": courtNumber, "is_retired": bson.M{"$ne": true}}
 	err := r.collection.FindOne(ctx, filter).Decode(&court)
 	if err != nil {
 		return nil, err
@@ -63,10 +84,10 @@ func (r *CourtRepository) FindByCourtNumber(ctx context.Context, courtNumber int
 	return &court, nil
 }
 
-func (r *CourtRepository) FindActiveCourts(ctx context.Context) ([]*models.Court, error) {
+func (r *CourtRepository) FindActiveCourts(ctx context.Context, facilityID primitive.ObjectID) ([]*models.Court, error) {
 	var courts []*models.Court
 
-	filter := bson.M{"is_active": true}
+	filter := bson.M{"facility_id": facilityID, "is_active": true}
 	opts := options.Find().SetSort(bson.M{"court_number": 1})
 	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
@@ -89,3 +110,79 @@ func (r *CourtRepository) UpdateStatus(ctx context.Context, id primitive.ObjectI
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	return err
 }
+
+// EnsureIndexes backfills is_retired on courts inserted by hand, moves
+// courts created before facilities existed into the default facility and
+// makes court numbers unique per facility among courts that have not been
+// retired.
+func (r *CourtRepository) EnsureIndexes(ctx context.Context, defaultFacilityID primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"is_retired": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"is_retired": false}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"facility_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"facility_id": defaultFacilityID}},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Court numbers used to be unique across all courts.
+	if _, err := r.collection.Indexes().DropOne(ctx, "court_number_unique"); err != nil && !isCommandError(err, 26, 27) {
+		return err
+	}
+
+	_, err = r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "facility_id", Value: 1}, {Key: "court_number", Value: 1}},
+		Options: options.Index().
+			SetName("facility_court_number_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"is_retired": false}),
+	})
+	return err
+}
+
+func (r *CourtRepository) Create(ctx context.Context, court *models.Court) error {
+	court.CreatedAt = time.Now()
+	court.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, court)
+	return err
+}
+
+func (r *CourtRepository) Update(ctx context.Context, court *models.Court) error {
+	court.UpdatedAt = time.Now()
+
+	filter := bson.M{"_id": court.ID}
+	update := bson.M{"$set": court}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// Touch bumps a court's revision. Writers that check the court's bookings
+// inside a transaction touch it so two of them cannot both commit.
+func (r *CourtRepository) Touch(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"revision": 1}})
+	return err
+}
+
+func (r *CourtRepository) Retire(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{
+		"is_active":  false,
+		"is_retired": true,
+		"retired_at": now,
+		"updated_at": now,
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+type EquipmentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewEquipmentRepository(db *mongo.Database) *EquipmentRepository {
+	return &EquipmentRepository{
+		collection: db.Collection("equipment"),
+	}
+}
+
+func (r *EquipmentRepository) Create(ctx context.Context, item *models.Equipment) error {
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, item)
+	return err
+}
+
+func (r *EquipmentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Equipment, error) {
+	var item models.Equipment
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r *EquipmentRepository) FindByFacility(ctx context.Context, facilityID primitive.ObjectID, activeOnly bool) ([]*models.Equipment, error) {
+	filter := bson.M{"facility_id": facilityID}
+	if activeOnly {
+		filter["is_active"] = true
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []*models.Equipment{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *EquipmentRepository) Update(ctx context.Context, item *models.Equipment) error {
+	item.UpdatedAt = time.Now()
+
+	update := bson.M{"$set": bson.M{
+		"name":       item.Name,
+		"kind":       item.Kind,
+		"quantity":   item.Quantity,
+		"price":      item.Price,
+		"is_active":  item.IsActive,
+		"updated_at": item.UpdatedAt,
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": item.ID}, update)
+	return err
+}
+
+// Touch bumps an item's revision. Stock checks write it inside their
+// transaction so two of them for the same item cannot both commit.
+func (r *EquipmentRepository) Touch(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"revision": 1}})
+	return err
+}
+
+// TakeStock removes sold items from stock and reports false when there are
+// not enough left.
+func (r *EquipmentRepository) TakeStock(ctx context.Context, id primitive.ObjectID, quantity int) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "quantity": bson.M{"$gte": quantity}},
+		bson.M{"$inc": bson.M{"quantity": -quantity, "revision": 1}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
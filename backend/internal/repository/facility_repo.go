@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+type FacilityRepository struct {
+	collection *mongo.Collection
+}
+
+func NewFacilityRepository(db *mongo.Database) *FacilityRepository {
+	return &FacilityRepository{
+		collection: db.Collection("facilities"),
+	}
+}
+
+func (r *FacilityRepository) Create(ctx context.Context, facility *models.Facility) error {
+	facility.CreatedAt = time.Now()
+	facility.UpdatedAt = time.Now()
+	if facility.AdminIDs == nil {
+		facility.AdminIDs = []primitive.ObjectID{}
+	}
+
+	_, err := r.collection.InsertOne(ctx, facility)
+	return err
+}
+
+func (r *FacilityRepository) FindAll(ctx context.Context) ([]*models.Facility, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "is_default", Value: -1}, {Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	facilities := []*models.Facility{}
+	if err := cursor.All(ctx, &facilities); err != nil {
+		return nil, err
+	}
+
+	return facilities, nil
+}
+
+func (r *FacilityRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Facility, error) {
+	var facility models.Facility
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&facility)
+	if err != nil {
+		return nil, err
+	}
+
+	return &facility, nil
+}
+
+func (r *FacilityRepository) FindDefault(ctx context.Context) (*models.Facility, error) {
+	var facility models.Facility
+
+	err := r.collection.FindOne(ctx, bson.M{"is_default": true}).Decode(&facility)
+	if err != nil {
+		return nil, err
+	}
+
+	return &facility, nil
+}
+
+// FindByAdmin returns the facilities a facility admin manages.
+func (r *FacilityRepository) FindByAdmin(ctx context.Context, userID primitive.ObjectID) ([]*models.Facility, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"admin_ids": userID}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	facilities := []*models.Facility{}
+	if err := cursor.All(ctx, &facilities); err != nil {
+		return nil, err
+	}
+
+	return facilities, nil
+}
+
+func (r *FacilityRepository) Update(ctx context.Context, facility *models.Facility) error {
+	facility.UpdatedAt = time.Now()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": facility.ID}, bson.M{"$set": facility})
+	return err
+}
+
+// RemoveAdmin drops a user from every facility they manage, for example
+// when the account is deleted.
+func (r *FacilityRepository) RemoveAdmin(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"admin_ids": userID},
+		bson.M{"$pull": bson.M{"admin_ids": userID}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	return err
+}
+
+// EnsureDefault returns the default facility, creating it from defaults on
+// the first start after facilities were introduced. Data from before then
+// belongs to this facility.
+func (r *FacilityRepository) EnsureDefault(ctx context.Context, defaults *models.Facility) (*models.Facility, error) {
+	now := time.Now()
+	insert := bson.M{
+		"_id":          primitive.NewObjectID(),
+		"name":         defaults.Name,
+		"address":      defaults.Address,
+		"timezone":     defaults.Timezone,
+		"opening_time": defaults.OpeningTime,
+		"closing_time": defaults.ClosingTime,
+		"admin_ids":    []primitive.ObjectID{},
+		"created_at":   now,
+		"updated_at":   now,
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var facility models.Facility
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"is_default": true}, bson.M{"$setOnInsert": insert}, opts).Decode(&facility)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "is_default", Value: 1}},
+		Options: options.Index().
+			SetName("default_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"is_default": true}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &facility, nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+// withoutPDF leaves the stored file out of listings.
+var withoutPDF = bson.M{"pdf": 0}
+
+type InvoiceRepository struct {
+	collection *mongo.Collection
+	counters   *mongo.Collection
+}
+
+func NewInvoiceRepository(db *mongo.Database) *InvoiceRepository {
+	return &InvoiceRepository{
+		collection: db.Collection("invoices"),
+		counters:   db.Collection("counters"),
+	}
+}
+
+type InvoiceFilter struct {
+	UserID    *primitive.ObjectID
+	StudentID string
+	Kind      string
+	From      *time.Time
+	To        *time.Time
+	Page      int
+	Limit     int
+}
+
+// NextSequence returns the next number in a kind's sequence for a year,
+// starting from one.
+func (r *InvoiceRepository) NextSequence(ctx context.Context, kind string, year int) (int64, error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.counters.FindOneAndUpdate(ctx,
+		bson.M{"_id": fmt.Sprintf("%s:%d", kind, year)},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		opts,
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+
+	return counter.Seq, nil
+}
+
+func (r *InvoiceRepository) Create(ctx context.Context, invoice *models.Invoice) error {
+	if invoice.ID.IsZero() {
+		invoice.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, invoice)
+	return err
+}
+
+// FindByID returns a document including its PDF.
+func (r *InvoiceRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Invoice, error) {
+	var invoice models.Invoice
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+// FindForBookings returns the documents issued for each of the bookings.
+func (r *InvoiceRepository) FindForBookings(ctx context.Context, bookingIDs []primitive.ObjectID) (map[primitive.ObjectID][]*models.Invoice, error) {
+	opts := options.Find().SetProjection(withoutPDF).SetSort(bson.M{"kind": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"booking_id": bson.M{"$in": bookingIDs}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var invoices []*models.Invoice
+	if err := cursor.All(ctx, &invoices); err != nil {
+		return nil, err
+	}
+
+	byBooking := map[primitive.ObjectID][]*models.Invoice{}
+	for _, invoice := range invoices {
+		byBooking[*invoice.BookingID] = append(byBooking[*invoice.BookingID], invoice)
+	}
+	return byBooking, nil
+}
+
+// FindForPayment returns the documents issued for a wallet top-up.
+func (r *InvoiceRepository) FindForPayment(ctx context.Context, paymentID primitive.ObjectID) ([]*models.Invoice, error) {
+	opts := options.Find().SetProjection(withoutPDF).SetSort(bson.M{"kind": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"payment_id": paymentID, "booking_id": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invoices := []*models.Invoice{}
+	if err := cursor.All(ctx, &invoices); err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+// List returns one page of documents, newest first, without their PDFs.
+func (r *InvoiceRepository) List(ctx context.Context, f InvoiceFilter) ([]*models.Invoice, int64, error) {
+	filter := bson.M{}
+	if f.UserID != nil {
+		filter["user_id"] = *f.UserID
+	}
+	if f.StudentID != "" {
+		filter["student_id"] = f.StudentID
+	}
+	if f.Kind != "" {
+		filter["kind"] = f.Kind
+	}
+	if issued := timeRange(f.From, f.To); issued != nil {
+		filter["issued_at"] = issued
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetProjection(withoutPDF).
+		SetSort(bson.D{{Key: "issued_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((f.Page - 1) * f.Limit)).
+		SetLimit(int64(f.Limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	invoices := []*models.Invoice{}
+	if err := cursor.All(ctx, &invoices); err != nil {
+		return nil, 0, err
+	}
+
+	return invoices, total, nil
+}
+
+// EnsureIndexes makes numbers unique and allows one document of each kind
+// per booking and per top-up.
+func (r *InvoiceRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "number", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "kind", Value: 1}, {Key: "booking_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"booking_id": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{{Key: "kind", Value: 1}, {Key: "payment_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"payment_id": bson.M{"$exists": true}}),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "issued_at", Value: -1}}},
+		{Keys: bson.D{{Key: "issued_at", Value: -1}}},
+	})
+	return err
+}
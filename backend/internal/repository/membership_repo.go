@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+type MembershipPlanRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMembershipPlanRepository(db *mongo.Database) *MembershipPlanRepository {
+	return &MembershipPlanRepository{
+		collection: db.Collection("membership_plans"),
+	}
+}
+
+func (r *MembershipPlanRepository) Create(ctx context.Context, plan *models.MembershipPlan) error {
+	plan.CreatedAt = time.Now()
+	plan.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, plan)
+	return err
+}
+
+func (r *MembershipPlanRepository) FindAll(ctx context.Context, activeOnly bool) ([]*models.MembershipPlan, error) {
+	filter := bson.M{}
+	if activeOnly {
+		filter["is_active"] = true
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	plans := []*models.MembershipPlan{}
+	if err := cursor.All(ctx, &plans); err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+func (r *MembershipPlanRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.MembershipPlan, error) {
+	var plan models.MembershipPlan
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+func (r *MembershipPlanRepository) Update(ctx context.Context, plan *models.MembershipPlan) error {
+	plan.UpdatedAt = time.Now()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": plan.ID}, bson.M{"$set": plan})
+	return err
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+type NotificationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewNotificationRepository(db *mongo.Database) *NotificationRepository {
+	return &NotificationRepository{
+		collection: db.Collection("notifications"),
+	}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	notification.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, notification)
+	return err
+}
+
+func (r *NotificationRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]*models.Notification, error) {
+	notifications := []*models.Notification{}
+
+	filter := bson.M{"user_id": userID}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	err = cursor.All(ctx, &notifications)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	filter := bson.M{"user_id": userID}
+	_, err := r.collection.DeleteMany(ctx, filter)
+	return err
+}
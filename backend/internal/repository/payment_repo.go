@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+type PaymentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPaymentRepository(db *mongo.Database) *PaymentRepository {
+	return &PaymentRepository{
+		collection: db.Collection("payments"),
+	}
+}
+
+func (r *PaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	if payment.ID.IsZero() {
+		payment.ID = primitive.NewObjectID()
+	}
+	payment.CreatedAt = time.Now()
+	payment.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, payment)
+	return err
+}
+
+func (r *PaymentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Payment, error) {
+	var payment models.Payment
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&payment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *PaymentRepository) FindByProviderRef(ctx context.Context, provider, ref string) (*models.Payment, error) {
+	var payment models.Payment
+
+	err := r.collection.FindOne(ctx, bson.M{"provider": provider, "provider_ref": ref}).Decode(&payment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *PaymentRepository) FindLatestByBooking(ctx context.Context, bookingID primitive.ObjectID) (*models.Payment, error) {
+	var payment models.Payment
+
+	opts := options.FindOne().SetSort(bson.M{"created_at": -1})
+	err := r.collection.FindOne(ctx, bson.M{"booking_id": bookingID}, opts).Decode(&payment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// MarkPaid records a successful payment if it is currently in one of from.
+// It reports false otherwise, so a repeated webhook is handled only once.
+func (r *PaymentRepository) MarkPaid(ctx context.Context, id primitive.ObjectID, paidAt time.Time, from ...string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": bson.M{"$in": from}},
+		bson.M{"$set": bson.M{"status": models.PaymentPaid, "paid_at": paidAt, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// SetStatus moves a payment to status if it is currently in one of from.
+func (r *PaymentRepository) SetStatus(ctx context.Context, id primitive.ObjectID, status string, from ...string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": bson.M{"$in": from}},
+		bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// FlagRefund marks a payment as owing the given refund if it is currently in
+// one of from.
+func (r *PaymentRepository) FlagRefund(ctx context.Context, id primitive.ObjectID, amount int64, from ...string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": bson.M{"$in": from}},
+		bson.M{"$set": bson.M{"status": models.PaymentRefundDue, "refund_amount": amount, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// ExpireStale expires pending payments whose hold has run out.
+func (r *PaymentRepository) ExpireStale(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"status": models.PaymentPending, "expires_at": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"status": models.PaymentExpired, "updated_at": now}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (r *PaymentRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "provider", Value: 1}, {Key: "provider_ref", Value: 1}}},
+		{Keys: bson.D{{Key: "booking_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}}},
+	})
+	return err
+}
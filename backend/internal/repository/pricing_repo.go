@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+type PricingRuleRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPricingRuleRepository(db *mongo.Database) *PricingRuleRepository {
+	return &PricingRuleRepository{
+		collection: db.Collection("pricing_rules"),
+	}
+}
+
+func (r *PricingRuleRepository) Create(ctx context.Context, rule *models.PricingRule) error {
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, rule)
+	return err
+}
+
+func (r *PricingRuleRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.PricingRule, error) {
+	var rule models.PricingRule
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// FindByFacility returns every rule of a facility, including court rules.
+func (r *PricingRuleRepository) FindByFacility(ctx context.Context, facilityID primitive.ObjectID) ([]*models.PricingRule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}, {Key: "priority", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"facility_id": facilityID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rules := []*models.PricingRule{}
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (r *PricingRuleRepository) Update(ctx context.Context, rule *models.PricingRule) error {
+	rule.UpdatedAt = time.Now()
+
+	unset := bson.M{}
+	if rule.CourtID == nil {
+		unset["court_id"] = ""
+	}
+	if len(rule.Weekdays) == 0 {
+		unset["weekdays"] = ""
+	}
+
+	update := bson.M{"$set": rule}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": rule.ID}, update)
+	return err
+}
+
+func (r *PricingRuleRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
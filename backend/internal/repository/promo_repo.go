@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+var ErrPromoUsedUp = errors.New("promo code has been used up")
+
+// PromoCodeRepository stores promo codes and their redemptions. A code's
+// used count is the number of redemptions, kept in step by Redeem and
+// Release.
+type PromoCodeRepository struct {
+	codes       *mongo.Collection
+	redemptions *mongo.Collection
+}
+
+func NewPromoCodeRepository(db *mongo.Database) *PromoCodeRepository {
+	return &PromoCodeRepository{
+		codes:       db.Collection("promo_codes"),
+		redemptions: db.Collection("promo_redemptions"),
+	}
+}
+
+func (r *PromoCodeRepository) Create(ctx context.Context, code *models.PromoCode) error {
+	code.CreatedAt = time.Now()
+	code.UpdatedAt = time.Now()
+
+	_, err := r.codes.InsertOne(ctx, code)
+	return err
+}
+
+func (r *PromoCodeRepository) FindAll(ctx context.Context) ([]*models.PromoCode, error) {
+	cursor, err := r.codes.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	codes := []*models.PromoCode{}
+	if err := cursor.All(ctx, &codes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (r *PromoCodeRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.PromoCode, error) {
+	var code models.PromoCode
+
+	err := r.codes.FindOne(ctx, bson.M{"_id": id}).Decode(&code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+func (r *PromoCodeRepository) FindByCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	var promo models.PromoCode
+
+	err := r.codes.FindOne(ctx, bson.M{"code": code}).Decode(&promo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &promo, nil
+}
+
+// Update replaces a code's settings but never its used count.
+func (r *PromoCodeRepository) Update(ctx context.Context, code *models.PromoCode) error {
+	code.UpdatedAt = time.Now()
+
+	set, err := bson.Marshal(code)
+	if err != nil {
+		return err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(set, &doc); err != nil {
+		return err
+	}
+	delete(doc, "used_count")
+	delete(doc, "created_at")
+
+	unset := bson.M{}
+	for _, field := range []string{"valid_from", "valid_until", "tiers", "facility_ids", "weekdays", "start_time", "end_time",
+		"percent_off", "amount_off", "max_discount", "max_uses", "max_uses_per_user", "min_amount", "description"} {
+		if _, ok := doc[field]; !ok {
+			unset[field] = ""
+		}
+	}
+
+	update := bson.M{"$set": doc}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	_, err = r.codes.UpdateOne(ctx, bson.M{"_id": code.ID}, update)
+	return err
+}
+
+// CountRedemptions counts a user's redemptions of a code.
+func (r *PromoCodeRepository) CountRedemptions(ctx context.Context, promoID, userID primitive.ObjectID) (int64, error) {
+	return r.redemptions.CountDocuments(ctx, bson.M{"promo_id": promoID, "user_id": userID})
+}
+
+// Redeem claims one use of the code for a booking. It fails with
+// ErrPromoUsedUp if the code ran out or was deactivated in the meantime.
+func (r *PromoCodeRepository) Redeem(ctx context.Context, code *models.PromoCode, redemption *models.PromoRedemption) error {
+	filter := bson.M{"_id": code.ID, "is_active": true}
+	if code.MaxUses > 0 {
+		filter["used_count"] = bson.M{"$lt": code.MaxUses}
+	}
+
+	result, err := r.codes.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"used_count": 1}})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return ErrPromoUsedUp
+	}
+
+	redemption.ID = primitive.NewObjectID()
+	redemption.PromoID = code.ID
+	redemption.Code = code.Code
+	redemption.CreatedAt = time.Now()
+	if _, err := r.redemptions.InsertOne(ctx, redemption); err != nil {
+		r.codes.UpdateOne(ctx, bson.M{"_id": code.ID}, bson.M{"$inc": bson.M{"used_count": -1}})
+		return err
+	}
+
+	return nil
+}
+
+// Release gives back the use a booking claimed, for example when it is
+// cancelled or its payment hold expires.
+func (r *PromoCodeRepository) Release(ctx context.Context, bookingID primitive.ObjectID) error {
+	var redemption models.PromoRedemption
+
+	err := r.redemptions.FindOneAndDelete(ctx, bson.M{"booking_id": bookingID}).Decode(&redemption)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = r.codes.UpdateOne(ctx, bson.M{"_id": redemption.PromoID}, bson.M{"$inc": bson.M{"used_count": -1}})
+	return err
+}
+
+func (r *PromoCodeRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.codes.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.redemptions.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "promo_id", Value: 1}, {Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "booking_id", Value: 1}}},
+	})
+	return err
+}
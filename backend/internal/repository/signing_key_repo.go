@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/models"
+)
+
+// legacySigningKeyID names the record that remembers when the shared
+// JWT_SECRET stopped signing. It holds no key material.
+const legacySigningKeyID = "legacy-hs256"
+
+type SigningKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSigningKeyRepository(db *mongo.Database) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		collection: db.Collection("signing_keys"),
+	}
+}
+
+func (r *SigningKeyRepository) Create(ctx context.Context, key *models.SigningKey) error {
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
+
+// FindUsable returns keys that can still validate tokens, newest first.
+func (r *SigningKeyRepository) FindUsable(ctx context.Context) ([]*models.SigningKey, error) {
+	var keys []*models.SigningKey
+
+	filter := bson.M{
+		"_id": bson.M{"$ne": legacySigningKeyID},
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$exists": false}},
+			{"expires_at": bson.M{"$gt": time.Now()}},
+		},
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	err = cursor.All(ctx, &keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RetireOthers stops every key except activeID from signing. Retired keys
+// keep validating until expiresAt so tokens they signed stay usable.
+func (r *SigningKeyRepository) RetireOthers(ctx context.Context, activeID string, expiresAt time.Time) error {
+	filter := bson.M{
+		"_id":        bson.M{"$ne": activeID},
+		"retired_at": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{
+		"retired_at": time.Now(),
+		"expires_at": expiresAt,
+	}}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// Activate marks a key published ahead of rotation as the signing key, once
+// the keys it replaces have been retired.
+func (r *SigningKeyRepository) Activate(ctx context.Context, id string) error {
+	update := bson.M{"$unset": bson.M{"activates_at": ""}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// SetPrivateKey replaces the stored private key, for example to encrypt a
+// key saved before encryption was introduced.
+func (r *SigningKeyRepository) SetPrivateKey(ctx context.Context, id, privateKey string) error {
+	update := bson.M{"$set": bson.M{"private_key": privateKey}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// RetireLegacy records that the shared secret stopped signing and returns
+// when it stops validating. Only the first call sets the time, so restarts
+// do not extend the legacy key's life.
+func (r *SigningKeyRepository) RetireLegacy(ctx context.Context, expiresAt time.Time) (time.Time, error) {
+	now := time.Now()
+	update := bson.M{"$setOnInsert": bson.M{
+		"algorithm":  "HS256",
+		"created_at": now,
+		"retired_at": now,
+		"expires_at": expiresAt,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var record models.SigningKey
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": legacySigningKeyID}, update, opts).Decode(&record); err != nil {
+		return time.Time{}, err
+	}
+	if record.ExpiresAt == nil {
+		return now, nil
+	}
+	return *record.ExpiresAt, nil
+}
+
+// ClearLegacy forgets the shared secret's retirement, so switching to
+// asymmetric keys again later gives its sessions a fresh grace period.
+func (r *SigningKeyRepository) ClearLegacy(ctx context.Context) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": legacySigningKeyID})
+	return err
+}
+
+// DeleteExpired removes keys that no longer validate. The legacy record is
+// kept so its retirement time survives.
+func (r *SigningKeyRepository) DeleteExpired(ctx context.Context) error {
+	filter := bson.M{
+		"_id":        bson.M{"$ne": legacySigningKeyID},
+		"expires_at": bson.M{"$lte": time.Now()},
+	}
+	_, err := r.collection.DeleteMany(ctx, filter)
+	return err
+}
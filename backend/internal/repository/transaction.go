@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RunInTransaction runs fn in a multi-document transaction. Repository calls
+// made with the context passed to fn take part in it, and fn may be retried
+// on transient errors. Transactions need a replica set or sharded cluster.
+func RunInTransaction(ctx context.Context, db *mongo.Database, fn func(ctx context.Context) error) error {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"courtopia-reserve/backend/internal/models"
 )
@@ -33,6 +35,18 @@ func (r *UserRepository) FindByStudentID(ctx context.Context, studentID string)
 	return &user, nil
 }
 
+func (r *UserRepository) FindByOIDCSubject(ctx context.Context, issuer, subject string) (*models.User, error) {
+	var user models.User
+
+	filter := bson.M{"oidc_issuer": issuer, "oidc_subject": subject}
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
@@ -74,3 +88,154 @@ func (r *UserRepository) UpdateOne(ctx context.Context, filter interface{}, upda
 	return err
 }
 
+// RecordTOTPStep moves the user's last used TOTP step forward. It reports
+// false when that step or a later one was already used, so a code is
+// accepted at most once even by concurrent requests.
+func (r *UserRepository) RecordTOTPStep(ctx context.Context, id primitive.ObjectID, step int64) (bool, error) {
+	filter := bson.M{"_id": id, "totp_last_step": bson.M{"$not": bson.M{"$gte": step}}}
+	update := bson.M{"$set": bson.M{"totp_last_step": step}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// ConsumeRecoveryCode removes a recovery code hash. It reports false when
+// the hash is no longer there, for example because a concurrent request
+// used it first.
+func (r *UserRepository) ConsumeRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) (bool, error) {
+	filter := bson.M{"_id": id, "recovery_codes": hash}
+	update := bson.M{
+		"$pull": bson.M{"recovery_codes": hash},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// RecordTOTPFailure counts a failed second-factor attempt. Once max
+// attempts have failed the counter is reset and the account is locked
+// until lockUntil; it reports whether this attempt caused the lock.
+func (r *UserRepository) RecordTOTPFailure(ctx context.Context, id primitive.ObjectID, max int, lockUntil time.Time) (bool, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var user models.User
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"totp_failed_attempts": 1}}, opts).Decode(&user)
+	if err != nil {
+		return false, err
+	}
+	if user.TOTPFailedAttempts < max {
+		return false, nil
+	}
+
+	filter := bson.M{"_id": id, "totp_failed_attempts": bson.M{"$gte": max}}
+	update := bson.M{
+		"$set":   bson.M{"totp_locked_until": lockUntil},
+		"$unset": bson.M{"totp_failed_attempts": ""},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *UserRepository) ClearTOTPFailures(ctx context.Context, id primitive.ObjectID) error {
+	update := bson.M{"$unset": bson.M{"totp_failed_attempts": "", "totp_locked_until": ""}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+type UserSearch struct {
+	Query     string
+	Role      string
+	Suspended *bool
+	Page      int
+	Limit     int
+}
+
+// Search matches the query as a case-insensitive substring of the student
+// ID, name or email and returns one page of users plus the total count.
+func (r *UserRepository) Search(ctx context.Context, search UserSearch) ([]*models.User, int64, error) {
+	filter := bson.M{}
+	if search.Query != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search.Query), Options: "i"}
+		filter["$or"] = []bson.M{
+			{"student_id": pattern},
+			{"name": pattern},
+			{"email": pattern},
+		}
+	}
+	if search.Role != "" {
+		filter["role"] = search.Role
+	}
+	if search.Suspended != nil {
+		filter["suspended"] = *search.Suspended
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "student_id", Value: 1}}).
+		SetSkip(int64((search.Page - 1) * search.Limit)).
+		SetLimit(int64(search.Limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []*models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// FindMembershipsEndingBefore returns users whose membership is still
+// running but ends before the given time and who have not been reminded.
+func (r *UserRepository) FindMembershipsEndingBefore(ctx context.Context, now, before time.Time) ([]*models.User, error) {
+	filter := bson.M{
+		"membership.ends_at":          bson.M{"$gt": now, "$lte": before},
+		"membership.reminder_sent_at": bson.M{"$exists": false},
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []*models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) FindByPasswordResetHash(ctx context.Context, hash string) (*models.User, error) {
+	var user models.User
+
+	filter := bson.M{
+		"password_reset_hash":    hash,
+		"password_reset_expires": bson.M{"$gt": time.Now()},
+	}
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"courtopia-reserve/backend/internal/ledger"
+	"courtopia-reserve/backend/internal/models"
+)
+
+var ErrInsufficientFunds = errors.New("insufficient wallet balance")
+
+// WalletRepository keeps wallet balances and the append-only ledger they are
+// derived from. Post must run inside RunInTransaction so the balance and
+// its ledger transaction are written together.
+type WalletRepository struct {
+	wallets      *mongo.Collection
+	transactions *mongo.Collection
+}
+
+func NewWalletRepository(db *mongo.Database) *WalletRepository {
+	return &WalletRepository{
+		wallets:      db.Collection("wallets"),
+		transactions: db.Collection("ledger_transactions"),
+	}
+}
+
+type LedgerFilter struct {
+	UserID primitive.ObjectID
+	From   *time.Time
+	To     *time.Time
+	Page   int
+	Limit  int
+}
+
+// Post applies a ledger transaction to the user's wallet and records it.
+// Debits fail with ErrInsufficientFunds rather than overdraw the wallet.
+func (r *WalletRepository) Post(ctx context.Context, txn *models.LedgerTransaction) error {
+	if err := ledger.Validate(txn); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	filter := bson.M{"user_id": txn.UserID}
+	if txn.Amount < 0 {
+		filter["balance"] = bson.M{"$gte": -txn.Amount}
+	}
+	update := bson.M{
+		"$inc": bson.M{"balance": txn.Amount},
+		"$set": bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{
+			"student_id": txn.StudentID,
+			"currency":   txn.Currency,
+			"created_at": now,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetUpsert(txn.Amount >= 0)
+
+	var wallet models.Wallet
+	err := r.wallets.FindOneAndUpdate(ctx, filter, update, opts).Decode(&wallet)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrInsufficientFunds
+	}
+	if err != nil {
+		return err
+	}
+
+	txn.BalanceAfter = wallet.Balance
+	txn.CreatedAt = now
+	_, err = r.transactions.InsertOne(ctx, txn)
+	return err
+}
+
+// FindByUser returns the user's wallet, or an empty one if they never had a
+// transaction.
+func (r *WalletRepository) FindByUser(ctx context.Context, user *models.User, currency string) (*models.Wallet, error) {
+	var wallet models.Wallet
+
+	err := r.wallets.FindOne(ctx, bson.M{"user_id": user.ID}).Decode(&wallet)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return &models.Wallet{UserID: user.ID, StudentID: user.StudentID, Currency: currency}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+// Statement returns a user's transactions oldest first.
+func (r *WalletRepository) Statement(ctx context.Context, f LedgerFilter) ([]*models.LedgerTransaction, int64, error) {
+	filter := bson.M{"user_id": f.UserID}
+	if created := timeRange(f.From, f.To); created != nil {
+		filter["created_at"] = created
+	}
+
+	total, err := r.transactions.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64((f.Page - 1) * f.Limit)).
+		SetLimit(int64(f.Limit))
+
+	cursor, err := r.transactions.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	transactions := []*models.LedgerTransaction{}
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
+
+// BalanceBefore returns the wallet balance just before the given time.
+func (r *WalletRepository) BalanceBefore(ctx context.Context, userID primitive.ObjectID, before time.Time) (int64, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	var txn models.LedgerTransaction
+	err := r.transactions.FindOne(ctx, bson.M{"user_id": userID, "created_at": bson.M{"$lt": before}}, opts).Decode(&txn)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return txn.BalanceAfter, nil
+}
+
+// AccountTotals sums the debits and credits of each account in the period.
+func (r *WalletRepository) AccountTotals(ctx context.Context, from, to *time.Time) ([]*models.LedgerAccountTotal, error) {
+	pipeline := mongo.Pipeline{}
+	if created := timeRange(from, to); created != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"created_at": created}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$unwind", Value: "$entries"}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":    "$entries.account",
+			"debit":  bson.M{"$sum": "$entries.debit"},
+			"credit": bson.M{"$sum": "$entries.credit"},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	)
+
+	totals := []*models.LedgerAccountTotal{}
+	if err := r.aggregate(ctx, pipeline, &totals); err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// KindTotals counts and sums the wallet amounts of each transaction kind in
+// the period.
+func (r *WalletRepository) KindTotals(ctx context.Context, from, to *time.Time) ([]*models.LedgerKindTotal, error) {
+	pipeline := mongo.Pipeline{}
+	if created := timeRange(from, to); created != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"created_at": created}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":    "$kind",
+			"count":  bson.M{"$sum": 1},
+			"amount": bson.M{"$sum": "$amount"},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	)
+
+	totals := []*models.LedgerKindTotal{}
+	if err := r.aggregate(ctx, pipeline, &totals); err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// CountUnbalanced counts transactions whose debits and credits differ.
+func (r *WalletRepository) CountUnbalanced(ctx context.Context) (int64, error) {
+	return r.transactions.CountDocuments(ctx, bson.M{"$expr": bson.M{
+		"$ne": bson.A{bson.M{"$sum": "$entries.debit"}, bson.M{"$sum": "$entries.credit"}},
+	}})
+}
+
+// LedgerBalances sums every user's ledger transactions.
+func (r *WalletRepository) LedgerBalances(ctx context.Context) (map[primitive.ObjectID]int64, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$group", Value: bson.M{"_id": "$user_id", "balance": bson.M{"$sum": "$amount"}}}},
+	}
+
+	var rows []struct {
+		UserID  primitive.ObjectID `bson:"_id"`
+		Balance int64              `bson:"balance"`
+	}
+	if err := r.aggregate(ctx, pipeline, &rows); err != nil {
+		return nil, err
+	}
+
+	balances := make(map[primitive.ObjectID]int64, len(rows))
+	for _, row := range rows {
+		balances[row.UserID] = row.Balance
+	}
+	return balances, nil
+}
+
+func (r *WalletRepository) FindAll(ctx context.Context) ([]*models.Wallet, error) {
+	cursor, err := r.wallets.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"student_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	wallets := []*models.Wallet{}
+	if err := cursor.All(ctx, &wallets); err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+func (r *WalletRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.wallets.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.transactions.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}},
+	})
+	return err
+}
+
+func (r *WalletRepository) aggregate(ctx context.Context, pipeline mongo.Pipeline, results interface{}) error {
+	cursor, err := r.transactions.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, results)
+}
+
+func timeRange(from, to *time.Time) bson.M {
+	if from == nil && to == nil {
+		return nil
+	}
+
+	created := bson.M{}
+	if from != nil {
+		created["$gte"] = *from
+	}
+	if to != nil {
+		created["$lt"] = *to
+	}
+	return created
+}
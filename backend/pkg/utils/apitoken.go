@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const APITokenPrefix = "cpt_"
+
+// GenerateAPIToken returns a new personal API token together with the hash
+// that is persisted. The plaintext token is never stored.
+func GenerateAPIToken() (string, string, error) {
+	random, err := RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	token := APITokenPrefix + random
+	return token, HashAPIToken(token), nil
+}
+
+func HashAPIToken(token string) string {
+	return HashToken(token)
+}
+
+// HashToken returns the SHA-256 hex digest used to store high-entropy
+// single-use secrets such as API and password reset tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"errors"
-	"fmt"
 	"time"
 
 	"courtopia-reserve/backend/internal/models"
@@ -11,14 +10,69 @@ import (
 )
 
 type Claims struct {
-	StudentID string `json:"studentId"`
-	Role      string `json:"role"`
-	Eamil 	  string `json:"email,omitempty"`
+	StudentID string   `json:"studentId"`
+	Role      string   `json:"role"`
+	Eamil     string   `json:"email,omitempty"`
+	MFA       bool     `json:"mfa,omitempty"`
+	Purpose   string   `json:"purpose,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	TokenID   string   `json:"-"`
+
+	// Act identifies the admin behind an impersonation token, following
+	// the actor claim of RFC 8693.
+	Act              *Actor `json:"act,omitempty"`
+	AllowDestructive bool   `json:"allowDestructive,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(user *models.User, secret string, expiryHours int) (string, error) {
-	expirationTime := time.Now().Add(time.Duration(expiryHours) * time.Hour)
+type Actor struct {
+	Subject   string `json:"sub"`
+	StudentID string `json:"studentId"`
+}
+
+// IsAPIToken reports whether the claims were built from a personal API
+// token rather than a login session.
+func (c *Claims) IsAPIToken() bool {
+	return c.TokenID != ""
+}
+
+func (c *Claims) IsImpersonation() bool {
+	return c.Act != nil
+}
+
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type TokenOption func(*Claims)
+
+// WithMFA marks a token as issued after a completed second-factor check.
+func WithMFA() TokenOption {
+	return func(c *Claims) { c.MFA = true }
+}
+
+// WithPurpose restricts a token to a single step of a flow, such as the
+// two-factor login challenge. Purpose tokens are not session tokens.
+func WithPurpose(purpose string) TokenOption {
+	return func(c *Claims) { c.Purpose = purpose }
+}
+
+// WithActor issues the token to an admin acting as the user. Requests that
+// change anything are refused unless allowDestructive is set.
+func WithActor(actor *models.User, allowDestructive bool) TokenOption {
+	return func(c *Claims) {
+		c.Act = &Actor{Subject: actor.ID.Hex(), StudentID: actor.StudentID}
+		c.AllowDestructive = allowDestructive
+	}
+}
+
+func GenerateToken(user *models.User, keys *KeySet, expiry time.Duration, opts ...TokenOption) (string, error) {
+	expirationTime := time.Now().Add(expiry)
 
 	claims := &Claims{
 		StudentID: user.StudentID,
@@ -30,23 +84,19 @@ func GenerateToken(user *models.User, secret string, expiryHours int) (string, e
 			Subject:   user.ID.Hex(),
 		},
 	}
+	for _, opt := range opts {
+		opt(claims)
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secret))
-
-	return tokenString, err
+	return keys.Sign(claims)
 }
 
-func ValidateToken(tokenString string, secret string) (*Claims, error) {
+func ValidateToken(tokenString string, keys *KeySet) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&Claims{},
-		func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(secret), nil
-		},
+		keys.keyFunc,
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}),
 	)
 
 	if err != nil {
@@ -0,0 +1,320 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// SigningKey is a JWT key identified by its kid. Keys past ExpiresAt no
+// longer validate tokens; retired keys still validate but never sign.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+
+	secret  []byte
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+func NewHMACKey(id, secret string) *SigningKey {
+	return &SigningKey{
+		ID:        id,
+		Algorithm: AlgHS256,
+		CreatedAt: time.Now(),
+		secret:    []byte(secret),
+	}
+}
+
+func GenerateSigningKey(algorithm string) (*SigningKey, error) {
+	id, err := RandomString(12)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{ID: id, Algorithm: algorithm, CreatedAt: time.Now()}
+	switch algorithm {
+	case AlgRS256:
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		key.private, key.public = private, &private.PublicKey
+	case AlgEdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key.private, key.public = private, public
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	return key, nil
+}
+
+// ParseSigningKey restores an asymmetric key from its PKCS#8 PEM encoding.
+func ParseSigningKey(id, algorithm string, privatePEM []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{ID: id, Algorithm: algorithm}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != AlgRS256 {
+			return nil, fmt.Errorf("key %s is RSA but declared %s", id, algorithm)
+		}
+		key.private, key.public = private, &private.PublicKey
+	case ed25519.PrivateKey:
+		if algorithm != AlgEdDSA {
+			return nil, fmt.Errorf("key %s is Ed25519 but declared %s", id, algorithm)
+		}
+		key.private, key.public = private, private.Public()
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+
+	return key, nil
+}
+
+func (k *SigningKey) PrivateKeyPEM() ([]byte, error) {
+	if k.private == nil {
+		return nil, errors.New("key has no private part")
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(k.private)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// sealedKeyPrefix marks a private key encrypted by SealPrivateKey, telling
+// it apart from keys stored as plain PEM before encryption was added.
+const sealedKeyPrefix = "sealed:v1:"
+
+// SealPrivateKey encrypts a private key PEM with AES-256-GCM so that the
+// stored key is useless without the key encryption key.
+func SealPrivateKey(kek, privatePEM []byte) (string, error) {
+	gcm, err := newKeyCipher(kek)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, privatePEM, nil)
+	return sealedKeyPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenPrivateKey reverses SealPrivateKey. Plain PEM is returned as is and
+// reported as not sealed so callers can re-store it encrypted.
+func OpenPrivateKey(kek []byte, stored string) ([]byte, bool, error) {
+	if !strings.HasPrefix(stored, sealedKeyPrefix) {
+		return []byte(stored), false, nil
+	}
+
+	gcm, err := newKeyCipher(kek)
+	if err != nil {
+		return nil, true, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, sealedKeyPrefix))
+	if err != nil {
+		return nil, true, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, true, errors.New("sealed key is too short")
+	}
+
+	privatePEM, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, true, err
+	}
+	return privatePEM, true, nil
+}
+
+func newKeyCipher(kek []byte) (cipher.AEAD, error) {
+	if len(kek) != 32 {
+		return nil, errors.New("key encryption key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+func (k *SigningKey) signingKey() interface{} {
+	if k.Algorithm == AlgHS256 {
+		return k.secret
+	}
+	return k.private
+}
+
+func (k *SigningKey) verificationKey() interface{} {
+	if k.Algorithm == AlgHS256 {
+		return k.secret
+	}
+	return k.public
+}
+
+// JWK returns the public half of the key. HMAC keys are never published.
+func (k *SigningKey) JWK() (JWK, bool) {
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType:   "RSA",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: AlgRS256,
+			N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			KeyType:   "OKP",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: AlgEdDSA,
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(public),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
+
+// KeySet holds the key currently used for signing plus every key that is
+// still allowed to validate tokens. It is safe for concurrent use and can be
+// swapped atomically when keys rotate.
+type KeySet struct {
+	mu     sync.RWMutex
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+func NewKeySet(active *SigningKey, others ...*SigningKey) *KeySet {
+	ks := &KeySet{}
+	ks.Replace(active, others...)
+	return ks
+}
+
+func (ks *KeySet) Replace(active *SigningKey, others ...*SigningKey) {
+	keys := make(map[string]*SigningKey, len(others)+1)
+	for _, k := range others {
+		keys[k.ID] = k
+	}
+	if active != nil {
+		keys[active.ID] = active
+	}
+
+	ks.mu.Lock()
+	ks.active = active
+	ks.keys = keys
+	ks.mu.Unlock()
+}
+
+func (ks *KeySet) Active() *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.active
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key := ks.Active()
+	if key == nil {
+		return "", errors.New("no active signing key")
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+
+	return token.SignedString(key.signingKey())
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	ks.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if !key.ExpiresAt.IsZero() && time.Now().After(key.ExpiresAt) {
+		return nil, fmt.Errorf("signing key %q has expired", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.verificationKey(), nil
+}
+
+func (ks *KeySet) JWKS() []JWK {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	jwks := []JWK{}
+	for _, k := range ks.keys {
+		if !k.ExpiresAt.IsZero() && time.Now().After(k.ExpiresAt) {
+			continue
+		}
+		if jwk, ok := k.JWK(); ok {
+			jwks = append(jwks, jwk)
+		}
+	}
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].KeyID < jwks[j].KeyID })
+
+	return jwks
+}
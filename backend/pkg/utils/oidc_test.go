@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockOIDCProvider is a minimal identity provider: discovery, JWKS and a
+// token endpoint that answers every code with an ID token built from
+// claims and signed by signer. Tests adjust either to exercise
+// verification failures.
+type mockOIDCProvider struct {
+	server *httptest.Server
+	keys   *KeySet
+	signer *KeySet
+	claims jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+
+	key, err := GenerateSigningKey(AlgRS256)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	m := &mockOIDCProvider{keys: NewKeySet(key)}
+	m.signer = m.keys
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                m.server.URL,
+			AuthorizationEndpoint: m.server.URL + "/authorize",
+			TokenEndpoint:         m.server.URL + "/token",
+			JWKSURI:               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string][]JWK{"keys": m.keys.JWKS()})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "courtopia" || secret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("code") != "good-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		idToken, err := m.signer.Sign(m.claims)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	m.claims = jwt.MapClaims{
+		"iss":                m.server.URL,
+		"aud":                "courtopia",
+		"sub":                "idp-user-1",
+		"exp":                time.Now().Add(5 * time.Minute).Unix(),
+		"nonce":              "nonce-1",
+		"preferred_username": "6512345678",
+		"name":               "Somchai Jaidee",
+		"email":              "somchai@example.ac.th",
+	}
+	return m
+}
+
+func (m *mockOIDCProvider) client() *OIDCProvider {
+	return NewOIDCProvider(OIDCConfig{
+		Issuer:       m.server.URL,
+		ClientID:     "courtopia",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:8080/api/auth/oidc/callback",
+	})
+}
+
+func TestOIDCAuthCodeURL(t *testing.T) {
+	m := newMockOIDCProvider(t)
+
+	authURL, err := m.client().AuthCodeURL(context.Background(), "state-1", "nonce-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	if !strings.HasPrefix(authURL, m.server.URL+"/authorize?") {
+		t.Fatalf("unexpected authorization endpoint: %s", authURL)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if q.Get("client_id") != "courtopia" || q.Get("state") != "state-1" || q.Get("nonce") != "nonce-1" {
+		t.Fatalf("unexpected authorization parameters: %v", q)
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	m := newMockOIDCProvider(t)
+
+	identity, err := m.client().Exchange(context.Background(), "good-code", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := OIDCIdentity{
+		Issuer:    m.server.URL,
+		Subject:   "idp-user-1",
+		StudentID: "6512345678",
+		Name:      "Somchai Jaidee",
+		Email:     "somchai@example.ac.th",
+	}
+	if *identity != want {
+		t.Fatalf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestOIDCExchangeRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		code  string
+		nonce string
+		edit  func(jwt.MapClaims)
+	}{
+		{name: "unknown code", code: "bad-code", nonce: "nonce-1"},
+		{name: "nonce mismatch", code: "good-code", nonce: "other-nonce"},
+		{name: "wrong audience", code: "good-code", nonce: "nonce-1", edit: func(c jwt.MapClaims) { c["aud"] = "someone-else" }},
+		{name: "wrong issuer", code: "good-code", nonce: "nonce-1", edit: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }},
+		{name: "expired", code: "good-code", nonce: "nonce-1", edit: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{name: "no subject", code: "good-code", nonce: "nonce-1", edit: func(c jwt.MapClaims) { delete(c, "sub") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockOIDCProvider(t)
+			if tt.edit != nil {
+				tt.edit(m.claims)
+			}
+			if identity, err := m.client().Exchange(context.Background(), tt.code, tt.nonce); err == nil {
+				t.Fatalf("Exchange accepted the token: %+v", identity)
+			}
+		})
+	}
+}
+
+func TestOIDCExchangeRejectsUnpublishedKey(t *testing.T) {
+	m := newMockOIDCProvider(t)
+
+	other, err := GenerateSigningKey(AlgRS256)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	m.signer = NewKeySet(other)
+
+	if identity, err := m.client().Exchange(context.Background(), "good-code", "nonce-1"); err == nil {
+		t.Fatalf("Exchange accepted a token from an unpublished key: %+v", identity)
+	}
+}
@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PDFPageWidth  = 595.28
+	PDFPageHeight = 841.89
+)
+
+// PDFDocument draws text, lines and boxes on A4 pages and writes them as a
+// PDF file. Text is set in the embedded TrueType font when one is given,
+// which is what makes Thai render; without one it falls back to the
+// built-in Helvetica, which only covers Latin-1.
+type PDFDocument struct {
+	font  *TrueTypeFont
+	pages []*bytes.Buffer
+	used  map[uint16]rune
+}
+
+func NewPDFDocument(font *TrueTypeFont) *PDFDocument {
+	return &PDFDocument{font: font, used: map[uint16]rune{}}
+}
+
+func (d *PDFDocument) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *PDFDocument) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// CanRender reports whether every character of s has a glyph in the font.
+func (d *PDFDocument) CanRender(s string) bool {
+	for _, r := range s {
+		if d.font != nil {
+			if _, ok := d.font.cmap[r]; !ok && r != ' ' {
+				return false
+			}
+		} else if r > 0xFF {
+			return false
+		}
+	}
+	return true
+}
+
+// Text draws s with its baseline starting at x, y, measured in points from
+// the bottom left of the page.
+func (d *PDFDocument) Text(x, y, size float64, s string) {
+	fmt.Fprintf(d.page(), "BT /F1 %.2f Tf %.2f %.2f Td %s Tj ET\n", size, x, y, d.encode(s))
+}
+
+// TextRight draws s so that it ends at x.
+func (d *PDFDocument) TextRight(x, y, size float64, s string) {
+	d.Text(x-d.TextWidth(s, size), y, size, s)
+}
+
+func (d *PDFDocument) TextWidth(s string, size float64) float64 {
+	var units float64
+	for _, r := range s {
+		if d.font != nil {
+			units += d.font.advance(d.font.cmap[r])
+		} else {
+			units += helveticaWidth(r)
+		}
+	}
+	return units * size / 1000
+}
+
+func (d *PDFDocument) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, y1, x2, y2)
+}
+
+// FillRect fills a box with a shade of grey from 0 (black) to 1 (white).
+func (d *PDFDocument) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(d.page(), "q %.2f g %.2f %.2f %.2f %.2f re f Q\n", gray, x, y, w, h)
+}
+
+func (d *PDFDocument) encode(s string) string {
+	if d.font == nil {
+		var b strings.Builder
+		b.WriteByte('(')
+		for _, r := range s {
+			switch {
+			case r == '(' || r == ')' || r == '\\':
+				b.WriteByte('\\')
+				b.WriteRune(r)
+			case r >= 0x20 && r < 0x7F:
+				b.WriteRune(r)
+			case r >= 0xA0 && r <= 0xFF:
+				fmt.Fprintf(&b, "\\%03o", r)
+			default:
+				b.WriteByte('?')
+			}
+		}
+		b.WriteByte(')')
+		return b.String()
+	}
+
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, r := range s {
+		gid := d.font.cmap[r]
+		if gid != 0 {
+			d.used[gid] = r
+		}
+		fmt.Fprintf(&b, "%04X", gid)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// WriteTo writes the document as a PDF file.
+func (d *PDFDocument) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	pw := &pdfWriter{w: w}
+	pw.printf("%%PDF-1.4\n%%\xE2\xE3\xCF\xD3\n")
+
+	fontObjects := 1
+	if d.font != nil {
+		fontObjects = 5
+	}
+	firstPage := 3 + fontObjects
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	pw.object("<< /Type /Catalog /Pages 2 0 R >>")
+	pw.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	if d.font == nil {
+		pw.object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	} else {
+		d.writeFont(pw)
+	}
+
+	for i, content := range d.pages {
+		pw.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			PDFPageWidth, PDFPageHeight, firstPage+2*i+1))
+		pw.stream("", content.Bytes())
+	}
+
+	xref := pw.n
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	for _, offset := range pw.offsets {
+		pw.printf("%010d 00000 n \n", offset)
+	}
+	pw.printf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets)+1, xref)
+
+	return pw.n, pw.err
+}
+
+// writeFont embeds the whole font file as a composite font addressed by
+// glyph ID, with a ToUnicode map so the text can be copied and searched.
+func (d *PDFDocument) writeFont(pw *pdfWriter) {
+	f := d.font
+	scale := func(v int16) int { return int(v) * 1000 / int(f.unitsPerEm) }
+
+	gids := make([]int, 0, len(d.used))
+	for gid := range d.used {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+
+	var widths, unicode strings.Builder
+	for i, gid := range gids {
+		fmt.Fprintf(&widths, "%d [%.0f] ", gid, f.advance(uint16(gid)))
+		if i%100 == 0 {
+			if i > 0 {
+				unicode.WriteString("endbfchar\n")
+			}
+			fmt.Fprintf(&unicode, "%d beginbfchar\n", min(100, len(gids)-i))
+		}
+		fmt.Fprintf(&unicode, "<%04X> <%s>\n", gid, utf16Hex(d.used[uint16(gid)]))
+	}
+	if len(gids) > 0 {
+		unicode.WriteString("endbfchar\n")
+	}
+
+	pw.object("<< /Type /Font /Subtype /Type0 /BaseFont /EmbeddedFont /Encoding /Identity-H /DescendantFonts [4 0 R] /ToUnicode 7 0 R >>")
+	pw.object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /EmbeddedFont "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor 5 0 R /CIDToGIDMap /Identity /W [%s] >>", widths.String()))
+	pw.object(fmt.Sprintf("<< /Type /FontDescriptor /FontName /EmbeddedFont /Flags 32 /FontBBox [%d %d %d %d] "+
+		"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 6 0 R >>",
+		scale(f.bbox[0]), scale(f.bbox[1]), scale(f.bbox[2]), scale(f.bbox[3]),
+		scale(f.ascent), scale(f.descent), scale(f.ascent)))
+	pw.stream(fmt.Sprintf("/Length1 %d", len(f.data)), f.data)
+	pw.stream("", []byte("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n"+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n"+
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n"+
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n"+
+		unicode.String()+
+		"endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n"))
+}
+
+func utf16Hex(r rune) string {
+	if r < 0x10000 {
+		return fmt.Sprintf("%04X", r)
+	}
+	r -= 0x10000
+	return fmt.Sprintf("%04X%04X", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+}
+
+type pdfWriter struct {
+	w       io.Writer
+	n       int64
+	offsets []int64
+	err     error
+}
+
+func (pw *pdfWriter) printf(format string, args ...interface{}) {
+	if pw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(pw.w, format, args...)
+	pw.n += int64(n)
+	pw.err = err
+}
+
+func (pw *pdfWriter) object(body string) {
+	pw.offsets = append(pw.offsets, pw.n)
+	pw.printf("%d 0 obj\n%s\nendobj\n", len(pw.offsets), body)
+}
+
+// stream writes a compressed stream object with extra dictionary entries.
+func (pw *pdfWriter) stream(extra string, data []byte) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+
+	pw.offsets = append(pw.offsets, pw.n)
+	pw.printf("%d 0 obj\n<< /Length %d /Filter /FlateDecode %s >>\nstream\n", len(pw.offsets), buf.Len(), extra)
+	if pw.err == nil {
+		n, err := pw.w.Write(buf.Bytes())
+		pw.n += int64(n)
+		pw.err = err
+	}
+	pw.printf("\nendstream\nendobj\n")
+}
+
+// helveticaWidths are the advance widths of printable ASCII in Helvetica,
+// in thousandths of the font size.
+var helveticaWidths = [95]float64{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+func helveticaWidth(r rune) float64 {
+	if r >= 0x20 && r < 0x7F {
+		return helveticaWidths[r-0x20]
+	}
+	return 556
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const promptPayAID = "A000000677010111"
+
+// PromptPayPayload builds the EMVCo merchant-presented QR payload for a
+// PromptPay transfer of the given amount in satang. The target is a mobile
+// number, a 13-digit national or tax ID, or a 15-digit e-wallet ID. Render
+// the result as a QR code for banking apps to scan.
+func PromptPayPayload(target string, satang int64) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, target)
+
+	var account string
+	switch {
+	case len(digits) == 10 && digits[0] == '0':
+		account = emvField("01", "0066"+digits[1:])
+	case len(digits) == 13:
+		account = emvField("02", digits)
+	case len(digits) == 15:
+		account = emvField("03", digits)
+	default:
+		return "", errors.New("PromptPay ID must be a mobile number, a 13-digit ID or a 15-digit e-wallet ID")
+	}
+	if satang <= 0 {
+		return "", errors.New("PromptPay amount must be positive")
+	}
+
+	payload := emvField("00", "01") +
+		emvField("01", "12") +
+		emvField("29", emvField("00", promptPayAID)+account) +
+		emvField("58", "TH") +
+		emvField("53", "764") +
+		emvField("54", fmt.Sprintf("%d.%02d", satang/100, satang%100)) +
+		"6304"
+
+	return payload + fmt.Sprintf("%04X", crc16CCITT([]byte(payload))), nil
+}
+
+func emvField(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// crc16CCITT is the CRC-16/CCITT-FALSE checksum EMVCo QR codes end with.
+func crc16CCITT(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// parseEMV splits an EMVCo payload into its top-level fields in order.
+func parseEMV(t *testing.T, payload string) [][2]string {
+	t.Helper()
+
+	var fields [][2]string
+	for len(payload) > 0 {
+		if len(payload) < 4 {
+			t.Fatalf("truncated field %q", payload)
+		}
+		n, err := strconv.Atoi(payload[2:4])
+		if err != nil || len(payload) < 4+n {
+			t.Fatalf("bad field length in %q", payload)
+		}
+		fields = append(fields, [2]string{payload[:2], payload[4 : 4+n]})
+		payload = payload[4+n:]
+	}
+	return fields
+}
+
+func TestCRC16CCITT(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint16
+	}{
+		{"", 0xFFFF},
+		{"123456789", 0x29B1},
+		{"A", 0xB915},
+	}
+
+	for _, tt := range tests {
+		if got := crc16CCITT([]byte(tt.data)); got != tt.want {
+			t.Errorf("crc16CCITT(%q) = %04X, want %04X", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestPromptPayPayload(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		satang      int64
+		wantAccount string
+		wantAmount  string
+	}{
+		{name: "mobile number", target: "0812345678", satang: 15000, wantAccount: "01130066812345678", wantAmount: "150.00"},
+		{name: "formatted mobile number", target: "081-234-5678", satang: 5, wantAccount: "01130066812345678", wantAmount: "0.05"},
+		{name: "national ID", target: "1234567890123", satang: 123456, wantAccount: "02131234567890123", wantAmount: "1234.56"},
+		{name: "e-wallet ID", target: "123456789012345", satang: 100, wantAccount: "0315123456789012345", wantAmount: "1.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := PromptPayPayload(tt.target, tt.satang)
+			if err != nil {
+				t.Fatalf("PromptPayPayload: %v", err)
+			}
+
+			body, crc := payload[:len(payload)-4], payload[len(payload)-4:]
+			if want := fmt.Sprintf("%04X", crc16CCITT([]byte(body))); crc != want {
+				t.Errorf("checksum %s, want %s", crc, want)
+			}
+
+			want := [][2]string{
+				{"00", "01"},
+				{"01", "12"},
+				{"29", "0016" + promptPayAID + tt.wantAccount},
+				{"58", "TH"},
+				{"53", "764"},
+				{"54", tt.wantAmount},
+				{"63", crc},
+			}
+			fields := parseEMV(t, payload)
+			if len(fields) != len(want) {
+				t.Fatalf("fields = %v, want %v", fields, want)
+			}
+			for i := range want {
+				if fields[i] != want[i] {
+					t.Errorf("field %d = %v, want %v", i, fields[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPromptPayPayloadRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		satang int64
+	}{
+		{name: "mobile number without leading zero", target: "8123456789", satang: 100},
+		{name: "too short", target: "12345", satang: 100},
+		{name: "too long", target: "1234567890123456", satang: 100},
+		{name: "zero amount", target: "0812345678", satang: 0},
+		{name: "negative amount", target: "0812345678", satang: -100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if payload, err := PromptPayPayload(tt.target, tt.satang); err == nil {
+				t.Errorf("PromptPayPayload accepted it: %s", payload)
+			}
+		})
+	}
+}
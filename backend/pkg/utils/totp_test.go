@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed from RFC 6238 appendix B,
+// "12345678901234567890", in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTPVectors(t *testing.T) {
+	// The RFC lists 8-digit codes; the last six digits are the 6-digit code.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		step, ok := ValidateTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0))
+		if !ok {
+			t.Errorf("code %s rejected at %d", tt.code, tt.unix)
+			continue
+		}
+		if step != tt.unix/totpPeriod {
+			t.Errorf("code %s matched step %d, want %d", tt.code, step, tt.unix/totpPeriod)
+		}
+	}
+}
+
+func TestValidateTOTPWindow(t *testing.T) {
+	key := []byte("12345678901234567890")
+	issuedStep := int64(40000000)
+	code := totpCode(key, issuedStep)
+
+	tests := []struct {
+		name   string
+		offset int64
+		want   bool
+	}{
+		{name: "clock two steps behind", offset: -2},
+		{name: "clock one step behind", offset: -1, want: true},
+		{name: "same step", offset: 0, want: true},
+		{name: "clock one step ahead", offset: 1, want: true},
+		{name: "clock two steps ahead", offset: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix((issuedStep+tt.offset)*totpPeriod+15, 0)
+			step, ok := ValidateTOTP(rfc6238Secret, code, now)
+			if ok != tt.want {
+				t.Fatalf("ValidateTOTP = %v, want %v", ok, tt.want)
+			}
+			if ok && step != issuedStep {
+				t.Errorf("matched step %d, want the step the code was issued in, %d", step, issuedStep)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		want   bool
+	}{
+		{name: "lower-case secret", secret: strings.ToLower(rfc6238Secret), code: "287082", want: true},
+		{name: "surrounding spaces", secret: rfc6238Secret, code: " 287082 ", want: true},
+		{name: "wrong code", secret: rfc6238Secret, code: "287083"},
+		{name: "too short", secret: rfc6238Secret, code: "28708"},
+		{name: "eight digits", secret: rfc6238Secret, code: "94287082"},
+		{name: "invalid secret", secret: "not base32!", code: "287082"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, now); ok != tt.want {
+				t.Errorf("ValidateTOTP = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+
+	now := time.Now()
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q is not 20 bytes of base32: %v", secret, err)
+	}
+	if _, ok := ValidateTOTP(secret, totpCode(key, now.Unix()/totpPeriod), now); !ok {
+		t.Error("a code from the generated secret was rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Courtopia", "6512345678", rfc6238Secret)
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("parse %q: %v", uri, err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Courtopia:6512345678" {
+		t.Errorf("unexpected URI %s", uri)
+	}
+	q := u.Query()
+	if q.Get("secret") != rfc6238Secret || q.Get("issuer") != "Courtopia" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("unexpected parameters %v", q)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes: %v", err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("got %d codes, want 10", len(codes))
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 9 || code[4] != '-' || code != strings.ToLower(code) {
+			t.Errorf("code %q is not in the xxxx-xxxx form", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q generated twice", code)
+		}
+		seen[code] = true
+	}
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+)
+
+var ErrUnsupportedFont = errors.New("unsupported font: a TrueType font with a Unicode cmap is required")
+
+// TrueTypeFont holds the parts of a TrueType font needed to embed it in a
+// PDF: the character to glyph map and the glyph advance widths.
+type TrueTypeFont struct {
+	data       []byte
+	unitsPerEm uint16
+	ascent     int16
+	descent    int16
+	bbox       [4]int16
+	advances   []uint16
+	cmap       map[rune]uint16
+}
+
+func LoadTrueTypeFont(path string) (*TrueTypeFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTrueTypeFont(data)
+}
+
+func ParseTrueTypeFont(data []byte) (*TrueTypeFont, error) {
+	if len(data) < 12 {
+		return nil, ErrUnsupportedFont
+	}
+	// Fonts with CFF outlines start with OTTO and cannot be embedded as
+	// TrueType.
+	if version := binary.BigEndian.Uint32(data); version != 0x00010000 && version != 0x74727565 {
+		return nil, ErrUnsupportedFont
+	}
+
+	tables := map[string][]byte{}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, ErrUnsupportedFont
+		}
+		offset := int(binary.BigEndian.Uint32(data[rec+8:]))
+		length := int(binary.BigEndian.Uint32(data[rec+12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, ErrUnsupportedFont
+		}
+		tables[string(data[rec:rec+4])] = data[offset : offset+length]
+	}
+
+	head, hhea, maxp, hmtx, cmap := tables["head"], tables["hhea"], tables["maxp"], tables["hmtx"], tables["cmap"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 || cmap == nil || tables["glyf"] == nil {
+		return nil, ErrUnsupportedFont
+	}
+
+	f := &TrueTypeFont{
+		data:       data,
+		unitsPerEm: binary.BigEndian.Uint16(head[18:]),
+		ascent:     int16(binary.BigEndian.Uint16(hhea[4:])),
+		descent:    int16(binary.BigEndian.Uint16(hhea[6:])),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, ErrUnsupportedFont
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int16(binary.BigEndian.Uint16(head[36+2*i:]))
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	if numMetrics == 0 || numMetrics > numGlyphs || len(hmtx) < 4*numMetrics {
+		return nil, ErrUnsupportedFont
+	}
+	f.advances = make([]uint16, numGlyphs)
+	for i := range f.advances {
+		if i < numMetrics {
+			f.advances[i] = binary.BigEndian.Uint16(hmtx[4*i:])
+		} else {
+			f.advances[i] = f.advances[numMetrics-1]
+		}
+	}
+
+	var err error
+	if f.cmap, err = parseCmap(cmap, numGlyphs); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// advance returns a glyph's advance width in thousandths of the font size.
+func (f *TrueTypeFont) advance(gid uint16) float64 {
+	if int(gid) >= len(f.advances) {
+		return 0
+	}
+	return float64(f.advances[gid]) * 1000 / float64(f.unitsPerEm)
+}
+
+// parseCmap reads the Unicode character map, preferring the full-range
+// format 12 subtable over the BMP-only format 4.
+func parseCmap(cmap []byte, numGlyphs int) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, ErrUnsupportedFont
+	}
+
+	var format4, format12 []byte
+	n := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < n; i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(cmap) {
+			return nil, ErrUnsupportedFont
+		}
+		platform := binary.BigEndian.Uint16(cmap[rec:])
+		encoding := binary.BigEndian.Uint16(cmap[rec+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[rec+4:]))
+		if offset+2 > len(cmap) || !(platform == 0 || platform == 3 && (encoding == 1 || encoding == 10)) {
+			continue
+		}
+		switch binary.BigEndian.Uint16(cmap[offset:]) {
+		case 4:
+			format4 = cmap[offset:]
+		case 12:
+			format12 = cmap[offset:]
+		}
+	}
+
+	m := map[rune]uint16{}
+	valid := func(gid int) bool { return gid > 0 && gid < numGlyphs }
+
+	switch {
+	case len(format12) >= 16:
+		groups := int(binary.BigEndian.Uint32(format12[12:]))
+		if 16+12*groups > len(format12) {
+			return nil, ErrUnsupportedFont
+		}
+		for i := 0; i < groups; i++ {
+			g := format12[16+12*i:]
+			start, end := binary.BigEndian.Uint32(g), binary.BigEndian.Uint32(g[4:])
+			gid := int(binary.BigEndian.Uint32(g[8:]))
+			for c := start; c <= end && c <= 0x10FFFF; c++ {
+				if valid(gid) {
+					m[rune(c)] = uint16(gid)
+				}
+				gid++
+			}
+		}
+	case len(format4) >= 14:
+		segs := int(binary.BigEndian.Uint16(format4[6:])) / 2
+		ends, starts, deltas, ranges := 14, 16+2*segs, 16+4*segs, 16+6*segs
+		if ranges+2*segs > len(format4) {
+			return nil, ErrUnsupportedFont
+		}
+		for i := 0; i < segs; i++ {
+			end := int(binary.BigEndian.Uint16(format4[ends+2*i:]))
+			start := int(binary.BigEndian.Uint16(format4[starts+2*i:]))
+			delta := int(binary.BigEndian.Uint16(format4[deltas+2*i:]))
+			rangeOffset := int(binary.BigEndian.Uint16(format4[ranges+2*i:]))
+			for c := start; c <= end && c < 0xFFFF; c++ {
+				gid := 0
+				if rangeOffset == 0 {
+					gid = (c + delta) & 0xFFFF
+				} else {
+					at := ranges + 2*i + rangeOffset + 2*(c-start)
+					if at+2 > len(format4) {
+						continue
+					}
+					if gid = int(binary.BigEndian.Uint16(format4[at:])); gid != 0 {
+						gid = (gid + delta) & 0xFFFF
+					}
+				}
+				if valid(gid) {
+					m[rune(c)] = uint16(gid)
+				}
+			}
+		}
+	default:
+		return nil, ErrUnsupportedFont
+	}
+
+	return m, nil
+}